@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/zbsss/device-manager/internal/analysis"
+	"github.com/zbsss/device-manager/internal/leasehistory"
+)
+
+var (
+	format      = flag.String("format", analysis.FormatTable, "Output format: table, json or csv")
+	windowSize  = flag.Int("window", 120, "Sliding window in seconds in which usage is compared with the pod limit, should match -windowSize of the device manager")
+	minIdleGap  = flag.Int("min-idle-gap", 10, "Shortest time in milliseconds without a lease counted as an idle gap")
+	deviceId    = flag.String("device", "", "Only analyze leases of this device")
+	minFairness = flag.Float64("min-fairness", 0, "Exit with status 2 if the fairness index of any device is below this value")
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "trace" {
+		trace(os.Args[2:])
+		return
+	}
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <lease-history.jsonl[.gz]>...\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s trace [flags] <lease-history.jsonl[.gz]>...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	entries := readEntries(flag.Args(), *deviceId)
+
+	reports := analysis.Analyze(entries, analysis.Options{
+		Window:     time.Duration(*windowSize) * time.Second,
+		MinIdleGap: time.Duration(*minIdleGap) * time.Millisecond,
+	})
+
+	err := analysis.Write(os.Stdout, reports, *format)
+	if err != nil {
+		log.Fatalf("failed to write report: %v", err)
+	}
+
+	for _, report := range reports {
+		if report.Fairness < *minFairness {
+			log.Printf("fairness index of device %s is %.3f, below %.3f", report.DeviceId, report.Fairness, *minFairness)
+			os.Exit(2)
+		}
+	}
+}
+
+// readEntries reads the lease history files, keeping only leases of `deviceId`
+// unless it is empty.
+func readEntries(paths []string, deviceId string) []leasehistory.Entry {
+	entries := []leasehistory.Entry{}
+	for _, path := range paths {
+		fileEntries, err := leasehistory.ReadFile(path)
+		if err != nil {
+			log.Fatalf("failed to read lease history: %v", err)
+		}
+
+		for _, entry := range fileEntries {
+			if deviceId == "" || entry.DeviceId == deviceId {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/zbsss/device-manager/internal/analysis"
+)
+
+// trace writes the lease timeline in Chrome Trace Event format.
+func trace(args []string) {
+	flags := flag.NewFlagSet("trace", flag.ExitOnError)
+	output := flags.String("o", "", "Output file, stdout if empty")
+	deviceId := flags.String("device", "", "Only export leases of this device")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s trace [flags] <lease-history.jsonl[.gz]>...\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	entries := readEntries(flags.Args(), *deviceId)
+
+	out := os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("failed to create %s: %v", *output, err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	err := analysis.WriteTrace(out, entries)
+	if err != nil {
+		log.Fatalf("failed to write trace: %v", err)
+	}
+}
@@ -2,15 +2,12 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"math/rand"
 	"os"
 	"time"
 
 	pb "github.com/zbsss/device-manager/pkg/devicemanager"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 func waitRandom(min, max int) {
@@ -20,16 +17,12 @@ func waitRandom(min, max int) {
 func main() {
 	rand.Seed(time.Now().UnixNano())
 
-	port := "50051"
-
 	clientId := os.Getenv("CLIENT_ID")
+	clientNamespace := os.Getenv("POD_NAMESPACE")
+	clientUid := os.Getenv("POD_UID")
 	deviceId := os.Getenv("DEVICE_ID")
-	addr := os.Getenv("HOST_IP")
 
-	conn, err := grpc.Dial(
-		fmt.Sprintf("%s:%s", addr, port),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	conn, err := pb.Dial(clientUid)
 	if err != nil {
 		log.Fatalf("did not connect: %v", err)
 	}
@@ -44,18 +37,22 @@ func main() {
 	ctx := context.Background()
 
 	for {
-		_, err := grpc.AllocateMemory(ctx, &pb.AllocateMemoryRequest{
-			DeviceId: deviceId,
-			PodId:    clientId,
-			MemoryB:  128,
+		allocation, err := grpc.AllocateMemory(ctx, &pb.AllocateMemoryRequest{
+			DeviceId:     deviceId,
+			PodId:        clientId,
+			PodNamespace: clientNamespace,
+			PodUid:       clientUid,
+			MemoryB:      128,
 		})
 		if err != nil {
 			log.Fatalf("could not get memory quota: %v", err)
 		}
 
-		_, err = grpc.GetToken(ctx, &pb.GetTokenRequest{
-			DeviceId: deviceId,
-			PodId:    clientId,
+		token, err := grpc.GetToken(ctx, &pb.GetTokenRequest{
+			DeviceId:     deviceId,
+			PodId:        clientId,
+			PodNamespace: clientNamespace,
+			PodUid:       clientUid,
 		})
 		if err != nil {
 			log.Fatalf("could not get token: %v", err)
@@ -65,17 +62,22 @@ func main() {
 		waitRandom(workTimeMin, workTimeMax)
 
 		_, err = grpc.ReturnToken(ctx, &pb.ReturnTokenRequest{
-			DeviceId: deviceId,
-			PodId:    clientId,
+			DeviceId:     deviceId,
+			PodId:        clientId,
+			PodNamespace: clientNamespace,
+			PodUid:       clientUid,
+			LeaseId:      token.LeaseId,
 		})
 		if err != nil {
 			log.Fatalf("could not return token: %v", err)
 		}
 
 		_, err = grpc.FreeMemory(ctx, &pb.FreeMemoryRequest{
-			DeviceId: deviceId,
-			PodId:    clientId,
-			MemoryB:  128,
+			DeviceId:     deviceId,
+			PodId:        clientId,
+			PodNamespace: clientNamespace,
+			PodUid:       clientUid,
+			AllocationId: allocation.AllocationId,
 		})
 		if err != nil {
 			log.Fatalf("could not return memory quota: %v", err)
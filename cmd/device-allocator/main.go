@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"math/rand"
 	"os"
@@ -10,16 +9,14 @@ import (
 	"time"
 
 	pb "github.com/zbsss/device-manager/pkg/devicemanager"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 func main() {
 	rand.Seed(time.Now().UnixNano())
 
-	port := "50051"
-	addr := os.Getenv("HOST_IP")
 	allocatorPodId := os.Getenv("ALLOCATOR_POD_ID")
+	allocatorPodNamespace := os.Getenv("ALLOCATOR_POD_NAMESPACE")
+	allocatorPodUid := os.Getenv("ALLOCATOR_POD_UID")
 	deviceId := os.Getenv("DEVICE_ID")
 	vendor := os.Getenv("VENDOR")
 	model := os.Getenv("MODEL")
@@ -31,10 +28,7 @@ func main() {
 		log.Fatalf("could not parse memory: %v", err)
 	}
 
-	conn, err := grpc.Dial(
-		fmt.Sprintf("%s:%s", addr, port),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	conn, err := pb.Dial(allocatorPodUid)
 	if err != nil {
 		log.Fatalf("did not connect: %v", err)
 	}
@@ -46,11 +40,13 @@ func main() {
 
 	for {
 		_, err = grpc.RegisterDevice(ctx, &pb.RegisterDeviceRequest{
-			AllocatorPodId: allocatorPodId,
-			Vendor:         vendor,
-			Model:          model,
-			DeviceId:       deviceId,
-			MemoryB:        memoryB,
+			AllocatorPodId:        allocatorPodId,
+			AllocatorPodNamespace: allocatorPodNamespace,
+			AllocatorPodUid:       allocatorPodUid,
+			Vendor:                vendor,
+			Model:                 model,
+			DeviceId:              deviceId,
+			MemoryB:               memoryB,
 		})
 		if err != nil {
 			log.Printf("could not register device: %v", err)
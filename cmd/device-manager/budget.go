@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/zbsss/device-manager/internal/budget"
+	"k8s.io/client-go/kubernetes"
+)
+
+var budgetsConfigMap = flag.String("budgets-configmap", "", "ConfigMap as namespace/name with the namespace budgets in its "+budget.ConfigMapKey+" key, replaces the budgets of the config file and is reloaded when it changes")
+
+// newBudgets loads the namespace budgets from the ConfigMap or the config file.
+func newBudgets(config *Config, clientset kubernetes.Interface) *budget.Budgets {
+	budgets, err := budget.NewBudgets(config.Budgets)
+	if err != nil {
+		log.Fatalf("invalid budgets in config: %v", err)
+	}
+
+	if *budgetsConfigMap == "" {
+		log.Printf("loaded %d budgets from config", len(config.Budgets))
+		return budgets
+	}
+
+	namespace, name, ok := strings.Cut(*budgetsConfigMap, "/")
+	if !ok || namespace == "" || name == "" {
+		log.Fatalf("expected budgets ConfigMap as namespace/name, got %q", *budgetsConfigMap)
+	}
+	if clientset == nil {
+		log.Fatalf("budgets ConfigMap %s requires running in a cluster", *budgetsConfigMap)
+	}
+
+	// never closed, the budgets are watched until the process exits
+	budget.WatchConfigMap(clientset, namespace, name, budgets, 10*time.Minute, make(chan struct{}))
+	return budgets
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zbsss/device-manager/internal/admission"
+	"github.com/zbsss/device-manager/internal/budget"
+	"github.com/zbsss/device-manager/internal/scheduler"
+)
+
+// Config is the optional configuration file of the device manager. Flags set
+// explicitly on the command line take precedence over it.
+type Config struct {
+	// Policy is the scheduling policy used for devices not listed in DevicePolicies.
+	Policy         string            `json:"policy"`
+	DevicePolicies map[string]string `json:"devicePolicies"`
+	// Admission is the admission policy of devices not listed in DeviceAdmission:
+	// strict, overcommit:<ratio> or best-effort.
+	Admission       string            `json:"admission"`
+	DeviceAdmission map[string]string `json:"deviceAdmission"`
+	// GroupWeights split devices between the groups of pods in the fair-share policy.
+	GroupWeights map[string]float64 `json:"groupWeights"`
+
+	Enforcement EnforcementConfig `json:"enforcement"`
+
+	// Budgets cap the quota of namespaces, unless they are loaded from a ConfigMap.
+	Budgets []budget.Budget `json:"budgets"`
+}
+
+// EnforcementConfig selects what happens to pods which overrun their lease.
+type EnforcementConfig struct {
+	// Action is used for pods not matched by PriorityActions or DeviceActions.
+	Action          string            `json:"action"`
+	DeviceActions   map[string]string `json:"deviceActions"`
+	PriorityActions map[int32]string  `json:"priorityActions"`
+	// PenaltySeconds is nil when the config does not set it, 0 disables the penalty.
+	PenaltySeconds *int `json:"penaltySeconds"`
+}
+
+func loadConfig(path string) (*Config, error) {
+	config := &Config{
+		DevicePolicies:  map[string]string{},
+		GroupWeights:    map[string]float64{},
+		DeviceAdmission: map[string]string{},
+		Enforcement: EnforcementConfig{
+			DeviceActions:   map[string]string{},
+			PriorityActions: map[int32]string{},
+		},
+	}
+	if path == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	err = json.Unmarshal(data, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	if config.DevicePolicies == nil {
+		config.DevicePolicies = map[string]string{}
+	}
+	if config.DeviceAdmission == nil {
+		config.DeviceAdmission = map[string]string{}
+	}
+	if config.GroupWeights == nil {
+		config.GroupWeights = map[string]float64{}
+	}
+	if config.Enforcement.DeviceActions == nil {
+		config.Enforcement.DeviceActions = map[string]string{}
+	}
+	if config.Enforcement.PriorityActions == nil {
+		config.Enforcement.PriorityActions = map[int32]string{}
+	}
+
+	return config, nil
+}
+
+// keyValueFlag collects repeated `key=value` flags into a map.
+type keyValueFlag map[string]string
+
+func (f keyValueFlag) String() string {
+	pairs := make([]string, 0, len(f))
+	for key, value := range f {
+		pairs = append(pairs, key+"="+value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f keyValueFlag) Set(value string) error {
+	for _, pair := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok || key == "" || val == "" {
+			return fmt.Errorf("expected key=value, got %q", pair)
+		}
+		f[key] = val
+	}
+	return nil
+}
+
+func (c *EnforcementConfig) toScheduler() scheduler.EnforcementConfig {
+	config := scheduler.EnforcementConfig{
+		Action:          scheduler.EnforcementAction(c.Action),
+		DeviceActions:   map[string]scheduler.EnforcementAction{},
+		PriorityActions: map[int32]scheduler.EnforcementAction{},
+	}
+	if c.PenaltySeconds != nil {
+		config.PenaltyDuration = time.Duration(*c.PenaltySeconds) * time.Second
+	}
+	for deviceId, action := range c.DeviceActions {
+		config.DeviceActions[deviceId] = scheduler.EnforcementAction(action)
+	}
+	for priority, action := range c.PriorityActions {
+		config.PriorityActions[priority] = scheduler.EnforcementAction(action)
+	}
+	return config
+}
+
+// admissionPolicies parses the default admission policy and those of single devices.
+func (c *Config) admissionPolicies() (admission.Policy, map[string]admission.Policy, error) {
+	policy, err := admission.ParsePolicy(c.Admission)
+	if err != nil {
+		return admission.Policy{}, nil, err
+	}
+
+	devicePolicies := map[string]admission.Policy{}
+	for deviceId, value := range c.DeviceAdmission {
+		devicePolicy, err := admission.ParsePolicy(value)
+		if err != nil {
+			return admission.Policy{}, nil, fmt.Errorf("device %s: %w", deviceId, err)
+		}
+		devicePolicies[deviceId] = devicePolicy
+	}
+	return policy, devicePolicies, nil
+}
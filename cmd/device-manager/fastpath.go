@@ -0,0 +1,29 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+
+	"github.com/zbsss/device-manager/internal/devicemanager"
+)
+
+var tokenSocket = flag.String("token-socket", "", "Unix socket handing the token to clients on the node without gRPC, empty disables it")
+
+// serveFastPath serves the token on the -token-socket, it returns nil if it
+// is disabled.
+func serveFastPath(dm *devicemanager.DeviceManager, policy *devicemanager.SocketPolicy) net.Listener {
+	if *tokenSocket == "" {
+		return nil
+	}
+
+	lis := listenUnix(*tokenSocket)
+	go func() {
+		if err := dm.ServeFastPath(lis, policy); err != nil {
+			log.Printf("failed to serve fast path: %v", err)
+		}
+	}()
+
+	log.Printf("fast path listening at %s", *tokenSocket)
+	return lis
+}
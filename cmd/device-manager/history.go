@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/zbsss/device-manager/internal/leasehistory"
+)
+
+var (
+	historyDir      = flag.String("history-dir", "/var/lib/device-manager/history", "Directory for lease history files, empty disables them")
+	historyMaxSize  = flag.Int64("history-max-size", 64, "Size in MiB after which the lease history file is rotated")
+	historyMaxFiles = flag.Int("history-max-files", 10, "Number of rotated lease history files to keep, 0 keeps all")
+	historyCompress = flag.Bool("history-compress", true, "Gzip rotated lease history files")
+	historyRingSize = flag.Int("history-ring-size", 10000, "Number of recent leases kept in memory")
+)
+
+// newHistoryRecorder creates the recorder of lease history writing to the
+// in-memory ring buffer and, if configured, to rotated files.
+func newHistoryRecorder() (*leasehistory.Recorder, *leasehistory.RingBuffer) {
+	ring := leasehistory.NewRingBuffer(*historyRingSize)
+	sinks := []leasehistory.Sink{ring}
+
+	if *historyDir != "" {
+		fileSink, err := leasehistory.NewFileSink(leasehistory.FileSinkConfig{
+			Dir:      *historyDir,
+			MaxBytes: *historyMaxSize << 20,
+			MaxFiles: *historyMaxFiles,
+			Compress: *historyCompress,
+		})
+		if err != nil {
+			log.Fatalf("failed to create lease history file: %v", err)
+		}
+		sinks = append(sinks, fileSink)
+		log.Printf("writing lease history to %s", *historyDir)
+	}
+
+	return leasehistory.NewRecorder(sinks...), ring
+}
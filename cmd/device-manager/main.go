@@ -5,39 +5,202 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/zbsss/device-manager/internal/admission"
 	"github.com/zbsss/device-manager/internal/devicemanager"
+	"github.com/zbsss/device-manager/internal/peercred"
+	"github.com/zbsss/device-manager/internal/scheduler"
 	pb "github.com/zbsss/device-manager/pkg/devicemanager"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 var (
-	port          = flag.Int("port", 50051, "The server port")
+	port          = flag.Int("port", 50051, "The server port, 0 disables TCP")
 	tokenLifetime = flag.Int("token-life", 30, "Lifetime of token in seconds")
 	windowSize    = flag.Int("windowSize", 120, "Window size in seconds")
+	preemptGrace  = flag.Int("preemption-grace", 0, "Seconds a lease is held before a higher priority pod can preempt it, 0 disables preemption")
+	agingPeriod   = flag.Int("priority-aging", 0, "Seconds a request waits to be raised by one priority level, 0 disables aging")
+	revokeGrace   = flag.Int("revocation-grace", 5, "Seconds a pod watching its lease has to return the token before it is evicted, 0 evicts right away")
+	gcResync      = flag.Int("gc-resync", 30, "Seconds between checks of all reservations against the running pods, terminated pods are released right away")
+	configFile    = flag.String("config", "", "Path to a JSON config file")
+	policy        = flag.String("policy", scheduler.PolicyFairShare,
+		fmt.Sprintf("Default scheduling policy, one of: %s", strings.Join(scheduler.SchedulingPolicies(), ", ")))
+	enforcement      = flag.String("enforcement", string(scheduler.ActionEvict), "Action taken on pods which overrun their lease: evict, delete, revoke, log or annotate")
+	admissionFlag    = flag.String("admission", string(admission.Strict), "Admission of reservations for devices: strict (sum of requests and of memory at most 1), overcommit:<ratio> or best-effort")
+	penalty          = flag.Int("penalty", 60, "Seconds a pod whose lease was revoked cannot get the token")
+	devicePolicies   = keyValueFlag{}
+	deviceActions    = keyValueFlag{}
+	priorityAction   = keyValueFlag{}
+	groupWeights     = keyValueFlag{}
+	deviceAdmissions = keyValueFlag{}
 )
 
-var windowDuration = time.Duration(*windowSize) * time.Second
-var tokenDuration = time.Duration(*tokenLifetime) * time.Second
+func init() {
+	flag.Var(devicePolicies, "device-policy", "Scheduling policy for a single device as device=policy, can be repeated")
+	flag.Var(deviceActions, "device-enforcement", "Enforcement action for a single device as device=action, can be repeated")
+	flag.Var(priorityAction, "priority-enforcement", "Enforcement action for pods of a priority as priority=action, can be repeated")
+	flag.Var(deviceAdmissions, "device-admission", "Admission policy for a single device as device=policy, can be repeated")
+	flag.Var(groupWeights, "group-weight", "Fair-share weight of a group of pods, its namespace or sharedev.group label, as group=weight, can be repeated")
+}
 
 func main() {
 	flag.Parse()
 
-	dm := devicemanager.NewDeviceManager(windowDuration, tokenDuration)
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "policy":
+			config.Policy = *policy
+		case "enforcement":
+			config.Enforcement.Action = *enforcement
+		case "penalty":
+			config.Enforcement.PenaltySeconds = penalty
+		case "admission":
+			config.Admission = *admissionFlag
+		}
+	})
+	if config.Policy == "" {
+		config.Policy = *policy
+	}
+	if config.Enforcement.Action == "" {
+		config.Enforcement.Action = *enforcement
+	}
+	if config.Admission == "" {
+		config.Admission = *admissionFlag
+	}
+	if config.Enforcement.PenaltySeconds == nil {
+		config.Enforcement.PenaltySeconds = penalty
+	}
+	for deviceId, devicePolicy := range devicePolicies {
+		config.DevicePolicies[deviceId] = devicePolicy
+	}
+	for deviceId, policy := range deviceAdmissions {
+		config.DeviceAdmission[deviceId] = policy
+	}
+	for deviceId, action := range deviceActions {
+		config.Enforcement.DeviceActions[deviceId] = action
+	}
+	for value, action := range priorityAction {
+		priority, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			log.Fatalf("invalid priority %q: %v", value, err)
+		}
+		config.Enforcement.PriorityActions[int32(priority)] = action
+	}
+	for group, value := range groupWeights {
+		weight, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			log.Fatalf("invalid weight of group %s %q: %v", group, value, err)
+		}
+		config.GroupWeights[group] = weight
+	}
+
+	admissionPolicy, deviceAdmission, err := config.admissionPolicies()
+	if err != nil {
+		log.Fatalf("invalid admission policy: %v", err)
+	}
 
-	lis, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%d", *port))
+	clientset := newClientset()
+	history, recentHistory := newHistoryRecorder()
+	// closed after dm.Stop, which waits for the schedulers to record their last leases
+	defer history.Close()
+
+	sf, err := scheduler.NewSchedulerFactory(scheduler.SchedulerConfig{
+		WindowDuration:  time.Duration(*windowSize) * time.Second,
+		EvictionPeriod:  time.Duration(*tokenLifetime) * time.Second,
+		PreemptionGrace: time.Duration(*preemptGrace) * time.Second,
+		AgingPeriod:     time.Duration(*agingPeriod) * time.Second,
+		RevocationGrace: time.Duration(*revokeGrace) * time.Second,
+		Enforcer:        scheduler.NewEnforcer(clientset),
+		Enforcement:     config.Enforcement.toScheduler(),
+		History:         history,
+		Policy:          config.Policy,
+		DevicePolicies:  config.DevicePolicies,
+		GroupWeights:    config.GroupWeights,
+		Admission:       admissionPolicy,
+		DeviceAdmission: deviceAdmission,
+	})
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		log.Fatalf("failed to create scheduler factory: %v", err)
 	}
 
-	s := grpc.NewServer()
+	devicemanager.GCResyncPeriod = time.Duration(*gcResync) * time.Second
+
+	st := openStore()
+	if st != nil {
+		defer st.Close()
+	}
+
+	budgets := newBudgets(config, clientset)
+
+	dm := devicemanager.NewDeviceManager(sf, clientset, recentHistory, st, budgets)
+	defer dm.Stop()
+
+	policy := newSocketPolicy(dm)
+	s := grpc.NewServer(
+		grpc.Creds(peercred.NewServerCredentials()),
+		grpc.UnaryInterceptor(policy.UnaryInterceptor()),
+		grpc.StreamInterceptor(policy.StreamInterceptor()),
+	)
 	reflection.Register(s)
 	pb.RegisterDeviceManagerServer(s, dm)
 
-	log.Printf("server listening at %v", lis.Addr())
-	if err := s.Serve(lis); err != nil {
-		log.Fatalf("failed to serve: %v", err)
+	listeners := listen()
+
+	if fastPath := serveFastPath(dm, policy); fastPath != nil {
+		defer fastPath.Close()
+	}
+
+	// stop serving on termination, so that buffered lease history and a state snapshot are written
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		log.Printf("received %v, stopping", <-signals)
+		s.Stop()
+	}()
+
+	var wg sync.WaitGroup
+	for _, lis := range listeners {
+		wg.Add(1)
+		go func(lis net.Listener) {
+			defer wg.Done()
+
+			log.Printf("server listening at %v", lis.Addr())
+			if err := s.Serve(lis); err != nil {
+				log.Printf("failed to serve: %v", err)
+			}
+		}(lis)
 	}
+	wg.Wait()
+}
+
+// newClientset creates a client for the cluster the device manager runs in,
+// it returns nil when running outside of a cluster.
+func newClientset() kubernetes.Interface {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		log.Printf("not running in a cluster: %v", err)
+		return nil
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		log.Fatalf("failed to create client: %v", err)
+	}
+
+	return clientset
 }
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/zbsss/device-manager/internal/devicemanager"
+)
+
+var (
+	address                = flag.String("address", "0.0.0.0", "Address the gRPC API listens on with -port, 127.0.0.1 keeps it on the node")
+	socket                 = flag.String("socket", "", "Unix socket serving the gRPC API to client pods with peer credential checks, empty disables it")
+	trustedServiceAccounts = flag.String("socket-trusted-service-accounts", "", "Comma separated namespace/name of the service accounts whose pods are not checked on the Unix sockets, such as the one of the scheduler")
+)
+
+// listen opens the -port and the -socket, the returned listeners are never
+// empty.
+func listen() []net.Listener {
+	var listeners []net.Listener
+	if *port != 0 {
+		lis, err := net.Listen("tcp", fmt.Sprintf("%s:%d", *address, *port))
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+		listeners = append(listeners, lis)
+	}
+	if *socket != "" {
+		listeners = append(listeners, listenUnix(*socket))
+	}
+
+	if len(listeners) == 0 {
+		log.Fatalf("neither -port nor -socket is set")
+	}
+	return listeners
+}
+
+// listenUnix listens on a Unix socket client pods can connect to.
+func listenUnix(path string) net.Listener {
+	// the socket of a previous run is left behind if it was killed
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.Fatalf("failed to remove %s: %v", path, err)
+	}
+
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		log.Fatalf("failed to listen on %s: %v", path, err)
+	}
+	// client pods do not run as the user of the device manager, their calls
+	// are checked by their peer credentials
+	if err := os.Chmod(path, 0o666); err != nil {
+		log.Fatalf("failed to open %s to clients: %v", path, err)
+	}
+	return lis
+}
+
+// newSocketPolicy returns the policy checking the peers of the Unix sockets of dm.
+func newSocketPolicy(dm *devicemanager.DeviceManager) *devicemanager.SocketPolicy {
+	accounts := map[string]bool{}
+	if *trustedServiceAccounts != "" {
+		for _, value := range strings.Split(*trustedServiceAccounts, ",") {
+			account := strings.TrimSpace(value)
+			if namespace, name, ok := strings.Cut(account, "/"); !ok || namespace == "" || name == "" {
+				log.Fatalf("invalid trusted service account %q, expected namespace/name", value)
+			}
+			accounts[account] = true
+		}
+	}
+	return devicemanager.NewSocketPolicy(dm, accounts)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/zbsss/device-manager/internal/store"
+)
+
+var (
+	stateDir           = flag.String("state-dir", "/var/lib/device-manager/state", "Directory for the persistent state of devices and reservations, empty disables it")
+	stateSnapshotEvery = flag.Int("state-snapshot-every", 1000, "Number of state changes after which a snapshot is written and the log truncated")
+	stateSync          = flag.Bool("state-sync", true, "Fsync state changes, otherwise changes survive a crash of the process but not of the node")
+	stateSyncAllocs    = flag.Bool("state-sync-allocations", false, "Fsync memory allocations and frees as well, their clients do not survive a crash of the node either")
+)
+
+// openStore opens the persistent state, it returns nil if it is disabled.
+func openStore() *store.Store {
+	if *stateDir == "" {
+		return nil
+	}
+
+	st, err := store.Open(store.Config{
+		Dir:             *stateDir,
+		SnapshotEvery:   *stateSnapshotEvery,
+		NoSync:          !*stateSync,
+		SyncAllocations: *stateSyncAllocs,
+	})
+	if err != nil {
+		log.Fatalf("failed to open state in %s: %v", *stateDir, err)
+	}
+	log.Printf("persisting state to %s", *stateDir)
+
+	return st
+}
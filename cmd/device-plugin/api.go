@@ -59,6 +59,8 @@ func (p *DummyDevicePlugin) GetDevicePluginOptions(context.Context, *pluginapi.E
 	}, nil
 }
 
+const deviceManagerSocketDir = "/var/run/device-manager"
+
 func (p *DummyDevicePlugin) Allocate(ctx context.Context, reqs *pluginapi.AllocateRequest) (*pluginapi.AllocateResponse, error) {
 	log.Printf("Allocate request: %+v", reqs)
 
@@ -75,6 +77,12 @@ func (p *DummyDevicePlugin) Allocate(ctx context.Context, reqs *pluginapi.Alloca
 				"DEVICE_ID": id,
 				"MEMORY":    "512",
 			}
+			// the device manager serves its clients on a Unix socket in this directory
+			resp.Mounts = []*pluginapi.Mount{{
+				ContainerPath: deviceManagerSocketDir,
+				HostPath:      deviceManagerSocketDir,
+				ReadOnly:      true,
+			}}
 		}
 		resps.ContainerResponses = append(resps.ContainerResponses, resp)
 	}
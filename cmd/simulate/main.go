@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zbsss/device-manager/internal/analysis"
+	"github.com/zbsss/device-manager/internal/leasehistory"
+	"github.com/zbsss/device-manager/internal/scheduler"
+)
+
+var (
+	workloadPath = flag.String("workload", "", "JSON file with synthetic pods, the lease history files given as arguments are replayed otherwise")
+	simDuration  = flag.Int("duration", 600, "Simulated time in seconds")
+	policy       = flag.String("policy", scheduler.PolicyFairShare, "Scheduling policy")
+	preemptGrace = flag.Int("preemption-grace", 0, "Seconds a lease is held before a higher priority pod can preempt it, 0 disables preemption")
+	agingPeriod  = flag.Int("priority-aging", 0, "Seconds a request waits to be raised by one priority level, 0 disables aging")
+	enforcement  = flag.String("enforcement", string(scheduler.ActionEvict), "Action taken on pods which overrun their lease, every action terminates the kernel")
+	penalty      = flag.Int("penalty", 60, "Seconds a pod whose lease was revoked cannot get the token")
+	seed         = flag.Int64("seed", 1, "Seed of the generated workloads")
+	format       = flag.String("format", analysis.FormatTable, "Output format: table, json or csv")
+	tracePath    = flag.String("trace", "", "Write the simulated leases in Chrome Trace Event format to this file")
+	verbose      = flag.Bool("v", false, "Print the log of the scheduler")
+
+	windowSizes    = intListFlag{120}
+	tokenLifetimes = intListFlag{30}
+)
+
+// sweepResult summarizes a simulation with one combination of the parameters.
+type sweepResult struct {
+	WindowSize      int           `json:"windowSize"`
+	TokenLifetime   int           `json:"tokenLifetime"`
+	Utilization     float64       `json:"utilization"`
+	Fairness        float64       `json:"fairness"`
+	WaitP95         time.Duration `json:"waitP95"`
+	LimitViolations int           `json:"limitViolations"`
+	Completed       int           `json:"completed"`
+	Terminated      int           `json:"terminated"`
+}
+
+func main() {
+	flag.Var(&windowSizes, "windowSize", "Window size in seconds, a comma separated list sweeps over the values")
+	flag.Var(&tokenLifetimes, "token-life", "Lifetime of token in seconds, a comma separated list sweeps over the values")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] -workload <pods.json> | <lease-history.jsonl[.gz]>...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	workloads, err := workloads()
+	if err != nil {
+		log.Fatalf("failed to load workloads: %v", err)
+	}
+
+	action, err := scheduler.ParseEnforcementAction(*enforcement)
+	if err != nil {
+		log.Fatalf("invalid enforcement: %v", err)
+	}
+
+	results := []sweepResult{}
+	for _, windowSize := range windowSizes {
+		for _, tokenLifetime := range tokenLifetimes {
+			config := scheduler.SimulationConfig{
+				Scheduler: scheduler.SchedulerConfig{
+					WindowDuration:  time.Duration(windowSize) * time.Second,
+					EvictionPeriod:  time.Duration(tokenLifetime) * time.Second,
+					PreemptionGrace: time.Duration(*preemptGrace) * time.Second,
+					AgingPeriod:     time.Duration(*agingPeriod) * time.Second,
+					Enforcement: scheduler.EnforcementConfig{
+						Action:          action,
+						PenaltyDuration: time.Duration(*penalty) * time.Second,
+					},
+					Policy: *policy,
+				},
+				Duration:  time.Duration(*simDuration) * time.Second,
+				Seed:      *seed,
+				Workloads: workloads,
+			}
+
+			result, err := simulate(config)
+			if err != nil {
+				log.Fatalf("simulation failed: %v", err)
+			}
+
+			reports := analysis.Analyze(result.History, analysis.Options{Window: config.Scheduler.WindowDuration})
+			if len(windowSizes) == 1 && len(tokenLifetimes) == 1 {
+				if *tracePath != "" {
+					writeTrace(result.History)
+				}
+				err = writeResult(os.Stdout, reports, result.Pods)
+				if err != nil {
+					log.Fatalf("failed to write result: %v", err)
+				}
+				return
+			}
+
+			results = append(results, summarize(windowSize, tokenLifetime, reports, result.Pods))
+		}
+	}
+
+	err = writeSweep(os.Stdout, results)
+	if err != nil {
+		log.Fatalf("failed to write result: %v", err)
+	}
+}
+
+func workloads() ([]scheduler.Workload, error) {
+	if *workloadPath != "" {
+		return loadWorkloads(*workloadPath)
+	}
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	entries := []leasehistory.Entry{}
+	for _, path := range flag.Args() {
+		fileEntries, err := leasehistory.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, fileEntries...)
+	}
+	return scheduler.WorkloadsFromHistory(entries), nil
+}
+
+// simulate silences the scheduler log unless -v is set.
+func simulate(config scheduler.SimulationConfig) (*scheduler.SimulationResult, error) {
+	if !*verbose {
+		log.SetOutput(io.Discard)
+		defer log.SetOutput(os.Stderr)
+	}
+	return scheduler.Simulate(config)
+}
+
+func writeTrace(history []leasehistory.Entry) {
+	file, err := os.Create(*tracePath)
+	if err != nil {
+		log.Fatalf("failed to create %s: %v", *tracePath, err)
+	}
+	defer file.Close()
+
+	err = analysis.WriteTrace(file, history)
+	if err != nil {
+		log.Fatalf("failed to write trace: %v", err)
+	}
+}
+
+func writeResult(w io.Writer, reports []*analysis.Report, pods []scheduler.PodResult) error {
+	switch *format {
+	case analysis.FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]interface{}{"reports": reports, "pods": pods})
+	case analysis.FormatTable:
+		if err := analysis.Write(w, reports, *format); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(w)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "POD\tCOMPLETED\tTERMINATED\tPENDING\tREJECTED")
+		for _, pod := range pods {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%t\n", pod.PodId, pod.Completed, pod.Terminated, pod.Pending, pod.Rejected)
+		}
+		return tw.Flush()
+	default:
+		return analysis.Write(w, reports, *format)
+	}
+}
+
+func summarize(windowSize, tokenLifetime int, reports []*analysis.Report, pods []scheduler.PodResult) sweepResult {
+	result := sweepResult{WindowSize: windowSize, TokenLifetime: tokenLifetime}
+	for _, report := range reports {
+		result.Utilization = report.Utilization
+		result.Fairness = report.Fairness
+		result.WaitP95 = report.QueueWait.P95
+		for _, pod := range report.Pods {
+			result.LimitViolations += pod.LimitViolations
+		}
+	}
+	for _, pod := range pods {
+		result.Completed += pod.Completed
+		result.Terminated += pod.Terminated
+	}
+	return result
+}
+
+func writeSweep(w io.Writer, results []sweepResult) error {
+	switch *format {
+	case analysis.FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	case analysis.FormatCSV:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"window_size", "token_life", "utilization", "fairness", "wait_p95_s", "limit_violations", "completed", "terminated"})
+		for _, r := range results {
+			cw.Write([]string{
+				strconv.Itoa(r.WindowSize), strconv.Itoa(r.TokenLifetime),
+				strconv.FormatFloat(r.Utilization, 'f', 6, 64), strconv.FormatFloat(r.Fairness, 'f', 6, 64),
+				strconv.FormatFloat(r.WaitP95.Seconds(), 'f', 6, 64),
+				strconv.Itoa(r.LimitViolations), strconv.Itoa(r.Completed), strconv.Itoa(r.Terminated),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	case analysis.FormatTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "WINDOW SIZE\tTOKEN LIFE\tUTILIZATION\tFAIRNESS\tWAIT P95\tLIMIT VIOLATIONS\tCOMPLETED\tTERMINATED")
+		for _, r := range results {
+			fmt.Fprintf(tw, "%ds\t%ds\t%.3f\t%.3f\t%v\t%d\t%d\t%d\n", r.WindowSize, r.TokenLifetime,
+				r.Utilization, r.Fairness, r.WaitP95.Round(time.Millisecond), r.LimitViolations, r.Completed, r.Terminated)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+}
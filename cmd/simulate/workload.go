@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zbsss/device-manager/internal/scheduler"
+)
+
+// duration is a time.Duration written as a string like "100ms" in JSON.
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("durations are strings like \"100ms\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = duration(parsed)
+	return nil
+}
+
+type distribution struct {
+	Kind   string   `json:"kind"`
+	Mean   duration `json:"mean"`
+	StdDev duration `json:"stdDev"`
+	Min    duration `json:"min"`
+	Max    duration `json:"max"`
+}
+
+func (d distribution) toScheduler() scheduler.Distribution {
+	return scheduler.Distribution{
+		Kind:   d.Kind,
+		Mean:   time.Duration(d.Mean),
+		StdDev: time.Duration(d.StdDev),
+		Min:    time.Duration(d.Min),
+		Max:    time.Duration(d.Max),
+	}
+}
+
+type pod struct {
+	PodId          string       `json:"podId"`
+	Requests       float64      `json:"requests"`
+	Limit          float64      `json:"limit"`
+	Priority       int32        `json:"priority"`
+	Group          string       `json:"group"`
+	Start          duration     `json:"start"`
+	ArrivalRate    float64      `json:"arrivalRate"`
+	Gap            distribution `json:"gap"`
+	KernelDuration distribution `json:"kernelDuration"`
+	KernelCount    int          `json:"kernelCount"`
+}
+
+// workloadFile describes synthetic pods, for example:
+//
+//	{"pods": [{"podId": "a", "requests": 0.5, "limit": 1, "arrivalRate": 10,
+//	           "kernelDuration": {"kind": "exponential", "mean": "50ms"}}]}
+type workloadFile struct {
+	Pods []pod `json:"pods"`
+}
+
+func loadWorkloads(path string) ([]scheduler.Workload, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file workloadFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	workloads := []scheduler.Workload{}
+	for _, pod := range file.Pods {
+		workloads = append(workloads, scheduler.Workload{
+			PodId:          pod.PodId,
+			Requests:       pod.Requests,
+			Limit:          pod.Limit,
+			Priority:       pod.Priority,
+			Group:          pod.Group,
+			Start:          time.Duration(pod.Start),
+			ArrivalRate:    pod.ArrivalRate,
+			Gap:            pod.Gap.toScheduler(),
+			KernelDuration: pod.KernelDuration.toScheduler(),
+			KernelCount:    pod.KernelCount,
+		})
+	}
+	return workloads, nil
+}
+
+// intListFlag collects comma separated values, to sweep over them.
+type intListFlag []int
+
+func (f *intListFlag) String() string {
+	values := []string{}
+	for _, value := range *f {
+		values = append(values, strconv.Itoa(value))
+	}
+	return strings.Join(values, ",")
+}
+
+func (f *intListFlag) Set(value string) error {
+	values := []int{}
+	for _, s := range strings.Split(value, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return err
+		}
+		values = append(values, v)
+	}
+	*f = values
+	return nil
+}
@@ -0,0 +1,129 @@
+// Package admission decides whether the quota a pod reserves on a device is admitted.
+package admission
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zbsss/device-manager/pkg/quota"
+)
+
+type Mode string
+
+const (
+	// Strict admits a reservation only if the sum of all reservations stays
+	// within the device, so every reservation is guaranteed.
+	Strict Mode = "strict"
+	// Overcommit admits reservations up to OvercommitRatio times the device.
+	Overcommit Mode = "overcommit"
+	// BestEffort admits every reservation.
+	BestEffort Mode = "best-effort"
+)
+
+// Result tells how a reservation was admitted.
+type Result string
+
+const (
+	// Guaranteed reservations fit into the device with all others.
+	Guaranteed Result = "guaranteed"
+	// Overcommitted reservations were admitted within the overcommit ratio.
+	Overcommitted Result = "overcommitted"
+	// NotGuaranteed reservations were admitted by the best-effort policy
+	// beyond the device.
+	NotGuaranteed Result = "best-effort"
+)
+
+// Policy is the admission policy of a device, the zero value is strict.
+type Policy struct {
+	Mode Mode
+	// OvercommitRatio caps the sum of reservations in Overcommit mode, 1.5
+	// admits reservations up to one and a half times the device.
+	OvercommitRatio float64
+}
+
+// ParsePolicy parses "strict", "best-effort" or "overcommit:<ratio>".
+func ParsePolicy(value string) (Policy, error) {
+	mode, ratio, hasRatio := strings.Cut(value, ":")
+	switch Mode(mode) {
+	case Strict, BestEffort:
+		if hasRatio {
+			return Policy{}, fmt.Errorf("admission policy %s does not take a ratio", mode)
+		}
+		return Policy{Mode: Mode(mode)}, nil
+	case Overcommit:
+		if !hasRatio {
+			return Policy{}, fmt.Errorf("expected overcommit:<ratio>, got %q", value)
+		}
+		parsed, err := strconv.ParseFloat(ratio, 64)
+		if err != nil {
+			return Policy{}, fmt.Errorf("invalid overcommit ratio %q: %w", ratio, err)
+		}
+		policy := Policy{Mode: Overcommit, OvercommitRatio: parsed}
+		return policy, policy.Validate()
+	}
+	return Policy{}, fmt.Errorf("unknown admission policy %q, available: strict, overcommit:<ratio>, best-effort", value)
+}
+
+func (p Policy) Validate() error {
+	switch p.Mode {
+	case "", Strict, BestEffort:
+		return nil
+	case Overcommit:
+		if p.OvercommitRatio < 1 {
+			return fmt.Errorf("overcommit ratio must be at least 1, got %v", p.OvercommitRatio)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown admission mode %q", p.Mode)
+}
+
+func (p Policy) String() string {
+	if p.Mode == Overcommit {
+		return fmt.Sprintf("%s:%g", p.Mode, p.OvercommitRatio)
+	}
+	if p.Mode == "" {
+		return string(Strict)
+	}
+	return string(p.Mode)
+}
+
+// Admit decides whether `requested` is admitted on top of `reserved`.
+func (p Policy) Admit(reserved, requested quota.MilliShares) (Result, error) {
+	if result, ok := p.admit(uint64(reserved+requested), uint64(quota.Device)); ok {
+		return result, nil
+	}
+	return "", p.reject(reserved.String(), requested.String())
+}
+
+// AdmitBytes decides whether `requestedB` bytes are admitted on top of
+// `reservedB` bytes of a device with `totalB` bytes of memory.
+func (p Policy) AdmitBytes(reservedB, requestedB, totalB uint64) (Result, error) {
+	if result, ok := p.admit(reservedB+requestedB, totalB); ok {
+		return result, nil
+	}
+	return "", p.reject(fmt.Sprintf("%d B", reservedB), fmt.Sprintf("%d B", requestedB))
+}
+
+func (p Policy) admit(total, capacity uint64) (Result, bool) {
+	if total <= capacity {
+		return Guaranteed, true
+	}
+
+	switch p.Mode {
+	case BestEffort:
+		return NotGuaranteed, true
+	case Overcommit:
+		// the ratio is rounded to milli-shares like every other quota
+		return Overcommitted, total <= quota.FromFraction(p.OvercommitRatio).Of(capacity)
+	default:
+		return "", false
+	}
+}
+
+func (p Policy) reject(reserved, requested string) error {
+	if p.Mode == Overcommit {
+		return fmt.Errorf("%s is reserved, %s more exceeds the overcommit ratio %g", reserved, requested, p.OvercommitRatio)
+	}
+	return fmt.Errorf("%s is reserved, %s more exceeds the device", reserved, requested)
+}
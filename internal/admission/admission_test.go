@@ -0,0 +1,63 @@
+package admission
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zbsss/device-manager/pkg/quota"
+)
+
+func TestParsePolicy(t *testing.T) {
+	for value, expected := range map[string]Policy{
+		"strict":         {Mode: Strict},
+		"best-effort":    {Mode: BestEffort},
+		"overcommit:1.5": {Mode: Overcommit, OvercommitRatio: 1.5},
+	} {
+		policy, err := ParsePolicy(value)
+		assert.Nil(t, err, value)
+		assert.Equal(t, expected, policy, value)
+		assert.Equal(t, value, policy.String())
+	}
+
+	for _, value := range []string{"", "lenient", "overcommit", "overcommit:0.5", "overcommit:x", "strict:2"} {
+		_, err := ParsePolicy(value)
+		assert.NotNil(t, err, value)
+	}
+}
+
+func TestAdmit(t *testing.T) {
+	for _, test := range []struct {
+		policy    Policy
+		reserved  quota.MilliShares
+		requested quota.MilliShares
+		expected  Result
+	}{
+		{Policy{}, 100 + 200, 700, Guaranteed},
+		{Policy{Mode: Strict}, 100, 900, Guaranteed},
+		{Policy{Mode: Strict}, 100, 901, ""},
+		{Policy{Mode: Overcommit, OvercommitRatio: 1.5}, 500, 500, Guaranteed},
+		{Policy{Mode: Overcommit, OvercommitRatio: 1.5}, 1000, 500, Overcommitted},
+		{Policy{Mode: Overcommit, OvercommitRatio: 1.5}, 1000, 501, ""},
+		{Policy{Mode: BestEffort}, 5000, 1000, NotGuaranteed},
+	} {
+		result, err := test.policy.Admit(test.reserved, test.requested)
+		assert.Equal(t, test.expected, result, "%v %v+%v", test.policy, test.reserved, test.requested)
+		assert.Equal(t, test.expected == "", err != nil, "%v %v+%v", test.policy, test.reserved, test.requested)
+	}
+}
+
+func TestAdmitBytes(t *testing.T) {
+	overcommit := Policy{Mode: Overcommit, OvercommitRatio: 1.5}
+
+	result, err := Policy{}.AdmitBytes(1<<30, 1<<30, 2<<30)
+	assert.Nil(t, err)
+	assert.Equal(t, Guaranteed, result)
+	_, err = Policy{}.AdmitBytes(1<<30, 1<<30+1, 2<<30)
+	assert.NotNil(t, err)
+
+	result, err = overcommit.AdmitBytes(2<<30, 1<<30, 2<<30)
+	assert.Nil(t, err)
+	assert.Equal(t, Overcommitted, result)
+	_, err = overcommit.AdmitBytes(2<<30, 1<<30+1, 2<<30)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,260 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/zbsss/device-manager/internal/leasehistory"
+)
+
+// Options control the sliding window analysis.
+type Options struct {
+	// Window is the length of the sliding window in which usage is compared
+	// against the limit of a pod, it should match the scheduler window.
+	Window time.Duration
+	// MinIdleGap is the shortest time without a lease counted as an idle gap.
+	MinIdleGap time.Duration
+}
+
+// Report summarizes the lease history of a single device.
+type Report struct {
+	DeviceId string        `json:"deviceId"`
+	Start    time.Time     `json:"start"`
+	End      time.Time     `json:"end"`
+	Duration time.Duration `json:"duration"`
+	// Utilization is the fraction of time the token was leased to any pod.
+	Utilization float64 `json:"utilization"`
+	// Fairness is Jain's fairness index of the pod utilizations normalized by
+	// their requests, 1 means every pod got the same share of its request.
+	Fairness  float64      `json:"fairness"`
+	QueueWait WaitStats    `json:"queueWait"`
+	IdleGaps  GapStats     `json:"idleGaps"`
+	Pods      []*PodReport `json:"pods"`
+}
+
+// PodReport summarizes the leases of a single pod on a device.
+type PodReport struct {
+	PodId  string        `json:"podId"`
+	Leases int           `json:"leases"`
+	Busy   time.Duration `json:"busy"`
+	// Utilization is the fraction of the report duration the pod held the token.
+	Utilization float64 `json:"utilization"`
+	Requests    float64 `json:"requests"`
+	Limit       float64 `json:"limit"`
+	// RequestShare and LimitShare are Utilization relative to the requests and
+	// the limit of the pod, zero when they are unknown.
+	RequestShare float64   `json:"requestShare"`
+	LimitShare   float64   `json:"limitShare"`
+	QueueWait    WaitStats `json:"queueWait"`
+	// MaxWindowUsage is the highest usage of the pod in any sliding window.
+	MaxWindowUsage float64 `json:"maxWindowUsage"`
+	// LimitViolations counts leases which ended with the usage of the pod in
+	// the preceding window above its limit.
+	LimitViolations int `json:"limitViolations"`
+}
+
+// WaitStats are percentiles of the time requests waited for the token.
+type WaitStats struct {
+	Count int           `json:"count"`
+	P50   time.Duration `json:"p50"`
+	P95   time.Duration `json:"p95"`
+	P99   time.Duration `json:"p99"`
+	Max   time.Duration `json:"max"`
+}
+
+// GapStats describe the periods no pod held the token.
+type GapStats struct {
+	Count   int           `json:"count"`
+	Total   time.Duration `json:"total"`
+	Longest time.Duration `json:"longest"`
+}
+
+// limitTolerance absorbs the time between a lease expiring and being terminated.
+const limitTolerance = 1e-3
+
+// Analyze builds a report for every device in `entries`, sorted by device id.
+func Analyze(entries []leasehistory.Entry, opts Options) []*Report {
+	perDevice := make(map[string][]leasehistory.Entry)
+	for _, entry := range entries {
+		if entry.ReturnedAt.Before(entry.LeasedAt) {
+			continue
+		}
+		perDevice[entry.DeviceId] = append(perDevice[entry.DeviceId], entry)
+	}
+
+	reports := []*Report{}
+	for deviceId, deviceEntries := range perDevice {
+		reports = append(reports, analyzeDevice(deviceId, deviceEntries, opts))
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].DeviceId < reports[j].DeviceId })
+
+	return reports
+}
+
+func analyzeDevice(deviceId string, entries []leasehistory.Entry, opts Options) *Report {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].LeasedAt.Before(entries[j].LeasedAt) })
+
+	report := &Report{
+		DeviceId: deviceId,
+		Start:    entries[0].LeasedAt,
+		End:      entries[0].ReturnedAt,
+		Pods:     []*PodReport{},
+	}
+
+	perPod := make(map[string][]leasehistory.Entry)
+	var busy time.Duration
+	var waits []time.Duration
+	for i, entry := range entries {
+		if entry.ReturnedAt.After(report.End) {
+			report.End = entry.ReturnedAt
+		}
+		busy += entry.ReturnedAt.Sub(entry.LeasedAt)
+		perPod[entry.PodId] = append(perPod[entry.PodId], entry)
+		if !entry.EnqueuedAt.IsZero() {
+			waits = append(waits, entry.LeasedAt.Sub(entry.EnqueuedAt))
+		}
+
+		if i > 0 {
+			gap := entry.LeasedAt.Sub(entries[i-1].ReturnedAt)
+			if gap > 0 && gap >= opts.MinIdleGap {
+				report.IdleGaps.Count++
+				report.IdleGaps.Total += gap
+				if gap > report.IdleGaps.Longest {
+					report.IdleGaps.Longest = gap
+				}
+			}
+		}
+	}
+
+	report.Duration = report.End.Sub(report.Start)
+	report.Utilization = ratio(busy, report.Duration)
+	report.QueueWait = waitStats(waits)
+
+	for podId, podEntries := range perPod {
+		report.Pods = append(report.Pods, analyzePod(podId, podEntries, report.Duration, opts))
+	}
+	sort.Slice(report.Pods, func(i, j int) bool { return report.Pods[i].PodId < report.Pods[j].PodId })
+
+	report.Fairness = fairness(report.Pods)
+
+	return report
+}
+
+// analyzePod expects the leases of the pod sorted by LeasedAt, since a pod
+// holds at most one token of a device they do not overlap.
+func analyzePod(podId string, entries []leasehistory.Entry, duration time.Duration, opts Options) *PodReport {
+	pod := &PodReport{
+		PodId:  podId,
+		Leases: len(entries),
+	}
+
+	// the most recent quota of the pod, older entries have none
+	last := entries[len(entries)-1]
+	pod.Requests = last.Requests
+	pod.Limit = last.Limit
+
+	var waits []time.Duration
+	prefix := make([]time.Duration, len(entries)+1)
+	for i, entry := range entries {
+		prefix[i+1] = prefix[i] + entry.ReturnedAt.Sub(entry.LeasedAt)
+		if !entry.EnqueuedAt.IsZero() {
+			waits = append(waits, entry.LeasedAt.Sub(entry.EnqueuedAt))
+		}
+	}
+	pod.Busy = prefix[len(entries)]
+	pod.Utilization = ratio(pod.Busy, duration)
+	pod.QueueWait = waitStats(waits)
+
+	if pod.Requests > 0 {
+		pod.RequestShare = pod.Utilization / pod.Requests
+	}
+	if pod.Limit > 0 {
+		pod.LimitShare = pod.Utilization / pod.Limit
+	}
+
+	if opts.Window <= 0 {
+		return pod
+	}
+
+	// usage in a trailing window is the highest when it ends with a lease
+	first := 0
+	for i, entry := range entries {
+		windowStart := entry.ReturnedAt.Add(-opts.Window)
+		for entries[first].ReturnedAt.Before(windowStart) || entries[first].ReturnedAt.Equal(windowStart) {
+			first++
+		}
+
+		used := prefix[i+1] - prefix[first]
+		if entries[first].LeasedAt.Before(windowStart) {
+			used -= windowStart.Sub(entries[first].LeasedAt)
+		}
+
+		usage := ratio(used, opts.Window)
+		if usage > pod.MaxWindowUsage {
+			pod.MaxWindowUsage = usage
+		}
+		if entry.Limit > 0 && usage > entry.Limit+limitTolerance {
+			pod.LimitViolations++
+		}
+	}
+
+	return pod
+}
+
+// fairness is Jain's index (sum x)^2 / (n * sum x^2) of the pod shares. Shares
+// are normalized by requests only if all pods have them, so that pods with
+// different requests are compared fairly.
+func fairness(pods []*PodReport) float64 {
+	if len(pods) == 0 {
+		return 0
+	}
+
+	normalize := true
+	for _, pod := range pods {
+		if pod.Requests <= 0 {
+			normalize = false
+		}
+	}
+
+	var sum, sumSquares float64
+	for _, pod := range pods {
+		x := pod.Utilization
+		if normalize {
+			x = pod.RequestShare
+		}
+		sum += x
+		sumSquares += x * x
+	}
+
+	if sumSquares == 0 {
+		return 0
+	}
+	return sum * sum / (float64(len(pods)) * sumSquares)
+}
+
+func waitStats(waits []time.Duration) WaitStats {
+	if len(waits) == 0 {
+		return WaitStats{}
+	}
+
+	sort.Slice(waits, func(i, j int) bool { return waits[i] < waits[j] })
+	percentile := func(p float64) time.Duration {
+		return waits[int(math.Ceil(p*float64(len(waits))))-1]
+	}
+
+	return WaitStats{
+		Count: len(waits),
+		P50:   percentile(0.5),
+		P95:   percentile(0.95),
+		P99:   percentile(0.99),
+		Max:   waits[len(waits)-1],
+	}
+}
+
+func ratio(a, b time.Duration) float64 {
+	if b <= 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
@@ -0,0 +1,152 @@
+package analysis
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zbsss/device-manager/internal/leasehistory"
+)
+
+var start = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func lease(podId string, enqueued, leased, returned int, requests, limit float64) leasehistory.Entry {
+	return leasehistory.Entry{
+		DeviceId:   "device",
+		PodId:      podId,
+		EnqueuedAt: start.Add(time.Duration(enqueued) * time.Second),
+		LeasedAt:   start.Add(time.Duration(leased) * time.Second),
+		ReturnedAt: start.Add(time.Duration(returned) * time.Second),
+		Requests:   requests,
+		Limit:      limit,
+	}
+}
+
+func TestAnalyzeUtilizationAndFairness(t *testing.T) {
+	entries := []leasehistory.Entry{
+		lease("b", 0, 10, 20, 0.5, 1),
+		lease("a", 0, 0, 10, 0.5, 1),
+		lease("a", 20, 30, 40, 0.5, 1),
+	}
+
+	reports := Analyze(entries, Options{Window: 40 * time.Second})
+	assert.Len(t, reports, 1)
+
+	report := reports[0]
+	assert.Equal(t, 40*time.Second, report.Duration)
+	assert.InDelta(t, 0.75, report.Utilization, 1e-9)
+	assert.Equal(t, GapStats{Count: 1, Total: 10 * time.Second, Longest: 10 * time.Second}, report.IdleGaps)
+
+	assert.Len(t, report.Pods, 2)
+	a, b := report.Pods[0], report.Pods[1]
+	assert.Equal(t, "a", a.PodId)
+	assert.Equal(t, 2, a.Leases)
+	assert.InDelta(t, 0.5, a.Utilization, 1e-9)
+	assert.InDelta(t, 1.0, a.RequestShare, 1e-9)
+	assert.InDelta(t, 0.25, b.Utilization, 1e-9)
+	assert.InDelta(t, 0.5, b.RequestShare, 1e-9)
+
+	// (1 + 0.5)^2 / (2 * (1 + 0.25))
+	assert.InDelta(t, 0.9, report.Fairness, 1e-9)
+
+	assert.Equal(t, 3, report.QueueWait.Count)
+	assert.Equal(t, 10*time.Second, report.QueueWait.P50)
+	assert.Equal(t, 10*time.Second, report.QueueWait.Max)
+	assert.Equal(t, WaitStats{Count: 2, P50: 0, P95: 10 * time.Second, P99: 10 * time.Second, Max: 10 * time.Second}, a.QueueWait)
+}
+
+func TestAnalyzeEqualSharesAreFair(t *testing.T) {
+	entries := []leasehistory.Entry{
+		lease("a", 0, 0, 10, 0.5, 1),
+		lease("b", 0, 10, 20, 0.5, 1),
+	}
+
+	reports := Analyze(entries, Options{})
+	assert.InDelta(t, 1.0, reports[0].Fairness, 1e-9)
+	assert.Equal(t, 0, reports[0].IdleGaps.Count)
+}
+
+func TestAnalyzeLimitViolations(t *testing.T) {
+	entries := []leasehistory.Entry{
+		lease("a", 0, 0, 6, 0.2, 0.5),
+		lease("a", 6, 8, 10, 0.2, 0.5),
+		lease("a", 30, 30, 34, 0.2, 0.5),
+	}
+
+	reports := Analyze(entries, Options{Window: 10 * time.Second})
+	pod := reports[0].Pods[0]
+
+	// 6s of 10s after the first lease, 8s of 10s after the second and 4s of
+	// 10s after the third
+	assert.InDelta(t, 0.8, pod.MaxWindowUsage, 1e-9)
+	assert.Equal(t, 2, pod.LimitViolations)
+}
+
+func TestAnalyzeSlidingWindowClipsLeases(t *testing.T) {
+	entries := []leasehistory.Entry{
+		lease("a", 0, 0, 10, 0.2, 0.5),
+		lease("a", 14, 14, 16, 0.2, 0.5),
+	}
+
+	reports := Analyze(entries, Options{Window: 10 * time.Second})
+	pod := reports[0].Pods[0]
+
+	// the window after the second lease covers 6s..16s, only 4s of the first lease
+	assert.InDelta(t, 1.0, pod.MaxWindowUsage, 1e-9)
+	assert.Equal(t, 2, pod.LimitViolations)
+
+	// 10s of 20s is exactly at the limit, 12s of 20s is above it
+	reports = Analyze(entries, Options{Window: 20 * time.Second})
+	assert.InDelta(t, 0.6, reports[0].Pods[0].MaxWindowUsage, 1e-9)
+	assert.Equal(t, 1, reports[0].Pods[0].LimitViolations)
+}
+
+func TestAnalyzeSplitsDevicesAndSkipsUnknownQuota(t *testing.T) {
+	old := leasehistory.Entry{
+		DeviceId:   "device0",
+		PodId:      "a",
+		LeasedAt:   start,
+		ReturnedAt: start.Add(time.Second),
+	}
+	entries := []leasehistory.Entry{lease("a", 0, 0, 10, 0.5, 0.5), old}
+
+	reports := Analyze(entries, Options{Window: time.Second})
+	assert.Len(t, reports, 2)
+	assert.Equal(t, "device", reports[0].DeviceId)
+	assert.Equal(t, "device0", reports[1].DeviceId)
+
+	pod := reports[1].Pods[0]
+	assert.Equal(t, 0, pod.LimitViolations)
+	assert.Equal(t, 0.0, pod.RequestShare)
+	assert.Equal(t, 0, pod.QueueWait.Count)
+	assert.InDelta(t, 1.0, reports[1].Fairness, 1e-9)
+}
+
+func TestWriteFormats(t *testing.T) {
+	reports := Analyze([]leasehistory.Entry{
+		lease("a", 0, 0, 10, 0.5, 1),
+		lease("b", 0, 10, 20, 0.5, 1),
+	}, Options{Window: time.Minute})
+
+	var out bytes.Buffer
+	assert.Nil(t, Write(&out, reports, FormatTable))
+	assert.Contains(t, out.String(), "Jain's fairness index: 1.000")
+
+	out.Reset()
+	assert.Nil(t, Write(&out, reports, FormatJSON))
+	var decoded []*Report
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, reports, decoded)
+
+	out.Reset()
+	assert.Nil(t, Write(&out, reports, FormatCSV))
+	rows, err := csv.NewReader(&out).ReadAll()
+	assert.Nil(t, err)
+	assert.Len(t, rows, 4)
+	assert.Equal(t, []string{"device", "*", "2"}, rows[3][:3])
+
+	assert.NotNil(t, Write(&out, reports, "xml"))
+}
@@ -0,0 +1,109 @@
+package analysis
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+)
+
+// Write writes the reports to `w` in one of the formats above. Durations are
+// written in nanoseconds in JSON and in seconds in CSV.
+func Write(w io.Writer, reports []*Report, format string) error {
+	switch format {
+	case FormatTable:
+		return writeTable(w, reports)
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(reports)
+	case FormatCSV:
+		return writeCSV(w, reports)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func writeTable(w io.Writer, reports []*Report) error {
+	for i, report := range reports {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		fmt.Fprintf(w, "Device %s: %s - %s (%v)\n", report.DeviceId,
+			report.Start.Format(time.RFC3339), report.End.Format(time.RFC3339), report.Duration.Round(time.Millisecond))
+		fmt.Fprintf(w, "Total utilization: %.3f\n", report.Utilization)
+		fmt.Fprintf(w, "Jain's fairness index: %.3f\n", report.Fairness)
+		fmt.Fprintf(w, "Queue wait: p50 %v, p95 %v, p99 %v, max %v\n",
+			round(report.QueueWait.P50), round(report.QueueWait.P95), round(report.QueueWait.P99), round(report.QueueWait.Max))
+		fmt.Fprintf(w, "Idle gaps: %d, total %v, longest %v\n",
+			report.IdleGaps.Count, round(report.IdleGaps.Total), round(report.IdleGaps.Longest))
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "POD\tLEASES\tUTILIZATION\tREQUESTS\tLIMIT\tREQUEST SHARE\tLIMIT SHARE\tWAIT P50\tWAIT P95\tWAIT P99\tMAX WINDOW USAGE\tLIMIT VIOLATIONS")
+		for _, pod := range report.Pods {
+			fmt.Fprintf(tw, "%s\t%d\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\t%v\t%v\t%v\t%.3f\t%d\n",
+				pod.PodId, pod.Leases, pod.Utilization, pod.Requests, pod.Limit, pod.RequestShare, pod.LimitShare,
+				round(pod.QueueWait.P50), round(pod.QueueWait.P95), round(pod.QueueWait.P99),
+				pod.MaxWindowUsage, pod.LimitViolations)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCSV writes one row per pod and a row with the pod "*" for the device totals.
+func writeCSV(w io.Writer, reports []*Report) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		"device", "pod", "leases", "utilization", "fairness", "requests", "limit",
+		"request_share", "limit_share", "wait_p50_s", "wait_p95_s", "wait_p99_s", "wait_max_s",
+		"max_window_usage", "limit_violations", "idle_gaps", "idle_total_s", "idle_longest_s",
+	})
+
+	for _, report := range reports {
+		leases := 0
+		for _, pod := range report.Pods {
+			leases += pod.Leases
+			cw.Write([]string{
+				report.DeviceId, pod.PodId, strconv.Itoa(pod.Leases), float(pod.Utilization), "",
+				float(pod.Requests), float(pod.Limit), float(pod.RequestShare), float(pod.LimitShare),
+				seconds(pod.QueueWait.P50), seconds(pod.QueueWait.P95), seconds(pod.QueueWait.P99), seconds(pod.QueueWait.Max),
+				float(pod.MaxWindowUsage), strconv.Itoa(pod.LimitViolations), "", "", "",
+			})
+		}
+
+		cw.Write([]string{
+			report.DeviceId, "*", strconv.Itoa(leases), float(report.Utilization), float(report.Fairness),
+			"", "", "", "",
+			seconds(report.QueueWait.P50), seconds(report.QueueWait.P95), seconds(report.QueueWait.P99), seconds(report.QueueWait.Max),
+			"", "", strconv.Itoa(report.IdleGaps.Count), seconds(report.IdleGaps.Total), seconds(report.IdleGaps.Longest),
+		})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Millisecond)
+}
+
+func float(f float64) string {
+	return strconv.FormatFloat(f, 'f', 6, 64)
+}
+
+func seconds(d time.Duration) string {
+	return float(d.Seconds())
+}
@@ -0,0 +1,128 @@
+package analysis
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/zbsss/device-manager/internal/leasehistory"
+)
+
+// TraceEvent is a single event of the Chrome Trace Event format, which can be
+// opened in chrome://tracing and https://ui.perfetto.dev.
+type TraceEvent struct {
+	Name  string                 `json:"name"`
+	Cat   string                 `json:"cat,omitempty"`
+	Phase string                 `json:"ph"`
+	Ts    int64                  `json:"ts"`
+	Dur   int64                  `json:"dur,omitempty"`
+	Pid   int                    `json:"pid"`
+	Tid   int                    `json:"tid"`
+	Id    string                 `json:"id,omitempty"`
+	Scope string                 `json:"s,omitempty"`
+	Args  map[string]interface{} `json:"args,omitempty"`
+}
+
+// Trace is the JSON object format of a trace.
+type Trace struct {
+	TraceEvents     []TraceEvent `json:"traceEvents"`
+	DisplayTimeUnit string       `json:"displayTimeUnit"`
+}
+
+// NewTrace turns lease history into a trace with a process per device and a
+// thread per pod. Leases are complete events, the time a pod waited for the
+// token is an async event and leases terminated by the device manager end
+// with an instant event.
+func NewTrace(entries []leasehistory.Entry) *Trace {
+	trace := &Trace{
+		TraceEvents:     []TraceEvent{},
+		DisplayTimeUnit: "ms",
+	}
+
+	sorted := make([]leasehistory.Entry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].LeasedAt.Before(sorted[j].LeasedAt) })
+
+	pids := map[string]int{}
+	tids := map[string]map[string]int{}
+	for _, entry := range sorted {
+		pid, ok := pids[entry.DeviceId]
+		if !ok {
+			pid = len(pids) + 1
+			pids[entry.DeviceId] = pid
+			tids[entry.DeviceId] = map[string]int{}
+			trace.TraceEvents = append(trace.TraceEvents, metadataEvent("process_name", pid, 0, entry.DeviceId))
+		}
+
+		tid, ok := tids[entry.DeviceId][entry.PodId]
+		if !ok {
+			tid = len(tids[entry.DeviceId]) + 1
+			tids[entry.DeviceId][entry.PodId] = tid
+			trace.TraceEvents = append(trace.TraceEvents, metadataEvent("thread_name", pid, tid, entry.PodId))
+		}
+
+		args := map[string]interface{}{}
+		if entry.Requests > 0 || entry.Limit > 0 {
+			args["requests"] = entry.Requests
+			args["limit"] = entry.Limit
+		}
+		if entry.EndReason != "" {
+			args["endReason"] = entry.EndReason
+		}
+
+		trace.TraceEvents = append(trace.TraceEvents, TraceEvent{
+			Name:  "lease",
+			Cat:   "lease",
+			Phase: "X",
+			Ts:    micros(entry.LeasedAt),
+			Dur:   entry.ReturnedAt.Sub(entry.LeasedAt).Microseconds(),
+			Pid:   pid,
+			Tid:   tid,
+			Args:  args,
+		})
+
+		if !entry.EnqueuedAt.IsZero() && entry.LeasedAt.After(entry.EnqueuedAt) {
+			// async events of a category are matched by id
+			id := entry.DeviceId + "/" + entry.PodId + "/" + entry.LeasedAt.Format(time.RFC3339Nano)
+			trace.TraceEvents = append(trace.TraceEvents,
+				TraceEvent{Name: "queue wait", Cat: "queue", Phase: "b", Ts: micros(entry.EnqueuedAt), Pid: pid, Tid: tid, Id: id},
+				TraceEvent{Name: "queue wait", Cat: "queue", Phase: "e", Ts: micros(entry.LeasedAt), Pid: pid, Tid: tid, Id: id},
+			)
+		}
+
+		switch entry.EndReason {
+		case leasehistory.EndEvicted, leasehistory.EndDeleted, leasehistory.EndRevoked:
+			trace.TraceEvents = append(trace.TraceEvents, TraceEvent{
+				Name:  entry.EndReason,
+				Cat:   "enforcement",
+				Phase: "i",
+				Ts:    micros(entry.ReturnedAt),
+				Pid:   pid,
+				Tid:   tid,
+				Scope: "t",
+			})
+		}
+	}
+
+	return trace
+}
+
+// WriteTrace writes the trace of `entries` as JSON to `w`.
+func WriteTrace(w io.Writer, entries []leasehistory.Entry) error {
+	return json.NewEncoder(w).Encode(NewTrace(entries))
+}
+
+func metadataEvent(name string, pid, tid int, value string) TraceEvent {
+	return TraceEvent{
+		Name:  name,
+		Phase: "M",
+		Pid:   pid,
+		Tid:   tid,
+		Args:  map[string]interface{}{"name": value},
+	}
+}
+
+func micros(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Microsecond)
+}
@@ -0,0 +1,71 @@
+package analysis
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zbsss/device-manager/internal/leasehistory"
+)
+
+func TestNewTrace(t *testing.T) {
+	evicted := lease("b", 5, 10, 20, 0.5, 1)
+	evicted.DeviceId = "device0"
+	evicted.EndReason = leasehistory.EndEvicted
+	returned := lease("a", 0, 0, 10, 0.5, 1)
+	returned.EndReason = leasehistory.EndReturned
+	unknown := lease("b", 0, 0, 5, 0, 0)
+	unknown.EnqueuedAt = time.Time{}
+
+	trace := NewTrace([]leasehistory.Entry{evicted, returned, unknown})
+
+	var metadata, leases, waits, instants []TraceEvent
+	for _, event := range trace.TraceEvents {
+		switch event.Phase {
+		case "M":
+			metadata = append(metadata, event)
+		case "X":
+			leases = append(leases, event)
+		case "b", "e":
+			waits = append(waits, event)
+		case "i":
+			instants = append(instants, event)
+		}
+	}
+
+	// device, pod a and pod b, device0 and its pod b
+	assert.Len(t, metadata, 5)
+	assert.Equal(t, TraceEvent{Name: "process_name", Phase: "M", Pid: 1, Args: map[string]interface{}{"name": "device"}}, metadata[0])
+	assert.Equal(t, "thread_name", metadata[1].Name)
+
+	assert.Len(t, leases, 3)
+	assert.Equal(t, micros(start), leases[0].Ts)
+	assert.Equal(t, (10 * time.Second).Microseconds(), leases[0].Dur)
+	assert.Equal(t, "returned", leases[0].Args["endReason"])
+	assert.Nil(t, leases[1].Args["requests"])
+
+	// the pods b of both devices are different tracks
+	assert.NotEqual(t, leases[1].Pid, leases[2].Pid)
+
+	assert.Len(t, waits, 2)
+	assert.Equal(t, waits[0].Id, waits[1].Id)
+	assert.Equal(t, micros(start.Add(5*time.Second)), waits[0].Ts)
+	assert.Equal(t, micros(start.Add(10*time.Second)), waits[1].Ts)
+
+	assert.Len(t, instants, 1)
+	assert.Equal(t, leasehistory.EndEvicted, instants[0].Name)
+	assert.Equal(t, micros(start.Add(20*time.Second)), instants[0].Ts)
+	assert.Equal(t, leases[2].Tid, instants[0].Tid)
+}
+
+func TestWriteTrace(t *testing.T) {
+	var out bytes.Buffer
+	assert.Nil(t, WriteTrace(&out, nil))
+
+	var decoded map[string]interface{}
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, []interface{}{}, decoded["traceEvents"])
+	assert.Equal(t, "ms", decoded["displayTimeUnit"])
+}
@@ -0,0 +1,152 @@
+// Package budget caps the device quota the pods of a namespace may reserve.
+package budget
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/zbsss/device-manager/pkg/quota"
+)
+
+// Budget caps what the pods of a namespace may reserve on all devices of a
+// resource together. A nil cap does not limit the namespace, caps are rounded
+// to milli-shares.
+type Budget struct {
+	Namespace string `json:"namespace"`
+	// Resource is "vendor/model" of the devices, e.g. example.com/mydev.
+	Resource string `json:"resource"`
+	// Requests is the sum of requests in device shares, 1.5 is one and a half devices.
+	Requests *float64 `json:"requests,omitempty"`
+	// Memory is the share of the memory of all devices of the resource.
+	Memory *float64 `json:"memory,omitempty"`
+}
+
+// Usage is what the pods of a namespace reserved on the devices of a resource.
+type Usage struct {
+	Requests quota.MilliShares
+	MemoryB  uint64
+	// MemoryBTotal is the memory of all devices of the resource.
+	MemoryBTotal uint64
+}
+
+// MemoryShare is the share of the memory of all devices reserved by the namespace.
+func (u Usage) MemoryShare() float64 {
+	if u.MemoryBTotal == 0 {
+		return 0
+	}
+	return float64(u.MemoryB) / float64(u.MemoryBTotal)
+}
+
+// Resource is the name of the devices of a vendor and model in budgets.
+func Resource(vendor, model string) string {
+	return vendor + "/" + model
+}
+
+// Check returns an error if the usage exceeds the budget.
+func (b *Budget) Check(usage Usage) error {
+	if b.Requests != nil && usage.Requests > quota.FromFraction(*b.Requests) {
+		return fmt.Errorf("namespace %s would request %s of %s, its budget is %s", b.Namespace, usage.Requests, b.Resource, quota.FromFraction(*b.Requests))
+	}
+	if b.Memory != nil && usage.MemoryB > quota.FromFraction(*b.Memory).Of(usage.MemoryBTotal) {
+		return fmt.Errorf("namespace %s would reserve %.3f of the memory of %s, its budget is %.3f", b.Namespace, usage.MemoryShare(), b.Resource, *b.Memory)
+	}
+	return nil
+}
+
+func (b *Budget) validate() error {
+	if b.Namespace == "" {
+		return fmt.Errorf("budget without namespace")
+	}
+	if b.Resource == "" {
+		return fmt.Errorf("budget of namespace %s without resource", b.Namespace)
+	}
+	if b.Requests != nil && *b.Requests < 0 {
+		return fmt.Errorf("budget of namespace %s for %s: requests must be positive", b.Namespace, b.Resource)
+	}
+	if b.Memory != nil && (*b.Memory < 0 || *b.Memory > 1) {
+		return fmt.Errorf("budget of namespace %s for %s: memory must be between 0 and 1", b.Namespace, b.Resource)
+	}
+	return nil
+}
+
+// Parse reads a JSON list of budgets.
+func Parse(data []byte) ([]Budget, error) {
+	var budgets []Budget
+	if err := json.Unmarshal(data, &budgets); err != nil {
+		return nil, fmt.Errorf("failed to parse budgets: %w", err)
+	}
+	return budgets, nil
+}
+
+type key struct {
+	namespace string
+	resource  string
+}
+
+// Budgets holds the budgets of all namespaces, they can be replaced while
+// the device manager runs.
+type Budgets struct {
+	lock    sync.RWMutex
+	budgets map[key]Budget
+}
+
+func NewBudgets(budgets []Budget) (*Budgets, error) {
+	b := &Budgets{budgets: map[key]Budget{}}
+	if err := b.Set(budgets); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Set replaces all budgets, they are left unchanged if any is invalid.
+func (b *Budgets) Set(budgets []Budget) error {
+	byKey := map[key]Budget{}
+	for _, budget := range budgets {
+		if err := budget.validate(); err != nil {
+			return err
+		}
+		k := key{budget.Namespace, budget.Resource}
+		if _, ok := byKey[k]; ok {
+			return fmt.Errorf("duplicate budget of namespace %s for %s", budget.Namespace, budget.Resource)
+		}
+		byKey[k] = budget
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.budgets = byKey
+	return nil
+}
+
+// Get returns the budget of a namespace for a resource, false if it has none.
+func (b *Budgets) Get(namespace, resource string) (Budget, bool) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	budget, ok := b.budgets[key{namespace, resource}]
+	return budget, ok
+}
+
+// List returns the budgets sorted by namespace and resource, all namespaces
+// if namespace is empty.
+func (b *Budgets) List(namespace string) []Budget {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	budgets := []Budget{}
+	for k, budget := range b.budgets {
+		if namespace == "" || k.namespace == namespace {
+			budgets = append(budgets, budget)
+		}
+	}
+	sort.Slice(budgets, func(i, j int) bool {
+		if budgets[i].Namespace != budgets[j].Namespace {
+			return budgets[i].Namespace < budgets[j].Namespace
+		}
+		return budgets[i].Resource < budgets[j].Resource
+	})
+	return budgets
+}
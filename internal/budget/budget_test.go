@@ -0,0 +1,111 @@
+package budget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func share(v float64) *float64 {
+	return &v
+}
+
+func TestBudgetCheck(t *testing.T) {
+	b := Budget{Namespace: "ml", Resource: "example.com/mydev", Requests: share(1.5), Memory: share(0.4)}
+
+	assert.Nil(t, b.Check(Usage{Requests: 1500, MemoryB: 400, MemoryBTotal: 1000}))
+	assert.NotNil(t, b.Check(Usage{Requests: 1501, MemoryB: 0, MemoryBTotal: 1000}))
+	assert.NotNil(t, b.Check(Usage{Requests: 0, MemoryB: 401, MemoryBTotal: 1000}))
+
+	// caps which are not set do not limit the namespace
+	unlimited := Budget{Namespace: "ml", Resource: "example.com/mydev", Requests: share(1)}
+	assert.Nil(t, unlimited.Check(Usage{Requests: 1000, MemoryB: 1000, MemoryBTotal: 1000}))
+}
+
+func TestBudgetsSet(t *testing.T) {
+	budgets, err := NewBudgets([]Budget{{Namespace: "ml", Resource: "example.com/mydev", Requests: share(1.5)}})
+	assert.Nil(t, err)
+
+	for _, invalid := range [][]Budget{
+		{{Resource: "example.com/mydev"}},
+		{{Namespace: "ml"}},
+		{{Namespace: "ml", Resource: "example.com/mydev", Requests: share(-1)}},
+		{{Namespace: "ml", Resource: "example.com/mydev", Memory: share(1.5)}},
+		{{Namespace: "ml", Resource: "example.com/mydev"}, {Namespace: "ml", Resource: "example.com/mydev"}},
+	} {
+		assert.NotNil(t, budgets.Set(invalid), "%v", invalid)
+	}
+
+	// invalid budgets leave the previous ones
+	b, ok := budgets.Get("ml", "example.com/mydev")
+	assert.True(t, ok)
+	assert.Equal(t, 1.5, *b.Requests)
+
+	assert.Nil(t, budgets.Set([]Budget{
+		{Namespace: "web", Resource: "example.com/mydev", Memory: share(0.2)},
+		{Namespace: "ml", Resource: "example.com/other", Requests: share(1)},
+		{Namespace: "ml", Resource: "example.com/mydev", Requests: share(2)},
+	}))
+	assert.Len(t, budgets.List(""), 3)
+	assert.Equal(t, []Budget{
+		{Namespace: "ml", Resource: "example.com/mydev", Requests: share(2)},
+		{Namespace: "ml", Resource: "example.com/other", Requests: share(1)},
+	}, budgets.List("ml"))
+}
+
+func TestParse(t *testing.T) {
+	budgets, err := Parse([]byte(`[{"namespace": "ml", "resource": "example.com/mydev", "requests": 1.5, "memory": 0.4}]`))
+	assert.Nil(t, err)
+	assert.Equal(t, []Budget{{Namespace: "ml", Resource: "example.com/mydev", Requests: share(1.5), Memory: share(0.4)}}, budgets)
+
+	_, err = Parse([]byte(`{"namespace": "ml"}`))
+	assert.NotNil(t, err)
+}
+
+func TestWatchConfigMap(t *testing.T) {
+	ctx := context.Background()
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "budgets", Namespace: "sharedev", ResourceVersion: "1"},
+		Data:       map[string]string{ConfigMapKey: `[{"namespace": "ml", "resource": "example.com/mydev", "requests": 1.5}]`},
+	}
+	clientset := fake.NewSimpleClientset(configMap)
+	budgets, err := NewBudgets(nil)
+	assert.Nil(t, err)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	WatchConfigMap(clientset, "sharedev", "budgets", budgets, time.Minute, stop)
+
+	assert.Eventually(t, func() bool {
+		return len(budgets.List("")) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// an invalid update keeps the previous budgets
+	configMap.Data[ConfigMapKey] = `[{"namespace": "ml"}]`
+	configMap.ResourceVersion = "2"
+	_, err = clientset.CoreV1().ConfigMaps("sharedev").Update(ctx, configMap, metav1.UpdateOptions{})
+	assert.Nil(t, err)
+
+	configMap.Data[ConfigMapKey] = `[{"namespace": "ml", "resource": "example.com/mydev", "requests": 1}, {"namespace": "web", "resource": "example.com/mydev", "memory": 0.5}]`
+	configMap.ResourceVersion = "3"
+	_, err = clientset.CoreV1().ConfigMaps("sharedev").Update(ctx, configMap, metav1.UpdateOptions{})
+	assert.Nil(t, err)
+
+	assert.Eventually(t, func() bool {
+		return len(budgets.List("")) == 2
+	}, time.Second, 10*time.Millisecond)
+	b, _ := budgets.Get("ml", "example.com/mydev")
+	assert.Equal(t, 1.0, *b.Requests)
+
+	err = clientset.CoreV1().ConfigMaps("sharedev").Delete(ctx, "budgets", metav1.DeleteOptions{})
+	assert.Nil(t, err)
+
+	assert.Eventually(t, func() bool {
+		return len(budgets.List("")) == 0
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,65 @@
+package budget
+
+import (
+	"log"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ConfigMapKey is the key of the JSON list of budgets in the ConfigMap.
+const ConfigMapKey = "budgets.json"
+
+// WatchConfigMap replaces the budgets whenever the ConfigMap changes, an
+// invalid ConfigMap keeps the previous budgets and a deleted one removes them.
+// It returns once the ConfigMap was loaded, the watch stops when stop is closed.
+func WatchConfigMap(clientset kubernetes.Interface, namespace, name string, budgets *Budgets, resync time.Duration, stop <-chan struct{}) {
+	factory := informers.NewSharedInformerFactoryWithOptions(
+		clientset,
+		resync,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}),
+	)
+
+	load := func(obj interface{}) {
+		configMap, ok := obj.(*v1.ConfigMap)
+		if !ok {
+			return
+		}
+
+		parsed, err := Parse([]byte(configMap.Data[ConfigMapKey]))
+		if err == nil {
+			err = budgets.Set(parsed)
+		}
+		if err != nil {
+			log.Printf("Ignoring budgets in ConfigMap %s/%s: %v", namespace, name, err)
+			return
+		}
+		log.Printf("Loaded %d budgets from ConfigMap %s/%s", len(parsed), namespace, name)
+	}
+
+	informer := factory.Core().V1().ConfigMaps().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: load,
+		UpdateFunc: func(old, obj interface{}) {
+			// resyncs deliver the unchanged ConfigMap
+			if old.(*v1.ConfigMap).ResourceVersion != obj.(*v1.ConfigMap).ResourceVersion {
+				load(obj)
+			}
+		},
+		DeleteFunc: func(interface{}) {
+			log.Printf("ConfigMap %s/%s deleted, removing all budgets", namespace, name)
+			_ = budgets.Set(nil)
+		},
+	})
+
+	factory.Start(stop)
+	factory.WaitForCacheSync(stop)
+}
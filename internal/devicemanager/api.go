@@ -1,15 +1,24 @@
 package devicemanager
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"log"
 	"sync"
 	"time"
 
+	"github.com/zbsss/device-manager/internal/admission"
+	"github.com/zbsss/device-manager/internal/analysis"
+	"github.com/zbsss/device-manager/internal/budget"
+	"github.com/zbsss/device-manager/internal/leasehistory"
 	"github.com/zbsss/device-manager/internal/memorymanager"
+	"github.com/zbsss/device-manager/internal/podref"
 	"github.com/zbsss/device-manager/internal/scheduler"
+	"github.com/zbsss/device-manager/internal/store"
 	pb "github.com/zbsss/device-manager/pkg/devicemanager"
+	"github.com/zbsss/device-manager/pkg/quota"
+	"google.golang.org/grpc/status"
 )
 
 func (dm *DeviceManager) GetAvailableDevices(ctx context.Context, in *pb.GetAvailableDevicesRequest) (*pb.GetAvailableDevicesReply, error) {
@@ -23,10 +32,15 @@ func (dm *DeviceManager) GetAvailableDevices(ctx context.Context, in *pb.GetAvai
 	for _, device := range dm.devices {
 		device.lock.RLock()
 		if device.Vendor == in.Vendor && device.Model == in.Model {
+			memory, requests := device.mm.GetAvailableQuota(), device.sch.GetAvailableQuota()
 			devices = append(devices, &pb.FreeDeviceResources{
-				DeviceId: device.Id,
-				Memory:   device.mm.GetAvailableQuota(),
-				Requests: device.sch.GetAvailableQuota(),
+				DeviceId:      device.Id,
+				Memory:        memory.Fraction(),
+				Requests:      requests.Fraction(),
+				MemoryMilli:   int64(memory),
+				RequestsMilli: int64(requests),
+				MemoryBFree:   device.mm.GetAvailableMemoryB(),
+				MemoryBTotal:  device.MemoryB,
 			})
 		}
 		device.lock.RUnlock()
@@ -52,19 +66,66 @@ func (dm *DeviceManager) GetToken(ctx context.Context, in *pb.GetTokenRequest) (
 		return nil, fmt.Errorf("device %s not registered", in.DeviceId)
 	}
 
+	pod := podref.New(in.PodNamespace, in.PodId, in.PodUid)
+	if err := device.checkPod(pod); err != nil {
+		return nil, err
+	}
+
 	req := &scheduler.TokenLeaseRequest{
-		PodId:    in.PodId,
-		Response: make(chan *scheduler.TokenLease),
+		PodId:    pod.Key(),
+		Response: make(chan *scheduler.TokenLease, 1),
+	}
+
+	if err := device.sch.EnqueueLeaseRequest(req); err != nil {
+		return nil, err
 	}
 
-	device.sch.EnqueueLeaseRequest(req)
-	token := <-req.Response
+	var token *scheduler.TokenLease
+	select {
+	case token = <-req.Response:
+	case <-ctx.Done():
+		// the client is gone, the token must not wait for it
+		device.sch.CancelLeaseRequest(req)
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
 
 	if token == nil {
 		return nil, fmt.Errorf("token not available")
 	}
 
-	return &pb.GetTokenReply{ExpiresAt: token.ExpiresAt.Unix()}, nil
+	return &pb.GetTokenReply{ExpiresAt: token.ExpiresAt.Unix(), LeaseId: token.Id}, nil
+}
+
+func (dm *DeviceManager) TryGetToken(ctx context.Context, in *pb.GetTokenRequest) (*pb.TryGetTokenReply, error) {
+	if in.DeviceId == "" {
+		return nil, fmt.Errorf("device not specified")
+	}
+	if in.PodId == "" {
+		return nil, fmt.Errorf("pod not specified")
+	}
+
+	device := dm.GetDev(in.DeviceId)
+	if device == nil {
+		return nil, fmt.Errorf("device %s not registered", in.DeviceId)
+	}
+
+	pod := podref.New(in.PodNamespace, in.PodId, in.PodUid)
+	if err := device.checkPod(pod); err != nil {
+		return nil, err
+	}
+
+	token, estimate, err := device.sch.TryLease(pod.Key())
+	if err != nil {
+		return nil, err
+	}
+	if token != nil {
+		return &pb.TryGetTokenReply{Granted: true, ExpiresAt: token.ExpiresAt.Unix(), LeaseId: token.Id}, nil
+	}
+
+	return &pb.TryGetTokenReply{
+		QueuePosition:   int32(estimate.Position),
+		EstimatedWaitMs: estimate.Wait.Milliseconds(),
+	}, nil
 }
 
 func (dm *DeviceManager) ReturnToken(ctx context.Context, in *pb.ReturnTokenRequest) (*pb.ReturnTokenReply, error) {
@@ -82,14 +143,77 @@ func (dm *DeviceManager) ReturnToken(ctx context.Context, in *pb.ReturnTokenRequ
 		return nil, fmt.Errorf("device %s not registered", in.DeviceId)
 	}
 
-	err := device.sch.ReturnLease(&scheduler.TokenLease{PodId: in.PodId})
+	pod := podref.New(in.PodNamespace, in.PodId, in.PodUid)
+	if err := device.checkPod(pod); err != nil {
+		return nil, err
+	}
+
+	err := device.sch.ReturnLease(&scheduler.TokenLease{Id: in.LeaseId, PodId: pod.Key()})
 	if err != nil {
 		log.Printf("Error returning token: %s", err)
+		return nil, err
 	}
 
 	return &pb.ReturnTokenReply{}, nil
 }
 
+func (dm *DeviceManager) WatchLease(in *pb.WatchLeaseRequest, stream pb.DeviceManager_WatchLeaseServer) error {
+	log.Printf("Received: WatchLease for device %s from pod %s", in.DeviceId, in.PodId)
+
+	if in.DeviceId == "" {
+		return fmt.Errorf("device not specified")
+	}
+	if in.PodId == "" {
+		return fmt.Errorf("pod not specified")
+	}
+
+	device := dm.GetDev(in.DeviceId)
+	if device == nil {
+		return fmt.Errorf("device %s not registered", in.DeviceId)
+	}
+
+	pod := podref.New(in.PodNamespace, in.PodId, in.PodUid)
+	if err := device.checkPod(pod); err != nil {
+		return err
+	}
+
+	notices, cancel := device.sch.WatchLease(pod.Key())
+	defer cancel()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case notice, ok := <-notices:
+			if !ok {
+				return fmt.Errorf("device %s deregistered", in.DeviceId)
+			}
+
+			err := stream.Send(noticeToPb(notice))
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func noticeToPb(notice *scheduler.LeaseNotice) *pb.LeaseNotice {
+	if notice.Type == scheduler.NoticeMemoryPressure {
+		return &pb.LeaseNotice{
+			Type:         pb.LeaseNotice_MEMORY_PRESSURE,
+			Reason:       notice.Reason,
+			MemoryBLimit: notice.MemoryBLimit,
+			MemoryBUsed:  notice.MemoryBUsed,
+		}
+	}
+	return &pb.LeaseNotice{
+		Type:    pb.LeaseNotice_REVOKE,
+		YieldBy: notice.YieldBy.Unix(),
+		Reason:  notice.Reason,
+		LeaseId: notice.LeaseId,
+	}
+}
+
 func (dm *DeviceManager) AllocateMemory(ctx context.Context, in *pb.AllocateMemoryRequest) (*pb.AllocateMemoryReply, error) {
 	// log.Printf("Received: GetMemoryQuota for device %s: %d", in.DeviceId, in.MemoryB)
 
@@ -108,12 +232,27 @@ func (dm *DeviceManager) AllocateMemory(ctx context.Context, in *pb.AllocateMemo
 		return nil, fmt.Errorf("device %s not registered", in.DeviceId)
 	}
 
-	err := device.mm.AllocateMemory(in.PodId, in.MemoryB)
+	pod := podref.New(in.PodNamespace, in.PodId, in.PodUid)
+	if err := device.checkPod(pod); err != nil {
+		return nil, err
+	}
+
+	// the lock keeps the log in the order the allocations were made
+	device.lock.Lock()
+	defer device.lock.Unlock()
+
+	allocationId, err := device.mm.AllocateMemory(pod.Key(), in.MemoryB)
+	if err != nil {
+		return nil, err
+	}
+
+	err = dm.persist(store.Op{Type: store.OpAllocateMemory, DeviceId: in.DeviceId, PodId: pod.Key(), MemoryB: in.MemoryB, AllocationId: allocationId})
 	if err != nil {
+		_, _ = device.mm.FreeMemory(pod.Key(), allocationId)
 		return nil, err
 	}
 
-	return &pb.AllocateMemoryReply{}, nil
+	return &pb.AllocateMemoryReply{AllocationId: allocationId}, nil
 }
 
 func (dm *DeviceManager) FreeMemory(ctx context.Context, in *pb.FreeMemoryRequest) (*pb.FreeMemoryReply, error) {
@@ -125,8 +264,8 @@ func (dm *DeviceManager) FreeMemory(ctx context.Context, in *pb.FreeMemoryReques
 	if in.PodId == "" {
 		return nil, fmt.Errorf("pod not specified")
 	}
-	if in.MemoryB <= 0 {
-		return nil, fmt.Errorf("memory value is invalid")
+	if in.AllocationId == 0 {
+		return nil, fmt.Errorf("allocation not specified")
 	}
 
 	device := dm.GetDev(in.DeviceId)
@@ -134,9 +273,31 @@ func (dm *DeviceManager) FreeMemory(ctx context.Context, in *pb.FreeMemoryReques
 		return nil, fmt.Errorf("device %s not registered", in.DeviceId)
 	}
 
-	device.mm.FreeMemory(in.PodId, in.MemoryB)
+	pod := podref.New(in.PodNamespace, in.PodId, in.PodUid)
+	if err := device.checkPod(pod); err != nil {
+		return nil, err
+	}
 
-	return &pb.FreeMemoryReply{}, nil
+	device.lock.Lock()
+	defer device.lock.Unlock()
+
+	// the allocations of a released pod were dropped with its quota
+	if _, ok := device.Pods[pod.Key()]; !ok {
+		return &pb.FreeMemoryReply{}, nil
+	}
+
+	memoryB, err := device.mm.FreeMemory(pod.Key(), in.AllocationId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = dm.persist(store.Op{Type: store.OpFreeMemory, DeviceId: in.DeviceId, PodId: pod.Key(), MemoryB: memoryB, AllocationId: in.AllocationId})
+	if err != nil {
+		_ = device.mm.RestoreAllocation(pod.Key(), in.AllocationId, memoryB)
+		return nil, err
+	}
+
+	return &pb.FreeMemoryReply{MemoryB: memoryB}, nil
 }
 
 func (dm *DeviceManager) RegisterDevice(ctx context.Context, in *pb.RegisterDeviceRequest) (*pb.RegisterDeviceReply, error) {
@@ -145,6 +306,7 @@ func (dm *DeviceManager) RegisterDevice(ctx context.Context, in *pb.RegisterDevi
 	if in.AllocatorPodId == "" {
 		return nil, fmt.Errorf("allocator pod not specified")
 	}
+	allocatorPod := podref.New(in.AllocatorPodNamespace, in.AllocatorPodId, in.AllocatorPodUid)
 	if in.DeviceId == "" {
 		return nil, fmt.Errorf("device not specified")
 	}
@@ -162,16 +324,30 @@ func (dm *DeviceManager) RegisterDevice(ctx context.Context, in *pb.RegisterDevi
 	dm.lock.Lock()
 	defer dm.lock.Unlock()
 
+	err := dm.persist(store.Op{
+		Type:            store.OpRegisterDevice,
+		DeviceId:        in.DeviceId,
+		AllocatorPodId:  allocatorPod.Key(),
+		AllocatorPodUID: allocatorPod.UID,
+		Vendor:          in.Vendor,
+		Model:           in.Model,
+		MemoryB:         in.MemoryB,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	dm.devices[in.DeviceId] = &Device{
-		lock:           &sync.RWMutex{},
-		sch:            dm.sf.StartScheduler(in.DeviceId),
-		mm:             memorymanager.NewMemoryManager(in.DeviceId, in.MemoryB),
-		Id:             in.DeviceId,
-		AllocatorPodId: in.AllocatorPodId,
-		Vendor:         in.Vendor,
-		Model:          in.Model,
-		Pods:           map[string]bool{},
-		LastUsedAt:     time.Now(),
+		lock:         &sync.RWMutex{},
+		sch:          dm.sf.StartScheduler(in.DeviceId),
+		mm:           memorymanager.NewMemoryManager(in.DeviceId, in.MemoryB, dm.sf.AdmissionPolicy(in.DeviceId)),
+		Id:           in.DeviceId,
+		AllocatorPod: allocatorPod,
+		Vendor:       in.Vendor,
+		Model:        in.Model,
+		MemoryB:      in.MemoryB,
+		Pods:         map[string]podref.Ref{},
+		LastUsedAt:   time.Now(),
 	}
 
 	return &pb.RegisterDeviceReply{}, nil
@@ -187,14 +363,19 @@ func (dm *DeviceManager) ReservePodQuota(ctx context.Context, in *pb.ReservePodQ
 		return nil, fmt.Errorf("pod not specified")
 	}
 
-	if in.Requests > in.Limit {
+	pod := podref.New(in.PodNamespace, in.PodId, in.PodUid)
+	q, err := dm.requestedQuota(in, pod)
+	if err != nil {
+		return nil, err
+	}
+	if q.Requests > q.Limit {
 		return nil, fmt.Errorf("requests > limit")
 	}
-	if in.Requests < 0 || in.Limit < 0 || in.Memory < 0 {
+	if q.Requests < 0 || q.Limit < 0 || q.Memory < 0 {
 		return nil, fmt.Errorf("requests, limit and memory must be positive")
 	}
-	if in.Limit == 0 {
-		in.Limit = in.Requests
+	if q.Limit == 0 {
+		q.Limit = q.Requests
 	}
 
 	device := dm.GetDev(in.DeviceId)
@@ -202,25 +383,371 @@ func (dm *DeviceManager) ReservePodQuota(ctx context.Context, in *pb.ReservePodQ
 		return nil, fmt.Errorf("device %s not registered", in.DeviceId)
 	}
 
+	memoryB, err := memoryBytes(device, in.MemoryB, q.Memory)
+	if err != nil {
+		return nil, err
+	}
+
+	dm.reserveLock.Lock()
+	defer dm.reserveLock.Unlock()
+
+	// listed before locking the device, the lock of the device manager is always taken first
+	devices := dm.devicesOf(budget.Resource(device.Vendor, device.Model))
+
 	device.lock.Lock()
 	defer device.lock.Unlock()
 
-	err := device.sch.ReservePodQuota(
+	// the quota of a pod recreated with the same name is not inherited
+	if reserved, ok := device.Pods[pod.Key()]; ok && !reserved.Matches(pod) {
+		log.Printf("Pod %s was recreated, removing the quota of the old pod from device %s", pod, device.Id)
+		dm.unreservePodQuota(device, pod.Key())
+	}
+
+	if err := dm.checkBudget(pod, device, devices, q.Requests, memoryB); err != nil {
+		return nil, err
+	}
+
+	// a pod reserving again keeps its uid and allocations, and its
+	// reservation if the new one fails
+	var old *scheduler.PodQuota
+	var oldMemoryB uint64
+	if reserved, ok := device.Pods[pod.Key()]; ok {
+		if pod.UID == "" {
+			pod.UID = reserved.UID
+		}
+		usage, err := device.sch.GetPodUsage(pod.Key())
+		if err != nil {
+			return nil, err
+		}
+		podMem, err := device.mm.GetPodMemory(pod.Key())
+		if err != nil {
+			return nil, err
+		}
+		old, oldMemoryB = &usage.PodQuota, podMem.MemoryBLimit
+	}
+	rollback := func(memory bool) {
+		if old == nil {
+			device.sch.UnreservePodQuota(pod.Key())
+			if memory {
+				device.mm.UnreservePodQuota(pod.Key())
+			}
+			return
+		}
+		_, _ = device.sch.ReservePodQuota(old)
+		if memory {
+			_, _ = device.mm.ResizePodQuota(pod.Key(), oldMemoryB)
+		}
+	}
+
+	group := dm.podGroup(pod)
+	requestsAdmission, err := device.sch.ReservePodQuota(
 		&scheduler.PodQuota{
-			PodId: in.PodId, Requests: in.Requests, Limit: in.Limit,
+			PodId: pod.Key(), UID: pod.UID, Requests: q.Requests, Limit: q.Limit, Priority: in.Priority, Group: group,
 		},
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	err = device.mm.ReservePodQuota(in.PodId, in.Memory)
+	memoryAdmission, err := device.mm.ReservePodQuota(pod, memoryB)
+	if err != nil {
+		rollback(false)
+		return nil, err
+	}
+
+	err = dm.persist(store.Op{
+		Type:          store.OpReservePod,
+		DeviceId:      in.DeviceId,
+		PodId:         pod.Key(),
+		PodUID:        pod.UID,
+		RequestsMilli: q.Requests,
+		LimitMilli:    q.Limit,
+		Priority:      in.Priority,
+		MemoryB:       memoryB,
+		Group:         group,
+	})
 	if err != nil {
-		device.sch.UnreservePodQuota(in.PodId)
+		rollback(true)
 		return nil, err
 	}
 
-	device.Pods[in.PodId] = true
+	device.Pods[pod.Key()] = pod
+
+	return &pb.ReservePodQuotaReply{
+		RequestsAdmission: admissionToPb(requestsAdmission),
+		MemoryAdmission:   admissionToPb(memoryAdmission),
+	}, nil
+}
+
+func (dm *DeviceManager) UpdatePodQuota(ctx context.Context, in *pb.UpdatePodQuotaRequest) (*pb.UpdatePodQuotaReply, error) {
+	log.Printf("Received: UpdatePodQuota for device %s and pod %s", in.DeviceId, in.PodId)
+
+	if in.DeviceId == "" {
+		return nil, fmt.Errorf("device not specified")
+	}
+	if in.PodId == "" {
+		return nil, fmt.Errorf("pod not specified")
+	}
+
+	requests, limit := quota.MilliShares(in.RequestsMilli), quota.MilliShares(in.LimitMilli)
+	if limit == 0 {
+		limit = requests
+	}
+	if requests > limit {
+		return nil, fmt.Errorf("requests > limit")
+	}
+	if requests < 0 || in.MemoryMilli < 0 {
+		return nil, fmt.Errorf("requests, limit and memory must be positive")
+	}
+
+	device := dm.GetDev(in.DeviceId)
+	if device == nil {
+		return nil, fmt.Errorf("device %s not registered", in.DeviceId)
+	}
+
+	memoryB, err := memoryBytes(device, in.MemoryB, quota.MilliShares(in.MemoryMilli))
+	if err != nil {
+		return nil, err
+	}
+
+	pod := podref.New(in.PodNamespace, in.PodId, in.PodUid)
+	if err := device.checkPod(pod); err != nil {
+		return nil, err
+	}
+
+	dm.reserveLock.Lock()
+	defer dm.reserveLock.Unlock()
+
+	// listed before locking the device, the lock of the device manager is always taken first
+	devices := dm.devicesOf(budget.Resource(device.Vendor, device.Model))
+
+	device.lock.Lock()
+	defer device.lock.Unlock()
+
+	if _, ok := device.Pods[pod.Key()]; !ok {
+		return nil, fmt.Errorf("pod %s has no quota on device %s", pod, device.Id)
+	}
+	usage, err := device.sch.GetPodUsage(pod.Key())
+	if err != nil {
+		return nil, err
+	}
+	podMem, err := device.mm.GetPodMemory(pod.Key())
+	if err != nil {
+		return nil, err
+	}
+	// an update of requests and limit only keeps the memory reservation
+	if in.MemoryB == 0 && in.MemoryMilli == 0 {
+		memoryB = podMem.MemoryBLimit
+	}
+
+	if memoryB < podMem.MemoryBUsed && !in.AllowMemoryPressure {
+		return nil, fmt.Errorf("pod %s allocated %d B, more than the new memory quota of %d B", pod, podMem.MemoryBUsed, memoryB)
+	}
+	if err := dm.checkBudget(pod, device, devices, requests, memoryB); err != nil {
+		return nil, err
+	}
+
+	old := usage.PodQuota
+	updated := old
+	updated.Requests, updated.Limit = requests, limit
+	requestsAdmission, err := device.sch.ReservePodQuota(&updated)
+	if err != nil {
+		return nil, err
+	}
+
+	memoryAdmission, err := device.mm.ResizePodQuota(pod.Key(), memoryB)
+	if err != nil {
+		_, _ = device.sch.ReservePodQuota(&old)
+		return nil, err
+	}
+
+	err = dm.persist(store.Op{
+		Type:          store.OpUpdatePod,
+		DeviceId:      device.Id,
+		PodId:         pod.Key(),
+		RequestsMilli: requests,
+		LimitMilli:    limit,
+		MemoryB:       memoryB,
+	})
+	if err != nil {
+		_, _ = device.sch.ReservePodQuota(&old)
+		_, _ = device.mm.ResizePodQuota(pod.Key(), podMem.MemoryBLimit)
+		return nil, err
+	}
+
+	reply := &pb.UpdatePodQuotaReply{
+		RequestsAdmission: admissionToPb(requestsAdmission),
+		MemoryAdmission:   admissionToPb(memoryAdmission),
+	}
+	if memoryB < podMem.MemoryBUsed {
+		reply.MemoryBOverLimit = podMem.MemoryBUsed - memoryB
+		notified := device.sch.SendNotice(&scheduler.LeaseNotice{
+			PodId:        pod.Key(),
+			Type:         scheduler.NoticeMemoryPressure,
+			Reason:       "memory quota reduced",
+			MemoryBLimit: memoryB,
+			MemoryBUsed:  podMem.MemoryBUsed,
+		})
+		log.Printf("Memory quota of pod %s on device %s reduced to %d B, %d B below its allocations (notified: %v)", pod, device.Id, memoryB, reply.MemoryBOverLimit, notified)
+	}
+
+	return reply, nil
+}
+
+// memoryBytes is the memory given in bytes or as a share of the memory of the device.
+func memoryBytes(device *Device, memoryB uint64, memory quota.MilliShares) (uint64, error) {
+	if memoryB > 0 && memory > 0 {
+		return 0, fmt.Errorf("memory is given both in bytes and as a share of the device")
+	}
+	if memoryB > 0 {
+		return memoryB, nil
+	}
+	return memory.Of(device.MemoryB), nil
+}
+
+// requestedQuota reads the quota in milli-shares, the fractions sent by older
+// clients are rounded to the nearest milli-share. A request without any quota
+// takes it from the labels of the pod.
+func (dm *DeviceManager) requestedQuota(in *pb.ReservePodQuotaRequest, pod podref.Ref) (quota.Quota, error) {
+	if in.RequestsMilli != 0 || in.LimitMilli != 0 || in.MemoryMilli != 0 {
+		return quota.Quota{
+			Requests: quota.MilliShares(in.RequestsMilli),
+			Limit:    quota.MilliShares(in.LimitMilli),
+			Memory:   quota.MilliShares(in.MemoryMilli),
+		}, nil
+	}
+	if in.Requests != 0 || in.Limit != 0 || in.Memory != 0 || in.MemoryB != 0 {
+		return quota.Quota{
+			Requests: quota.FromFraction(in.Requests),
+			Limit:    quota.FromFraction(in.Limit),
+			Memory:   quota.FromFraction(in.Memory),
+		}, nil
+	}
+	return dm.labeledQuota(pod)
+}
+
+func admissionToPb(result admission.Result) pb.Admission {
+	switch result {
+	case admission.Overcommitted:
+		return pb.Admission_OVERCOMMITTED
+	case admission.NotGuaranteed:
+		return pb.Admission_BEST_EFFORT
+	}
+	return pb.Admission_GUARANTEED
+}
+
+func (dm *DeviceManager) GetLeaseHistory(ctx context.Context, in *pb.GetLeaseHistoryRequest) (*pb.GetLeaseHistoryReply, error) {
+	log.Printf("Received: GetLeaseHistory for device %s and pod %s", in.DeviceId, in.PodId)
+
+	if dm.history == nil {
+		return nil, fmt.Errorf("lease history not available")
+	}
+
+	var entries []*pb.LeaseHistoryEntry
+	for _, entry := range dm.history.Query(historyFilter(in.DeviceId, in.PodNamespace, in.PodId, in.Since, in.Until)) {
+		pod := podref.ParseKey(entry.PodId)
+		entries = append(entries, &pb.LeaseHistoryEntry{
+			DeviceId:     entry.DeviceId,
+			PodId:        pod.Name,
+			PodNamespace: pod.Namespace,
+			LeasedAtMs:   entry.LeasedAt.UnixMilli(),
+			ReturnedAtMs: entry.ReturnedAt.UnixMilli(),
+			EndReason:    entry.EndReason,
+		})
+	}
+
+	return &pb.GetLeaseHistoryReply{Entries: entries}, nil
+}
+
+func (dm *DeviceManager) ExportTrace(ctx context.Context, in *pb.ExportTraceRequest) (*pb.ExportTraceReply, error) {
+	log.Printf("Received: ExportTrace for device %s and pod %s", in.DeviceId, in.PodId)
+
+	if dm.history == nil {
+		return nil, fmt.Errorf("lease history not available")
+	}
+
+	var buf bytes.Buffer
+	err := analysis.WriteTrace(&buf, dm.history.Query(historyFilter(in.DeviceId, in.PodNamespace, in.PodId, in.Since, in.Until)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to export trace: %w", err)
+	}
+
+	return &pb.ExportTraceReply{Trace: buf.Bytes()}, nil
+}
+
+// historyFilter converts the unix time range of a request, 0 leaves it open.
+func historyFilter(deviceId, podNamespace, podName string, since, until int64) leasehistory.Filter {
+	filter := leasehistory.Filter{DeviceId: deviceId, PodNamespace: podNamespace, PodName: podName}
+	if since > 0 {
+		filter.Since = time.Unix(since, 0)
+	}
+	if until > 0 {
+		filter.Until = time.Unix(until, 0)
+	}
+	return filter
+}
+
+func (dm *DeviceManager) GetPodUsage(ctx context.Context, in *pb.GetPodUsageRequest) (*pb.GetPodUsageReply, error) {
+	log.Printf("Received: GetPodUsage for device %s and pod %s", in.DeviceId, in.PodId)
+
+	if in.DeviceId == "" {
+		return nil, fmt.Errorf("device not specified")
+	}
+	if in.PodId == "" {
+		return nil, fmt.Errorf("pod not specified")
+	}
+
+	device := dm.GetDev(in.DeviceId)
+	if device == nil {
+		return nil, fmt.Errorf("device %s not registered", in.DeviceId)
+	}
+
+	pod := podref.New(in.PodNamespace, in.PodId, in.PodUid)
+	if err := device.checkPod(pod); err != nil {
+		return nil, err
+	}
+
+	usage, err := device.sch.GetPodUsage(pod.Key())
+	if err != nil {
+		return nil, err
+	}
+
+	podMem, err := device.mm.GetPodMemory(pod.Key())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetPodUsageReply{
+		Used:           usage.Used,
+		Requests:       usage.Requests.Fraction(),
+		Limit:          usage.Limit.Fraction(),
+		Priority:       usage.Priority,
+		Group:          usage.GroupName(),
+		WindowSeconds:  int64(usage.WindowDuration.Seconds()),
+		HoldsToken:     usage.HoldsToken,
+		QueuedRequests: uint32(usage.QueuedRequests),
+		QueueWait: &pb.QueueWaitStats{
+			Count:  uint64(usage.QueueWait.Count),
+			MeanMs: milliseconds(usage.QueueWait.Mean),
+			P50Ms:  milliseconds(usage.QueueWait.P50),
+			P95Ms:  milliseconds(usage.QueueWait.P95),
+			MaxMs:  milliseconds(usage.QueueWait.Max),
+		},
+		Memory:       quota.ShareOf(podMem.MemoryBLimit, device.MemoryB).Fraction(),
+		MemoryBLimit: podMem.MemoryBLimit,
+		MemoryBUsed:  podMem.MemoryBUsed,
+		Allocations:  allocationsToPb(podMem.Allocations),
+	}, nil
+}
+
+func allocationsToPb(allocations []memorymanager.Allocation) []*pb.MemoryAllocation {
+	var pbAllocations []*pb.MemoryAllocation
+	for _, a := range allocations {
+		pbAllocations = append(pbAllocations, &pb.MemoryAllocation{AllocationId: a.Id, MemoryB: a.MemoryB})
+	}
+	return pbAllocations
+}
 
-	return &pb.ReservePodQuotaReply{}, nil
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
 }
@@ -0,0 +1,459 @@
+package devicemanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zbsss/device-manager/internal/admission"
+	"github.com/zbsss/device-manager/internal/scheduler"
+	pb "github.com/zbsss/device-manager/pkg/devicemanager"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPodsInDifferentNamespacesDoNotCollide(t *testing.T) {
+	ctx := context.Background()
+	dm := NewDeviceManager(newTestSchedulerFactory(t), nil, nil, nil, nil)
+	defer dm.Stop()
+
+	registerDevice(t, dm, "device", "allocator")
+	for _, namespace := range []string{"team1", "team2"} {
+		_, err := dm.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{
+			DeviceId: "device", PodId: "a", PodNamespace: namespace, Requests: 0.3, Limit: 0.3, Memory: 0.5,
+		})
+		assert.Nil(t, err)
+	}
+
+	_, err := dm.AllocateMemory(ctx, &pb.AllocateMemoryRequest{DeviceId: "device", PodId: "a", PodNamespace: "team1", MemoryB: 100})
+	assert.Nil(t, err)
+
+	usage, err := dm.GetPodUsage(ctx, &pb.GetPodUsageRequest{DeviceId: "device", PodId: "a", PodNamespace: "team1"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), usage.MemoryBUsed)
+
+	usage, err = dm.GetPodUsage(ctx, &pb.GetPodUsageRequest{DeviceId: "device", PodId: "a", PodNamespace: "team2"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), usage.MemoryBUsed)
+
+	// clients which do not send the namespace are in the default namespace
+	_, err = dm.GetPodUsage(ctx, &pb.GetPodUsageRequest{DeviceId: "device", PodId: "a"})
+	assert.NotNil(t, err)
+}
+
+func TestRecreatedPodIsRejected(t *testing.T) {
+	ctx := context.Background()
+	dm := NewDeviceManager(newTestSchedulerFactory(t), nil, nil, nil, nil)
+	defer dm.Stop()
+
+	registerDevice(t, dm, "device", "allocator")
+	_, err := dm.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{DeviceId: "device", PodId: "a", PodUid: "old", Requests: 0.3, Limit: 0.3, Memory: 0.5})
+	assert.Nil(t, err)
+	_, err = dm.AllocateMemory(ctx, &pb.AllocateMemoryRequest{DeviceId: "device", PodId: "a", MemoryB: 100})
+	assert.Nil(t, err)
+
+	_, err = dm.GetToken(ctx, &pb.GetTokenRequest{DeviceId: "device", PodId: "a", PodUid: "new"})
+	assert.NotNil(t, err)
+	_, err = dm.AllocateMemory(ctx, &pb.AllocateMemoryRequest{DeviceId: "device", PodId: "a", PodUid: "new", MemoryB: 1})
+	assert.NotNil(t, err)
+
+	// the recreated pod reserves its own quota, without the memory of the old pod
+	_, err = dm.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{DeviceId: "device", PodId: "a", PodUid: "new", Requests: 0.3, Limit: 0.3, Memory: 0.5})
+	assert.Nil(t, err)
+
+	usage, err := dm.GetPodUsage(ctx, &pb.GetPodUsageRequest{DeviceId: "device", PodId: "a", PodUid: "new"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), usage.MemoryBUsed)
+
+	_, err = dm.GetToken(ctx, &pb.GetTokenRequest{DeviceId: "device", PodId: "a", PodUid: "old"})
+	assert.NotNil(t, err)
+	_, err = dm.GetToken(ctx, &pb.GetTokenRequest{DeviceId: "device", PodId: "a"})
+	assert.Nil(t, err)
+}
+
+func TestPodsAreGroupedByLabel(t *testing.T) {
+	ctx := context.Background()
+	labeled := newPod("a", PodTypeClient)
+	labeled.Labels[groupLabel] = "research"
+	clientset := fake.NewSimpleClientset(newPod("allocator", PodTypeAllocator), labeled, newPod("b", PodTypeClient))
+	dm := NewDeviceManager(newTestSchedulerFactory(t), clientset, nil, nil, nil)
+	defer dm.Stop()
+
+	registerDevice(t, dm, "device", "allocator", "a", "b")
+
+	usage, err := dm.GetPodUsage(ctx, &pb.GetPodUsageRequest{DeviceId: "device", PodId: "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, "research", usage.Group)
+
+	usage, err = dm.GetPodUsage(ctx, &pb.GetPodUsageRequest{DeviceId: "device", PodId: "b"})
+	assert.Nil(t, err)
+	assert.Equal(t, "default", usage.Group)
+}
+
+func TestReservePodQuotaReadsQuotaFromLabels(t *testing.T) {
+	ctx := context.Background()
+	labeled := newPod("a", PodTypeClient)
+	labeled.Labels["sharedev.requests"] = "250m"
+	labeled.Labels["sharedev.limits"] = "0.5"
+	labeled.Labels["sharedev.memory"] = "0.25"
+	invalid := newPod("b", PodTypeClient)
+	invalid.Labels["sharedev.requests"] = "0.0001"
+	clientset := fake.NewSimpleClientset(newPod("allocator", PodTypeAllocator), labeled, invalid)
+	dm := NewDeviceManager(newTestSchedulerFactory(t), clientset, nil, nil, nil)
+	defer dm.Stop()
+
+	registerDevice(t, dm, "device", "allocator")
+
+	_, err := dm.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{DeviceId: "device", PodId: "a"})
+	assert.Nil(t, err)
+	usage, err := dm.GetPodUsage(ctx, &pb.GetPodUsageRequest{DeviceId: "device", PodId: "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, 0.25, usage.Requests)
+	assert.Equal(t, 0.5, usage.Limit)
+	assert.Equal(t, uint64(256), usage.MemoryBLimit)
+
+	// a quota in the request takes precedence over the labels
+	_, err = dm.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{DeviceId: "device", PodId: "a", RequestsMilli: 100, LimitMilli: 100})
+	assert.Nil(t, err)
+	usage, err = dm.GetPodUsage(ctx, &pb.GetPodUsageRequest{DeviceId: "device", PodId: "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, 0.1, usage.Requests)
+
+	_, err = dm.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{DeviceId: "device", PodId: "b"})
+	assert.NotNil(t, err)
+}
+
+func TestReservePodQuotaReportsAdmission(t *testing.T) {
+	ctx := context.Background()
+	sf, err := scheduler.NewSchedulerFactory(scheduler.SchedulerConfig{
+		WindowDuration:  time.Minute,
+		EvictionPeriod:  time.Minute,
+		Policy:          scheduler.PolicyFairShare,
+		Admission:       admission.Policy{Mode: admission.Overcommit, OvercommitRatio: 1.5},
+		DeviceAdmission: map[string]admission.Policy{"best-effort": {Mode: admission.BestEffort}, "strict": {Mode: admission.Strict}},
+	})
+	assert.Nil(t, err)
+	dm := NewDeviceManager(sf, nil, nil, nil, nil)
+	defer dm.Stop()
+
+	reserve := func(deviceId, podId string, requests, memory float64) (*pb.ReservePodQuotaReply, error) {
+		return dm.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{DeviceId: deviceId, PodId: podId, Requests: requests, Limit: 1, Memory: memory})
+	}
+
+	for _, deviceId := range []string{"overcommit", "best-effort", "strict"} {
+		registerDevice(t, dm, deviceId, "allocator-"+deviceId)
+
+		reply, err := reserve(deviceId, "a", 0.9, 0.6)
+		assert.Nil(t, err)
+		assert.Equal(t, pb.Admission_GUARANTEED, reply.RequestsAdmission)
+		assert.Equal(t, pb.Admission_GUARANTEED, reply.MemoryAdmission)
+	}
+
+	// 0.9 of the device is requested, 0.5 more only fits with overcommit
+	_, err = reserve("strict", "b", 0.5, 0)
+	assert.NotNil(t, err)
+	reply, err := reserve("overcommit", "b", 0.5, 0.4)
+	assert.Nil(t, err)
+	assert.Equal(t, pb.Admission_OVERCOMMITTED, reply.RequestsAdmission)
+	assert.Equal(t, pb.Admission_GUARANTEED, reply.MemoryAdmission)
+	_, err = reserve("overcommit", "c", 0.2, 0)
+	assert.NotNil(t, err)
+	reply, err = reserve("best-effort", "b", 1, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, pb.Admission_BEST_EFFORT, reply.RequestsAdmission)
+	assert.Equal(t, pb.Admission_BEST_EFFORT, reply.MemoryAdmission)
+
+	// a rejected memory reservation leaves no requests reserved
+	_, err = reserve("overcommit", "c", 0, 0.6)
+	assert.NotNil(t, err)
+	assert.Equal(t, map[string]bool{"default/a": true, "default/b": true}, reservedPods(dm, "overcommit"))
+
+	// reserving again replaces the reservation instead of adding to it
+	reply, err = reserve("strict", "a", 1, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, pb.Admission_GUARANTEED, reply.RequestsAdmission)
+}
+
+func TestReservePodQuotaInMilliShares(t *testing.T) {
+	ctx := context.Background()
+	dm := NewDeviceManager(newTestSchedulerFactory(t), nil, nil, nil, nil)
+	defer dm.Stop()
+
+	registerDevice(t, dm, "device", "allocator")
+	for _, podId := range []string{"a", "b", "c"} {
+		_, err := dm.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{
+			DeviceId: "device", PodId: podId, RequestsMilli: 333, LimitMilli: 1000, MemoryMilli: 250,
+		})
+		assert.Nil(t, err)
+	}
+	// fractions of older clients are rounded to the nearest milli-share
+	_, err := dm.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{DeviceId: "device", PodId: "d", Requests: 0.0012, Limit: 1, Memory: 0.25})
+	assert.Nil(t, err)
+
+	available, err := dm.GetAvailableDevices(ctx, &pb.GetAvailableDevicesRequest{Vendor: "vendor", Model: "model"})
+	assert.Nil(t, err)
+	assert.Len(t, available.Free, 1)
+	assert.Equal(t, int64(0), available.Free[0].RequestsMilli)
+	assert.Equal(t, int64(0), available.Free[0].MemoryMilli)
+
+	_, err = dm.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{DeviceId: "device", PodId: "e", RequestsMilli: 1, LimitMilli: 1})
+	assert.NotNil(t, err)
+}
+
+func TestReservePodQuotaInBytes(t *testing.T) {
+	ctx := context.Background()
+	dm := NewDeviceManager(newTestSchedulerFactory(t), nil, nil, nil, nil)
+	defer dm.Stop()
+
+	registerDevice(t, dm, "device", "allocator")
+	_, err := dm.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{DeviceId: "device", PodId: "a", Requests: 0.1, Limit: 1, MemoryB: 300})
+	assert.Nil(t, err)
+	_, err = dm.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{DeviceId: "device", PodId: "b", Requests: 0.1, Limit: 1, Memory: 0.25})
+	assert.Nil(t, err)
+
+	usage, err := dm.GetPodUsage(ctx, &pb.GetPodUsageRequest{DeviceId: "device", PodId: "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(300), usage.MemoryBLimit)
+
+	available, err := dm.GetAvailableDevices(ctx, &pb.GetAvailableDevicesRequest{Vendor: "vendor", Model: "model"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1024-300-256), available.Free[0].MemoryBFree)
+	assert.Equal(t, uint64(1024), available.Free[0].MemoryBTotal)
+	// the reserved share is rounded up
+	assert.Equal(t, int64(457), available.Free[0].MemoryMilli)
+
+	for _, invalid := range []*pb.ReservePodQuotaRequest{
+		{DeviceId: "device", PodId: "c", MemoryB: 1025},
+		{DeviceId: "device", PodId: "c", MemoryB: 469},
+		{DeviceId: "device", PodId: "c", MemoryB: 100, MemoryMilli: 100},
+	} {
+		_, err = dm.ReservePodQuota(ctx, invalid)
+		assert.NotNil(t, err, "%v", invalid)
+	}
+}
+
+func TestFreeMemoryByAllocationId(t *testing.T) {
+	ctx := context.Background()
+	dm := NewDeviceManager(newTestSchedulerFactory(t), nil, nil, nil, nil)
+	defer dm.Stop()
+
+	registerDevice(t, dm, "device", "allocator", "a", "b")
+	allocate := func(podId string) uint64 {
+		reply, err := dm.AllocateMemory(ctx, &pb.AllocateMemoryRequest{DeviceId: "device", PodId: podId, MemoryB: 10})
+		assert.Nil(t, err)
+		return reply.AllocationId
+	}
+	free := func(podId string, allocationId uint64) error {
+		_, err := dm.FreeMemory(ctx, &pb.FreeMemoryRequest{DeviceId: "device", PodId: podId, AllocationId: allocationId})
+		return err
+	}
+
+	first, second, other := allocate("a"), allocate("a"), allocate("b")
+	assert.NotEqual(t, first, second)
+
+	assert.Nil(t, free("a", first))
+	assert.ErrorContains(t, free("a", first), "already freed")
+	assert.ErrorContains(t, free("a", 1000), "unknown allocation")
+	assert.NotNil(t, free("a", other))
+	assert.NotNil(t, free("a", 0))
+
+	usage, err := dm.GetPodUsage(ctx, &pb.GetPodUsageRequest{DeviceId: "device", PodId: "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(10), usage.MemoryBUsed)
+	assert.Len(t, usage.Allocations, 1)
+	assert.Equal(t, second, usage.Allocations[0].AllocationId)
+
+	// allocations which were not freed before the pod was released leaked
+	device := dm.GetDev("device")
+	device.lock.Lock()
+	dm.unreservePodQuota(device, "default/a")
+	device.lock.Unlock()
+	allocations, memoryB := device.mm.GetLeaks()
+	assert.Equal(t, 1, allocations)
+	assert.Equal(t, uint64(10), memoryB)
+}
+
+func TestReservePodQuotaAgainKeepsAllocations(t *testing.T) {
+	ctx := context.Background()
+	dm := NewDeviceManager(newTestSchedulerFactory(t), nil, nil, nil, nil)
+	defer dm.Stop()
+
+	registerDevice(t, dm, "device", "allocator")
+	reserve := func(memoryB uint64) error {
+		_, err := dm.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{DeviceId: "device", PodId: "a", PodUid: "uid", RequestsMilli: 200, LimitMilli: 500, MemoryB: memoryB})
+		return err
+	}
+	assert.Nil(t, reserve(512))
+	allocation, err := dm.AllocateMemory(ctx, &pb.AllocateMemoryRequest{DeviceId: "device", PodId: "a", MemoryB: 300})
+	assert.Nil(t, err)
+
+	// a retried reservation keeps the allocation, one below it is refused
+	assert.Nil(t, reserve(512))
+	assert.NotNil(t, reserve(200))
+
+	usage, err := dm.GetPodUsage(ctx, &pb.GetPodUsageRequest{DeviceId: "device", PodId: "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(512), usage.MemoryBLimit)
+	assert.Equal(t, uint64(300), usage.MemoryBUsed)
+	assert.Len(t, usage.Allocations, 1)
+
+	_, err = dm.FreeMemory(ctx, &pb.FreeMemoryRequest{DeviceId: "device", PodId: "a", AllocationId: allocation.AllocationId})
+	assert.Nil(t, err)
+	allocations, _ := dm.GetDev("device").mm.GetLeaks()
+	assert.Zero(t, allocations)
+}
+
+func TestUpdatePodQuota(t *testing.T) {
+	ctx := context.Background()
+	dm := NewDeviceManager(newTestSchedulerFactory(t), nil, nil, nil, nil)
+	defer dm.Stop()
+
+	registerDevice(t, dm, "device", "allocator", "b")
+	_, err := dm.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{DeviceId: "device", PodId: "a", RequestsMilli: 200, LimitMilli: 500, MemoryB: 512})
+	assert.Nil(t, err)
+	allocation, err := dm.AllocateMemory(ctx, &pb.AllocateMemoryRequest{DeviceId: "device", PodId: "a", MemoryB: 300})
+	assert.Nil(t, err)
+
+	reply, err := dm.UpdatePodQuota(ctx, &pb.UpdatePodQuotaRequest{DeviceId: "device", PodId: "a", RequestsMilli: 400, LimitMilli: 800, MemoryB: 400})
+	assert.Nil(t, err)
+	assert.Equal(t, pb.Admission_GUARANTEED, reply.RequestsAdmission)
+	assert.Equal(t, uint64(0), reply.MemoryBOverLimit)
+
+	// the allocations are kept
+	usage, err := dm.GetPodUsage(ctx, &pb.GetPodUsageRequest{DeviceId: "device", PodId: "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, 0.4, usage.Requests)
+	assert.Equal(t, 0.8, usage.Limit)
+	assert.Equal(t, uint64(400), usage.MemoryBLimit)
+	assert.Equal(t, uint64(300), usage.MemoryBUsed)
+	assert.Len(t, usage.Allocations, 1)
+	assert.Equal(t, allocation.AllocationId, usage.Allocations[0].AllocationId)
+
+	// a rejected update leaves the quota as it was
+	for _, invalid := range []*pb.UpdatePodQuotaRequest{
+		{DeviceId: "device", PodId: "a", RequestsMilli: 1000, MemoryB: 400},
+		{DeviceId: "device", PodId: "a", RequestsMilli: 400, MemoryB: 1000},
+		{DeviceId: "device", PodId: "a", RequestsMilli: 500, LimitMilli: 400, MemoryB: 400},
+		{DeviceId: "device", PodId: "c", RequestsMilli: 100},
+	} {
+		_, err = dm.UpdatePodQuota(ctx, invalid)
+		assert.NotNil(t, err, "%v", invalid)
+	}
+	usage, err = dm.GetPodUsage(ctx, &pb.GetPodUsageRequest{DeviceId: "device", PodId: "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, 0.4, usage.Requests)
+	assert.Equal(t, uint64(400), usage.MemoryBLimit)
+
+	// an update without memory keeps the memory reservation
+	_, err = dm.UpdatePodQuota(ctx, &pb.UpdatePodQuotaRequest{DeviceId: "device", PodId: "a", RequestsMilli: 300, LimitMilli: 600})
+	assert.Nil(t, err)
+	usage, err = dm.GetPodUsage(ctx, &pb.GetPodUsageRequest{DeviceId: "device", PodId: "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, 0.3, usage.Requests)
+	assert.Equal(t, 0.6, usage.Limit)
+	assert.Equal(t, uint64(400), usage.MemoryBLimit)
+	assert.Equal(t, uint64(300), usage.MemoryBUsed)
+
+	// shrinking below the allocations needs the consent of the caller
+	_, err = dm.UpdatePodQuota(ctx, &pb.UpdatePodQuotaRequest{DeviceId: "device", PodId: "a", RequestsMilli: 400, MemoryB: 200})
+	assert.NotNil(t, err)
+
+	notices, cancel := dm.GetDev("device").sch.WatchLease("default/a")
+	defer cancel()
+	reply, err = dm.UpdatePodQuota(ctx, &pb.UpdatePodQuotaRequest{DeviceId: "device", PodId: "a", RequestsMilli: 400, MemoryB: 200, AllowMemoryPressure: true})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), reply.MemoryBOverLimit)
+
+	select {
+	case notice := <-notices:
+		assert.Equal(t, pb.LeaseNotice_MEMORY_PRESSURE, noticeToPb(notice).Type)
+		assert.Equal(t, uint64(200), notice.MemoryBLimit)
+		assert.Equal(t, uint64(300), notice.MemoryBUsed)
+	case <-time.After(time.Second):
+		t.Fatal("no memory pressure notice")
+	}
+
+	// nothing more is allocated until the pod frees enough
+	_, err = dm.AllocateMemory(ctx, &pb.AllocateMemoryRequest{DeviceId: "device", PodId: "a", MemoryB: 1})
+	assert.NotNil(t, err)
+	_, err = dm.FreeMemory(ctx, &pb.FreeMemoryRequest{DeviceId: "device", PodId: "a", AllocationId: allocation.AllocationId})
+	assert.Nil(t, err)
+	_, err = dm.AllocateMemory(ctx, &pb.AllocateMemoryRequest{DeviceId: "device", PodId: "a", MemoryB: 200})
+	assert.Nil(t, err)
+}
+
+func TestGetTokenRespectsDeadline(t *testing.T) {
+	ctx := context.Background()
+	dm := NewDeviceManager(newTestSchedulerFactory(t), nil, nil, nil, nil)
+	defer dm.Stop()
+
+	registerDevice(t, dm, "device", "allocator", "a", "b", "c")
+	token, err := dm.GetToken(ctx, &pb.GetTokenRequest{DeviceId: "device", PodId: "a"})
+	assert.Nil(t, err)
+
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = dm.GetToken(timeout, &pb.GetTokenRequest{DeviceId: "device", PodId: "b"})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	// the request which timed out left the queue
+	reply, err := dm.TryGetToken(ctx, &pb.GetTokenRequest{DeviceId: "device", PodId: "c"})
+	assert.Nil(t, err)
+	assert.False(t, reply.Granted)
+	assert.Equal(t, int32(1), reply.QueuePosition)
+	assert.Greater(t, reply.EstimatedWaitMs, int64(0))
+
+	_, err = dm.ReturnToken(ctx, &pb.ReturnTokenRequest{DeviceId: "device", PodId: "a", LeaseId: token.LeaseId})
+	assert.Nil(t, err)
+	reply, err = dm.TryGetToken(ctx, &pb.GetTokenRequest{DeviceId: "device", PodId: "c"})
+	assert.Nil(t, err)
+	assert.True(t, reply.Granted)
+}
+
+func TestGetTokenRequiresReservation(t *testing.T) {
+	ctx := context.Background()
+	dm := NewDeviceManager(newTestSchedulerFactory(t), nil, nil, nil, nil)
+	defer dm.Stop()
+
+	registerDevice(t, dm, "device", "allocator", "a")
+
+	_, err := dm.TryGetToken(ctx, &pb.GetTokenRequest{DeviceId: "device", PodId: "unreserved"})
+	assert.NotNil(t, err)
+	_, err = dm.GetToken(ctx, &pb.GetTokenRequest{DeviceId: "device", PodId: "unreserved"})
+	assert.NotNil(t, err)
+
+	// the scheduler still serves reserved pods
+	reply, err := dm.TryGetToken(ctx, &pb.GetTokenRequest{DeviceId: "device", PodId: "a"})
+	assert.Nil(t, err)
+	assert.True(t, reply.Granted)
+}
+
+func TestReturnTokenIsFencedByLeaseId(t *testing.T) {
+	ctx := context.Background()
+	dm := NewDeviceManager(newTestSchedulerFactory(t), nil, nil, nil, nil)
+	defer dm.Stop()
+
+	registerDevice(t, dm, "device", "allocator", "a")
+	first, err := dm.GetToken(ctx, &pb.GetTokenRequest{DeviceId: "device", PodId: "a"})
+	assert.Nil(t, err)
+	_, err = dm.ReturnToken(ctx, &pb.ReturnTokenRequest{DeviceId: "device", PodId: "a", LeaseId: first.LeaseId})
+	assert.Nil(t, err)
+
+	second, err := dm.GetToken(ctx, &pb.GetTokenRequest{DeviceId: "device", PodId: "a"})
+	assert.Nil(t, err)
+	assert.Greater(t, second.LeaseId, first.LeaseId)
+
+	// a late return of the first lease leaves the second one alone
+	_, err = dm.ReturnToken(ctx, &pb.ReturnTokenRequest{DeviceId: "device", PodId: "a", LeaseId: first.LeaseId})
+	assert.NotNil(t, err)
+	_, err = dm.ReturnToken(ctx, &pb.ReturnTokenRequest{DeviceId: "device", PodId: "a"})
+	assert.NotNil(t, err)
+	usage, err := dm.GetPodUsage(ctx, &pb.GetPodUsageRequest{DeviceId: "device", PodId: "a"})
+	assert.Nil(t, err)
+	assert.True(t, usage.HoldsToken)
+
+	_, err = dm.ReturnToken(ctx, &pb.ReturnTokenRequest{DeviceId: "device", PodId: "a", LeaseId: second.LeaseId})
+	assert.Nil(t, err)
+}
@@ -0,0 +1,98 @@
+package devicemanager
+
+import (
+	"context"
+	"log"
+
+	"github.com/zbsss/device-manager/internal/budget"
+	"github.com/zbsss/device-manager/internal/podref"
+	pb "github.com/zbsss/device-manager/pkg/devicemanager"
+	"github.com/zbsss/device-manager/pkg/quota"
+)
+
+func (dm *DeviceManager) GetNamespaceBudgets(ctx context.Context, in *pb.GetNamespaceBudgetsRequest) (*pb.GetNamespaceBudgetsReply, error) {
+	log.Printf("Received: GetNamespaceBudgets for namespace %s", in.Namespace)
+
+	if dm.budgets == nil {
+		return &pb.GetNamespaceBudgetsReply{}, nil
+	}
+
+	var budgets []*pb.NamespaceBudget
+	for _, b := range dm.budgets.List(in.Namespace) {
+		usage := namespaceUsage(b.Namespace, dm.devicesOf(b.Resource))
+		budgets = append(budgets, &pb.NamespaceBudget{
+			Namespace:     b.Namespace,
+			Resource:      b.Resource,
+			RequestsLimit: budgetLimit(b.Requests),
+			RequestsUsed:  usage.Requests.Fraction(),
+			MemoryLimit:   budgetLimit(b.Memory),
+			MemoryUsed:    usage.MemoryShare(),
+			MemoryBUsed:   usage.MemoryB,
+			MemoryBTotal:  usage.MemoryBTotal,
+		})
+	}
+
+	return &pb.GetNamespaceBudgetsReply{Budgets: budgets}, nil
+}
+
+// checkBudget returns an error if reserving requests and memory for the pod on
+// the device exceeds the budget of its namespace on all `devices` of its
+// resource. The reservation the pod already has on the device is replaced. The
+// caller holds reserveLock, so that no other reservation is checked against
+// the same usage.
+func (dm *DeviceManager) checkBudget(pod podref.Ref, device *Device, devices []*Device, requests quota.MilliShares, memoryB uint64) error {
+	if dm.budgets == nil {
+		return nil
+	}
+
+	resource := budget.Resource(device.Vendor, device.Model)
+	b, ok := dm.budgets.Get(pod.Namespace, resource)
+	if !ok {
+		return nil
+	}
+
+	usage := namespaceUsage(pod.Namespace, devices)
+	if reserved, err := device.sch.GetPodUsage(pod.Key()); err == nil {
+		usage.Requests -= reserved.Requests
+	}
+	if reserved, err := device.mm.GetPodMemory(pod.Key()); err == nil {
+		usage.MemoryB -= reserved.MemoryBLimit
+	}
+	usage.Requests += requests
+	usage.MemoryB += memoryB
+
+	return b.Check(usage)
+}
+
+// devicesOf returns the registered devices of a resource.
+func (dm *DeviceManager) devicesOf(resource string) []*Device {
+	dm.lock.RLock()
+	defer dm.lock.RUnlock()
+
+	var devices []*Device
+	for _, device := range dm.devices {
+		if budget.Resource(device.Vendor, device.Model) == resource {
+			devices = append(devices, device)
+		}
+	}
+	return devices
+}
+
+// namespaceUsage sums what the pods of the namespace reserved on the devices.
+func namespaceUsage(namespace string, devices []*Device) budget.Usage {
+	usage := budget.Usage{}
+	for _, device := range devices {
+		usage.Requests += device.sch.GetNamespaceQuota(namespace)
+		usage.MemoryB += device.mm.GetNamespaceMemoryB(namespace)
+		usage.MemoryBTotal += device.MemoryB
+	}
+	return usage
+}
+
+// budgetLimit reports a cap which is not set as -1.
+func budgetLimit(limit *float64) float64 {
+	if limit == nil {
+		return -1
+	}
+	return *limit
+}
@@ -0,0 +1,79 @@
+package devicemanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zbsss/device-manager/internal/budget"
+	pb "github.com/zbsss/device-manager/pkg/devicemanager"
+)
+
+func share(v float64) *float64 {
+	return &v
+}
+
+func TestReservePodQuotaChecksNamespaceBudget(t *testing.T) {
+	ctx := context.Background()
+	budgets, err := budget.NewBudgets([]budget.Budget{
+		{Namespace: "ml", Resource: "vendor/model", Requests: share(1.5), Memory: share(0.4)},
+	})
+	assert.Nil(t, err)
+	dm := NewDeviceManager(newTestSchedulerFactory(t), nil, nil, nil, budgets)
+	defer dm.Stop()
+
+	registerDevice(t, dm, "device1", "allocator1")
+	registerDevice(t, dm, "device2", "allocator2")
+
+	reserve := func(deviceId, namespace, podId string, requests, memory float64) error {
+		_, err := dm.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{
+			DeviceId: deviceId, PodNamespace: namespace, PodId: podId, Requests: requests, Limit: requests, Memory: memory,
+		})
+		return err
+	}
+
+	assert.Nil(t, reserve("device1", "ml", "a", 0.8, 0.3))
+	assert.Nil(t, reserve("device2", "ml", "b", 0.7, 0.3))
+	// 1.5 of 1.5 device shares are reserved
+	assert.NotNil(t, reserve("device2", "ml", "c", 0.1, 0))
+	// 0.6 of 2 devices memory is 30%, another 0.3 would be 45%
+	assert.NotNil(t, reserve("device1", "ml", "c", 0, 0.3))
+	assert.Nil(t, reserve("device1", "ml", "c", 0, 0.2))
+
+	// an update replaces the reservation of the pod in the budget
+	_, err = dm.UpdatePodQuota(ctx, &pb.UpdatePodQuotaRequest{DeviceId: "device2", PodNamespace: "ml", PodId: "b", RequestsMilli: 700, MemoryMilli: 200})
+	assert.Nil(t, err)
+	_, err = dm.UpdatePodQuota(ctx, &pb.UpdatePodQuotaRequest{DeviceId: "device2", PodNamespace: "ml", PodId: "b", RequestsMilli: 800, MemoryMilli: 200})
+	assert.NotNil(t, err)
+	assert.Nil(t, reserve("device2", "ml", "b", 0.7, 0.3))
+
+	// other namespaces are not limited
+	assert.Nil(t, reserve("device2", "web", "a", 0.3, 0.3))
+
+	reply, err := dm.GetNamespaceBudgets(ctx, &pb.GetNamespaceBudgetsRequest{})
+	assert.Nil(t, err)
+	assert.Len(t, reply.Budgets, 1)
+	assert.Equal(t, "ml", reply.Budgets[0].Namespace)
+	assert.Equal(t, "vendor/model", reply.Budgets[0].Resource)
+	assert.Equal(t, 1.5, reply.Budgets[0].RequestsLimit)
+	assert.InDelta(t, 1.5, reply.Budgets[0].RequestsUsed, 1e-9)
+	assert.Equal(t, 0.4, reply.Budgets[0].MemoryLimit)
+	assert.InDelta(t, 0.4, reply.Budgets[0].MemoryUsed, 0.01)
+	assert.Equal(t, uint64(2048), reply.Budgets[0].MemoryBTotal)
+
+	// released quota can be reserved again
+	device := dm.GetDev("device1")
+	device.lock.Lock()
+	dm.unreservePodQuota(device, "ml/a")
+	device.lock.Unlock()
+	assert.Nil(t, reserve("device1", "ml", "d", 0.5, 0))
+}
+
+func TestGetNamespaceBudgetsWithoutBudgets(t *testing.T) {
+	dm := NewDeviceManager(newTestSchedulerFactory(t), nil, nil, nil, nil)
+	defer dm.Stop()
+
+	reply, err := dm.GetNamespaceBudgets(context.Background(), &pb.GetNamespaceBudgetsRequest{Namespace: "ml"})
+	assert.Nil(t, err)
+	assert.Empty(t, reply.Budgets)
+}
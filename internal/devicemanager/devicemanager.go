@@ -1,13 +1,23 @@
 package devicemanager
 
 import (
+	"fmt"
 	"log"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/zbsss/device-manager/internal/budget"
+	"github.com/zbsss/device-manager/internal/leasehistory"
+	"github.com/zbsss/device-manager/internal/memorymanager"
+	"github.com/zbsss/device-manager/internal/podref"
 	"github.com/zbsss/device-manager/internal/scheduler"
+	"github.com/zbsss/device-manager/internal/store"
 	pb "github.com/zbsss/device-manager/pkg/devicemanager"
+	"github.com/zbsss/device-manager/pkg/quota"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
 )
 
 type DeviceManager struct {
@@ -15,21 +25,205 @@ type DeviceManager struct {
 	lock    *sync.RWMutex
 	devices map[string]*Device
 	sf      scheduler.SchedulerFactory
+	history *leasehistory.RingBuffer
+	store   *store.Store
+
+	budgets *budget.Budgets
+	// reserveLock serializes reservations, so that each is checked against
+	// the budget of its namespace including all reservations before it.
+	reserveLock *sync.Mutex
+
+	clientset    kubernetes.Interface
+	pods         corelisters.PodLister
+	resyncPeriod time.Duration
+	done         chan struct{}
 }
 
-func NewDeviceManager(schedulerWindow, schedulerTokenExpiration time.Duration) *DeviceManager {
+// NewDeviceManager creates a DeviceManager serving lease history from `history`
+// and persisting its state to `st`. Reservations of pods which are not running
+// in the cluster of `clientset` are released and reservations exceeding the
+// budget of their namespace in `budgets` are rejected. All four may be nil. The
+// state in `st` is restored and reconciled with the running pods before it returns.
+func NewDeviceManager(sf scheduler.SchedulerFactory, clientset kubernetes.Interface, history *leasehistory.RingBuffer, st *store.Store, budgets *budget.Budgets) *DeviceManager {
 	dm := &DeviceManager{
-		lock:    &sync.RWMutex{},
-		devices: make(map[string]*Device),
-		sf:      scheduler.NewSchedulerFactory(schedulerWindow, schedulerTokenExpiration),
+		lock:         &sync.RWMutex{},
+		devices:      make(map[string]*Device),
+		sf:           sf,
+		history:      history,
+		store:        st,
+		budgets:      budgets,
+		reserveLock:  &sync.Mutex{},
+		clientset:    clientset,
+		resyncPeriod: GCResyncPeriod,
+		done:         make(chan struct{}),
+	}
+
+	if st != nil {
+		dm.restore(st.State())
+	}
+
+	if clientset != nil {
+		dm.startPodInformer()
+		dm.collectGarbage()
+		go dm.runGarbageCollector()
 	}
 
 	go dm.stateLoggerDaemon()
-	go dm.runGarbageCollector()
 
 	return dm
 }
 
+// Stop stops watching pods and the schedulers of all devices.
+func (dm *DeviceManager) Stop() {
+	close(dm.done)
+
+	dm.lock.RLock()
+	defer dm.lock.RUnlock()
+
+	for _, device := range dm.devices {
+		device.sch.Stop()
+	}
+}
+
+// restore recreates the devices and reservations of a previous run.
+func (dm *DeviceManager) restore(state *store.State) {
+	for _, d := range state.Devices {
+		allocatorPod := podref.ParseKey(d.AllocatorPodId)
+		allocatorPod.UID = d.AllocatorPodUID
+
+		device := &Device{
+			lock:         &sync.RWMutex{},
+			sch:          dm.sf.StartScheduler(d.Id),
+			mm:           memorymanager.NewMemoryManager(d.Id, d.MemoryB, dm.sf.AdmissionPolicy(d.Id)),
+			Id:           d.Id,
+			AllocatorPod: allocatorPod,
+			Vendor:       d.Vendor,
+			Model:        d.Model,
+			MemoryB:      d.MemoryB,
+			Pods:         map[string]podref.Ref{},
+			LastUsedAt:   time.Now(),
+		}
+		device.mm.SkipAllocationIds(d.LastAllocationId)
+
+		for _, p := range d.Pods {
+			pod := podref.ParseKey(p.Id)
+			pod.UID = p.UID
+
+			_, err := device.sch.ReservePodQuota(&scheduler.PodQuota{
+				PodId: pod.Key(), UID: pod.UID, Requests: p.RequestsMilli, Limit: p.LimitMilli, Priority: p.Priority, Group: p.Group,
+			})
+			if err == nil {
+				_, err = device.mm.ReservePodQuota(pod, podMemoryB(d, p))
+			}
+			if err == nil {
+				err = dm.restoreAllocations(device, p, pod)
+			}
+			if err == nil && pod.Key() != p.Id {
+				err = dm.migratePod(device, p, pod)
+			}
+			if err != nil {
+				log.Printf("Failed to restore pod %s on device %s: %v", pod, d.Id, err)
+				device.sch.UnreservePodQuota(pod.Key())
+				device.mm.UnreservePodQuota(pod.Key())
+				dm.persist(store.Op{Type: store.OpUnreservePod, DeviceId: d.Id, PodId: p.Id})
+				continue
+			}
+			device.Pods[pod.Key()] = pod
+		}
+
+		dm.devices[d.Id] = device
+		log.Printf("Restored device %s with %d pods", d.Id, len(device.Pods))
+	}
+}
+
+// podMemoryB is the memory reserved by a persisted pod, older versions only
+// persisted it as a share of the device.
+func podMemoryB(d *store.Device, p *store.Pod) uint64 {
+	if p.MemoryBLimit > 0 {
+		return p.MemoryBLimit
+	}
+	return quota.FromFraction(p.Memory).Of(d.MemoryB)
+}
+
+// restoreAllocations recreates the allocations of a persisted pod with their
+// ids. Memory allocated before allocations had ids is restored as a single
+// allocation with a new id.
+func (dm *DeviceManager) restoreAllocations(device *Device, p *store.Pod, pod podref.Ref) error {
+	ids := make([]uint64, 0, len(p.Allocations))
+	for id := range p.Allocations {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var restoredB uint64
+	for _, id := range ids {
+		if err := device.mm.RestoreAllocation(pod.Key(), id, p.Allocations[id]); err != nil {
+			return err
+		}
+		restoredB += p.Allocations[id]
+	}
+	if p.MemoryBUsed <= restoredB {
+		return nil
+	}
+
+	memoryB := p.MemoryBUsed - restoredB
+	id, err := device.mm.AllocateMemory(pod.Key(), memoryB)
+	if err != nil {
+		return err
+	}
+	log.Printf("Restored %d B allocated by pod %s on device %s before allocations had ids as allocation %d", memoryB, pod, device.Id, id)
+
+	for _, op := range []store.Op{
+		{Type: store.OpFreeMemory, DeviceId: device.Id, PodId: p.Id, MemoryB: memoryB},
+		{Type: store.OpAllocateMemory, DeviceId: device.Id, PodId: p.Id, MemoryB: memoryB, AllocationId: id},
+	} {
+		if err := dm.persist(op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migratePod re-keys a pod persisted by its bare name before pods were
+// identified by namespace/name.
+func (dm *DeviceManager) migratePod(device *Device, old *store.Pod, pod podref.Ref) error {
+	ops := []store.Op{
+		{Type: store.OpUnreservePod, DeviceId: device.Id, PodId: old.Id},
+		{
+			Type: store.OpReservePod, DeviceId: device.Id, PodId: pod.Key(), PodUID: pod.UID,
+			RequestsMilli: old.RequestsMilli, LimitMilli: old.LimitMilli, Priority: old.Priority, Memory: old.Memory, MemoryB: old.MemoryBLimit, Group: old.Group,
+		},
+	}
+	podMem, err := device.mm.GetPodMemory(pod.Key())
+	if err != nil {
+		return err
+	}
+	for _, a := range podMem.Allocations {
+		ops = append(ops, store.Op{Type: store.OpAllocateMemory, DeviceId: device.Id, PodId: pod.Key(), MemoryB: a.MemoryB, AllocationId: a.Id})
+	}
+
+	for _, op := range ops {
+		if err := dm.persist(op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// persist logs a change of the state, it is a no-op without a store.
+func (dm *DeviceManager) persist(op store.Op) error {
+	if dm.store == nil {
+		return nil
+	}
+
+	err := dm.store.Append(op)
+	if err != nil {
+		log.Printf("Failed to persist %s: %v", op.Type, err)
+		return fmt.Errorf("failed to persist %s: %w", op.Type, err)
+	}
+	return nil
+}
+
 func (dm *DeviceManager) GetDev(deviceId string) *Device {
 	dm.lock.RLock()
 	defer dm.lock.RUnlock()
@@ -50,7 +244,7 @@ func (dm *DeviceManager) stateLoggerDaemon() {
 				"\nDeviceId: " + device.Id +
 					"\nVendor: " + device.Vendor +
 					"\nModel: " + device.Model +
-					"\nAllocatorPodId: " + device.AllocatorPodId,
+					"\nAllocatorPod: " + device.AllocatorPod.Key(),
 			)
 		}
 
@@ -68,6 +262,11 @@ func (dm *DeviceManager) stateLoggerDaemon() {
 		sb.WriteString("\n===================")
 
 		log.Println(sb.String())
-		time.Sleep(30 * time.Second)
+
+		select {
+		case <-dm.done:
+			return
+		case <-time.After(30 * time.Second):
+		}
 	}
 }
@@ -0,0 +1,233 @@
+package devicemanager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zbsss/device-manager/internal/podref"
+	"github.com/zbsss/device-manager/internal/store"
+	pb "github.com/zbsss/device-manager/pkg/devicemanager"
+)
+
+const testDeviceMemoryB = 1 << 20
+
+func startDeviceManager(t *testing.T, dir string) *DeviceManager {
+	t.Helper()
+
+	st, err := store.Open(store.Config{Dir: dir, SnapshotEvery: 50})
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+
+	return NewDeviceManager(newTestSchedulerFactory(t), nil, nil, st, nil)
+}
+
+func TestDeviceManagerRestoresStateAfterKill(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dm := startDeviceManager(t, dir)
+
+	_, err := dm.RegisterDevice(ctx, &pb.RegisterDeviceRequest{
+		AllocatorPodId: "allocator", DeviceId: "device", Vendor: "vendor", Model: "model", MemoryB: testDeviceMemoryB,
+	})
+	assert.Nil(t, err)
+
+	pods := []string{"a", "b", "c"}
+	for _, podId := range pods {
+		_, err := dm.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{
+			DeviceId: "device", PodId: podId, Requests: 0.2, Limit: 0.5, Memory: 0.25,
+		})
+		assert.Nil(t, err)
+	}
+
+	// allocate and free memory from every pod until the manager is killed, at
+	// most half of their limit so that there is room left after the restart
+	podLimit := uint64(0.25 * testDeviceMemoryB)
+	stop := make(chan struct{})
+	wg := &sync.WaitGroup{}
+	acknowledged := make([]uint64, len(pods))
+	allocations := make([][]uint64, len(pods))
+	for i, podId := range pods {
+		wg.Add(1)
+		go func(i int, podId string) {
+			defer wg.Done()
+
+			for n := 0; ; n++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				if last := len(allocations[i]) - 1; n%3 == 2 && last >= 0 {
+					_, err := dm.FreeMemory(ctx, &pb.FreeMemoryRequest{DeviceId: "device", PodId: podId, AllocationId: allocations[i][last]})
+					assert.Nil(t, err)
+					acknowledged[i] -= 1024
+					allocations[i] = allocations[i][:last]
+				} else if acknowledged[i] >= podLimit/2 {
+					continue
+				} else if reply, err := dm.AllocateMemory(ctx, &pb.AllocateMemoryRequest{DeviceId: "device", PodId: podId, MemoryB: 1024}); err == nil {
+					acknowledged[i] += 1024
+					allocations[i] = append(allocations[i], reply.AllocationId)
+				}
+			}
+		}(i, podId)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+	// stop without closing the store, as if the process was killed
+	dm.Stop()
+
+	// the crash tore the record being written
+	wal, err := os.OpenFile(filepath.Join(dir, "wal.log"), os.O_APPEND|os.O_WRONLY, 0o644)
+	assert.Nil(t, err)
+	_, err = wal.WriteString(`0badc0de {"seq":`)
+	assert.Nil(t, err)
+	wal.Close()
+
+	restarted := startDeviceManager(t, dir)
+	defer restarted.Stop()
+
+	devices, err := restarted.GetAvailableDevices(ctx, &pb.GetAvailableDevicesRequest{Vendor: "vendor", Model: "model"})
+	assert.Nil(t, err)
+	assert.Len(t, devices.Free, 1)
+	assert.InDelta(t, 0.4, devices.Free[0].Requests, 1e-9)
+	assert.InDelta(t, 0.25, devices.Free[0].Memory, 1e-9)
+
+	for i, podId := range pods {
+		usage, err := restarted.GetPodUsage(ctx, &pb.GetPodUsageRequest{DeviceId: "device", PodId: podId})
+		assert.Nil(t, err)
+		assert.Equal(t, acknowledged[i], usage.MemoryBUsed, "pod %s", podId)
+		assert.Equal(t, 0.5, usage.Limit)
+		assert.Len(t, usage.Allocations, len(allocations[i]), "pod %s", podId)
+		for j, allocation := range usage.Allocations {
+			assert.Equal(t, allocations[i][j], allocation.AllocationId)
+		}
+	}
+
+	// clients keep working without registering again
+	token, err := restarted.GetToken(ctx, &pb.GetTokenRequest{DeviceId: "device", PodId: "a"})
+	assert.Nil(t, err)
+	_, err = restarted.ReturnToken(ctx, &pb.ReturnTokenRequest{DeviceId: "device", PodId: "a", LeaseId: token.LeaseId})
+	assert.Nil(t, err)
+
+	// the restored allocations still count against the limit
+	_, err = restarted.AllocateMemory(ctx, &pb.AllocateMemoryRequest{DeviceId: "device", PodId: "a", MemoryB: podLimit - acknowledged[0] + 1})
+	assert.NotNil(t, err)
+	reply, err := restarted.AllocateMemory(ctx, &pb.AllocateMemoryRequest{DeviceId: "device", PodId: "a", MemoryB: podLimit - acknowledged[0]})
+	assert.Nil(t, err)
+
+	// ids handed out before the restart are not reused
+	for _, ids := range allocations {
+		for _, id := range ids {
+			assert.Less(t, id, reply.AllocationId)
+		}
+	}
+}
+
+func TestDeviceManagerRestoresUnreservedPodsAndDevices(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dm := startDeviceManager(t, dir)
+
+	for _, deviceId := range []string{"kept", "removed"} {
+		_, err := dm.RegisterDevice(ctx, &pb.RegisterDeviceRequest{
+			AllocatorPodId: "allocator-" + deviceId, DeviceId: deviceId, Vendor: "vendor", Model: "model", MemoryB: testDeviceMemoryB,
+		})
+		assert.Nil(t, err)
+
+		for _, podId := range []string{"a", "b"} {
+			_, err := dm.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{DeviceId: deviceId, PodId: podId, Requests: 0.3, Limit: 0.3, Memory: 0.3})
+			assert.Nil(t, err)
+		}
+	}
+
+	dm.lock.Lock()
+	dm.devices["kept"].lock.Lock()
+	dm.unreservePodQuota(dm.devices["kept"], "default/b")
+	dm.devices["kept"].lock.Unlock()
+	dm.deregisterDevice("removed")
+	dm.lock.Unlock()
+	dm.Stop()
+
+	restarted := startDeviceManager(t, dir)
+	defer restarted.Stop()
+
+	assert.Nil(t, restarted.GetDev("removed"))
+	assert.Equal(t, map[string]bool{"default/a": true}, reservedPods(restarted, "kept"))
+
+	_, err := restarted.RegisterDevice(ctx, &pb.RegisterDeviceRequest{
+		AllocatorPodId: "allocator", DeviceId: "kept", Vendor: "vendor", Model: "model", MemoryB: testDeviceMemoryB,
+	})
+	assert.NotNil(t, err)
+}
+
+func TestDeviceManagerRestoresUpdatedQuota(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dm := startDeviceManager(t, dir)
+
+	_, err := dm.RegisterDevice(ctx, &pb.RegisterDeviceRequest{
+		AllocatorPodId: "allocator", DeviceId: "device", Vendor: "vendor", Model: "model", MemoryB: testDeviceMemoryB,
+	})
+	assert.Nil(t, err)
+	_, err = dm.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{DeviceId: "device", PodId: "a", RequestsMilli: 200, LimitMilli: 1000, MemoryB: 2048})
+	assert.Nil(t, err)
+	allocation, err := dm.AllocateMemory(ctx, &pb.AllocateMemoryRequest{DeviceId: "device", PodId: "a", MemoryB: 2048})
+	assert.Nil(t, err)
+
+	// the pod keeps more than its new quota until it frees the allocation
+	_, err = dm.UpdatePodQuota(ctx, &pb.UpdatePodQuotaRequest{DeviceId: "device", PodId: "a", RequestsMilli: 500, LimitMilli: 1000, MemoryB: 1024, AllowMemoryPressure: true})
+	assert.Nil(t, err)
+	dm.Stop()
+
+	restarted := startDeviceManager(t, dir)
+	defer restarted.Stop()
+
+	usage, err := restarted.GetPodUsage(ctx, &pb.GetPodUsageRequest{DeviceId: "device", PodId: "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, 0.5, usage.Requests)
+	assert.Equal(t, uint64(1024), usage.MemoryBLimit)
+	assert.Equal(t, uint64(2048), usage.MemoryBUsed)
+	assert.Len(t, usage.Allocations, 1)
+	assert.Equal(t, allocation.AllocationId, usage.Allocations[0].AllocationId)
+}
+
+func TestDeviceManagerMigratesPodsPersistedByName(t *testing.T) {
+	dir := t.TempDir()
+	st, err := store.Open(store.Config{Dir: dir})
+	assert.Nil(t, err)
+	for _, op := range []store.Op{
+		{Type: store.OpRegisterDevice, DeviceId: "device", AllocatorPodId: "allocator", Vendor: "vendor", Model: "model", MemoryB: testDeviceMemoryB},
+		{Type: store.OpReservePod, DeviceId: "device", PodId: "a", Requests: 0.5, Limit: 1, Memory: 0.5},
+		{Type: store.OpAllocateMemory, DeviceId: "device", PodId: "a", MemoryB: 100},
+	} {
+		assert.Nil(t, st.Append(op))
+	}
+
+	dm := NewDeviceManager(newTestSchedulerFactory(t), nil, nil, st, nil)
+	defer dm.Stop()
+
+	usage, err := dm.GetPodUsage(context.Background(), &pb.GetPodUsageRequest{DeviceId: "device", PodId: "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), usage.MemoryBUsed)
+	// the memory was persisted as a share of the device
+	assert.Equal(t, uint64(testDeviceMemoryB/2), usage.MemoryBLimit)
+	assert.Equal(t, podref.New("default", "allocator", ""), dm.GetDev("device").AllocatorPod)
+
+	// memory allocated before allocations had ids can be freed by a new id
+	assert.Len(t, usage.Allocations, 1)
+	assert.Equal(t, uint64(100), usage.Allocations[0].MemoryB)
+
+	pods := st.State().Devices["device"].Pods
+	assert.Len(t, pods, 1)
+	assert.Equal(t, uint64(100), pods["default/a"].MemoryBUsed)
+	assert.Equal(t, map[uint64]uint64{usage.Allocations[0].AllocationId: 100}, pods["default/a"].Allocations)
+}
@@ -0,0 +1,172 @@
+package devicemanager
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+
+	"github.com/zbsss/device-manager/internal/podref"
+	"github.com/zbsss/device-manager/internal/scheduler"
+	"github.com/zbsss/device-manager/pkg/fastpath"
+)
+
+// ServeFastPath hands the token to clients on the node over the connections of
+// `listener`, see package fastpath. The peers of a Unix socket are checked
+// against `policy` unless it is nil. It returns when the listener is closed.
+func (dm *DeviceManager) ServeFastPath(listener net.Listener, policy *SocketPolicy) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go dm.serveFastPathConn(conn, policy)
+	}
+}
+
+func (dm *DeviceManager) serveFastPathConn(conn net.Conn, policy *SocketPolicy) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	hello, err := fastpath.ReadHello(r)
+	if err != nil {
+		log.Printf("Failed to read fast path hello: %v", err)
+		return
+	}
+
+	if policy != nil {
+		if err := policy.checkFastPath(conn, hello); err != nil {
+			log.Printf("Denied fast path of pod %s on device %s: %v", hello.PodName, hello.DeviceId, err)
+			_ = fastpath.WriteError(conn, err)
+			return
+		}
+	}
+
+	device, pod, err := dm.fastPathPod(hello)
+	if err != nil {
+		_ = fastpath.WriteError(conn, err)
+		return
+	}
+	if err := fastpath.WriteOK(conn); err != nil {
+		return
+	}
+
+	// requests are read ahead, so that a client going away is noticed while it
+	// waits for the token
+	requests := make(chan fastpath.Request)
+	gone := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(gone)
+		for {
+			req, err := fastpath.ReadRequest(r)
+			if err != nil {
+				if err != io.EOF {
+					log.Printf("Fast path of pod %s on device %s broke: %v", pod, device.Id, err)
+				}
+				return
+			}
+
+			select {
+			case requests <- req:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var held *scheduler.TokenLease
+	defer func() {
+		if held != nil {
+			device.sch.AbandonLease(held)
+		}
+	}()
+
+	for {
+		var req fastpath.Request
+		select {
+		case req = <-requests:
+		case <-gone:
+			return
+		}
+
+		switch req.Op {
+		case fastpath.OpAcquire:
+			lease, err := dm.fastPathAcquire(device, pod, gone)
+			if lease == nil && err == nil {
+				return
+			}
+			if err != nil {
+				err = fastpath.WriteError(conn, err)
+			} else {
+				held = lease
+				err = fastpath.WriteGranted(conn, lease.Id, lease.ExpiresAt.Unix())
+			}
+			if err != nil {
+				return
+			}
+		case fastpath.OpRelease:
+			err := device.sch.ReturnLease(&scheduler.TokenLease{Id: req.LeaseId, PodId: pod.Key()})
+			if held != nil && held.Id == req.LeaseId {
+				held = nil
+			}
+			if err != nil {
+				err = fastpath.WriteError(conn, err)
+			} else {
+				err = fastpath.WriteOK(conn)
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (dm *DeviceManager) fastPathPod(hello fastpath.Hello) (*Device, podref.Ref, error) {
+	if hello.DeviceId == "" {
+		return nil, podref.Ref{}, fmt.Errorf("device not specified")
+	}
+	if hello.PodName == "" {
+		return nil, podref.Ref{}, fmt.Errorf("pod not specified")
+	}
+
+	device := dm.GetDev(hello.DeviceId)
+	if device == nil {
+		return nil, podref.Ref{}, fmt.Errorf("device %s not registered", hello.DeviceId)
+	}
+
+	pod := podref.New(hello.PodNamespace, hello.PodName, hello.PodUid)
+	if err := device.checkPod(pod); err != nil {
+		return nil, podref.Ref{}, err
+	}
+	return device, pod, nil
+}
+
+// fastPathAcquire waits for the token, it returns neither a lease nor an error
+// if the client went away first.
+func (dm *DeviceManager) fastPathAcquire(device *Device, pod podref.Ref, gone <-chan struct{}) (*scheduler.TokenLease, error) {
+	req := &scheduler.TokenLeaseRequest{
+		PodId:    pod.Key(),
+		Response: make(chan *scheduler.TokenLease, 1),
+	}
+	if err := device.sch.EnqueueLeaseRequest(req); err != nil {
+		return nil, err
+	}
+
+	select {
+	case lease := <-req.Response:
+		if lease == nil {
+			return nil, fmt.Errorf("token not available")
+		}
+		return lease, nil
+	case <-gone:
+		device.sch.CancelLeaseRequest(req)
+		return nil, nil
+	}
+}
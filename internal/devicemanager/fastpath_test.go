@@ -0,0 +1,179 @@
+package devicemanager
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	pb "github.com/zbsss/device-manager/pkg/devicemanager"
+	"github.com/zbsss/device-manager/pkg/fastpath"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// serveFastPath serves the fast path of dm on a socket in a temporary directory.
+func serveFastPath(t testing.TB, dm *DeviceManager) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "token.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go func() { _ = dm.ServeFastPath(listener, nil) }()
+	t.Cleanup(func() { listener.Close() })
+
+	return path
+}
+
+func TestFastPathHandsOverToken(t *testing.T) {
+	ctx := context.Background()
+	dm := NewDeviceManager(newTestSchedulerFactory(t), nil, nil, nil, nil)
+	defer dm.Stop()
+
+	registerDevice(t, dm, "device", "allocator", "a", "b")
+	path := serveFastPath(t, dm)
+
+	_, err := fastpath.Dial(path, fastpath.Hello{DeviceId: "unknown", PodName: "a"})
+	assert.NotNil(t, err)
+
+	client, err := fastpath.Dial(path, fastpath.Hello{DeviceId: "device", PodName: "a"})
+	assert.Nil(t, err)
+
+	first, _, err := client.Acquire()
+	assert.Nil(t, err)
+	assert.Nil(t, client.Release(first))
+	// a late release does not end the next lease
+	second, expiresAt, err := client.Acquire()
+	assert.Nil(t, err)
+	assert.Greater(t, expiresAt, time.Now().Unix())
+	assert.NotNil(t, client.Release(first))
+	assert.NotNil(t, client.Release(0))
+
+	// the lease of a client which went away ends
+	assert.Nil(t, client.Close())
+	timeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, err = dm.GetToken(timeout, &pb.GetTokenRequest{DeviceId: "device", PodId: "b"})
+	assert.Nil(t, err)
+	assert.NotZero(t, second)
+}
+
+// tokenClient gets and returns the token of a pod once.
+type tokenClient func() error
+
+func unaryTokenClient(client pb.DeviceManagerClient, podId string) tokenClient {
+	ctx := context.Background()
+	return func() error {
+		token, err := client.GetToken(ctx, &pb.GetTokenRequest{DeviceId: "device", PodId: podId})
+		if err != nil {
+			return err
+		}
+		_, err = client.ReturnToken(ctx, &pb.ReturnTokenRequest{DeviceId: "device", PodId: podId, LeaseId: token.LeaseId})
+		return err
+	}
+}
+
+func fastPathTokenClient(client *fastpath.Client) tokenClient {
+	return func() error {
+		leaseId, _, err := client.Acquire()
+		if err != nil {
+			return err
+		}
+		return client.Release(leaseId)
+	}
+}
+
+// BenchmarkTokenHandoff compares getting and returning the token over gRPC on
+// loopback TCP with the fast path. Latency is one pod alone, throughput is
+// four pods handing the token over to each other.
+func BenchmarkTokenHandoff(b *testing.B) {
+	pods := []string{"a", "b", "c", "d"}
+
+	setup := func(b *testing.B) (*DeviceManager, pb.DeviceManagerClient, string) {
+		dm := NewDeviceManager(newTestSchedulerFactory(b), nil, nil, nil, nil)
+		b.Cleanup(dm.Stop)
+		registerDevice(b, dm, "device", "allocator", pods...)
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			b.Fatalf("failed to listen: %v", err)
+		}
+		server := grpc.NewServer()
+		pb.RegisterDeviceManagerServer(server, dm)
+		go func() { _ = server.Serve(listener) }()
+		b.Cleanup(server.Stop)
+
+		conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			b.Fatalf("failed to dial: %v", err)
+		}
+		b.Cleanup(func() { conn.Close() })
+
+		return dm, pb.NewDeviceManagerClient(conn), serveFastPath(b, dm)
+	}
+
+	clients := map[string]func(b *testing.B, client pb.DeviceManagerClient, path, podId string) tokenClient{
+		"unary": func(b *testing.B, client pb.DeviceManagerClient, path, podId string) tokenClient {
+			return unaryTokenClient(client, podId)
+		},
+		"fastpath": func(b *testing.B, client pb.DeviceManagerClient, path, podId string) tokenClient {
+			fp, err := fastpath.Dial(path, fastpath.Hello{DeviceId: "device", PodName: podId})
+			if err != nil {
+				b.Fatalf("failed to dial fast path: %v", err)
+			}
+			b.Cleanup(func() { fp.Close() })
+			return fastPathTokenClient(fp)
+		},
+	}
+
+	for _, name := range []string{"unary", "fastpath"} {
+		newClient := clients[name]
+
+		b.Run(fmt.Sprintf("latency/%s", name), func(b *testing.B) {
+			_, client, path := setup(b)
+			handoff := newClient(b, client, path, pods[0])
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := handoff(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("throughput/%s", name), func(b *testing.B) {
+			_, client, path := setup(b)
+			handoffs := make([]tokenClient, len(pods))
+			for i, podId := range pods {
+				handoffs[i] = newClient(b, client, path, podId)
+			}
+
+			remaining := int64(b.N)
+			wg := &sync.WaitGroup{}
+			start := time.Now()
+			b.ResetTimer()
+			for _, handoff := range handoffs {
+				wg.Add(1)
+				go func(handoff tokenClient) {
+					defer wg.Done()
+					for atomic.AddInt64(&remaining, -1) >= 0 {
+						if err := handoff(); err != nil {
+							b.Error(err)
+							return
+						}
+					}
+				}(handoff)
+			}
+			wg.Wait()
+
+			b.ReportMetric(float64(b.N)/time.Since(start).Seconds(), "grants/s")
+		})
+	}
+}
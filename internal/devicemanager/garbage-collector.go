@@ -4,37 +4,123 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"sync"
 	"time"
 
+	"github.com/zbsss/device-manager/internal/podref"
+	"github.com/zbsss/device-manager/internal/store"
+	"github.com/zbsss/device-manager/pkg/quota"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
 var (
 	DeviceCleanupPeriod = 10 * time.Minute
+	// GCResyncPeriod is how often every reservation is checked against the
+	// running pods, in case an informer event was missed.
+	GCResyncPeriod = 30 * time.Second
 )
 
+const (
+	sharedevLabel = "sharedev"
+	// groupLabel puts a client pod into a tenant group other than its namespace.
+	groupLabel = "sharedev.group"
+)
+
+// startPodInformer watches the sharedev pods, so that the quota of a client
+// pod and the devices of an allocator pod are released as soon as the pod
+// terminates. It returns once the cache is synced.
+func (dm *DeviceManager) startPodInformer() {
+	factory := informers.NewSharedInformerFactoryWithOptions(
+		dm.clientset,
+		dm.resyncPeriod,
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = sharedevLabel
+		}),
+	)
+
+	informer := factory.Core().V1().Pods()
+	informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(_, obj interface{}) {
+			if pod, ok := obj.(*v1.Pod); ok && !isPodActive(pod) {
+				dm.releasePod(pod, string(pod.Status.Phase))
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if pod, ok := obj.(*v1.Pod); ok {
+				dm.releasePod(pod, "deleted")
+			}
+		},
+	})
+	dm.pods = informer.Lister()
+
+	factory.Start(dm.done)
+	factory.WaitForCacheSync(dm.done)
+}
+
 func (dm *DeviceManager) runGarbageCollector() {
+	ticker := time.NewTicker(dm.resyncPeriod)
+	defer ticker.Stop()
+
 	for {
-		wg := &sync.WaitGroup{}
-		wg.Add(2)
+		select {
+		case <-dm.done:
+			return
+		case <-ticker.C:
+			dm.collectGarbage()
+		}
+	}
+}
+
+// releasePod releases what the pod holds, unless it was reserved by another
+// pod with the same name.
+func (dm *DeviceManager) releasePod(pod *v1.Pod, state string) {
+	ref := podRef(pod)
 
-		dm.garbageCollectPodQuotas(wg)
-		dm.garbageCollectDevices(wg)
+	switch ShareDevPodType(pod.Labels[sharedevLabel]) {
+	case PodTypeClient:
+		dm.lock.RLock()
+		defer dm.lock.RUnlock()
 
-		wg.Wait()
+		for _, device := range dm.devices {
+			device.lock.Lock()
+			if reserved, ok := device.Pods[ref.Key()]; ok && reserved.Matches(ref) {
+				log.Printf("[GC] Pod %s is %s, removing it from device %s", ref, state, device.Id)
+				dm.unreservePodQuota(device, ref.Key())
+			}
+			device.lock.Unlock()
+		}
+	case PodTypeAllocator:
+		dm.lock.Lock()
+		defer dm.lock.Unlock()
 
-		time.Sleep(2 * time.Second)
+		for _, device := range dm.devices {
+			if device.AllocatorPod.Matches(ref) {
+				log.Printf("[GC] Allocator %s is %s, removing device %s", ref, state, device.Id)
+				dm.deregisterDevice(device.Id)
+			}
+		}
 	}
 }
 
-func (dm *DeviceManager) garbageCollectDevices(wg *sync.WaitGroup) {
-	defer wg.Done()
+// collectGarbage checks every reservation against the running pods, it is a
+// no-op outside of a cluster.
+func (dm *DeviceManager) collectGarbage() {
+	if dm.pods == nil {
+		return
+	}
+
+	dm.garbageCollectPodQuotas()
+	dm.garbageCollectDevices()
+}
 
-	runningAllocators, err := getRunningPods(PodTypeAllocator)
+func (dm *DeviceManager) garbageCollectDevices() {
+	runningAllocators, err := dm.getRunningPods(PodTypeAllocator)
 	if err != nil {
 		log.Printf("[GC] Error getting running pods: %v", err)
 		return
@@ -44,14 +130,14 @@ func (dm *DeviceManager) garbageCollectDevices(wg *sync.WaitGroup) {
 	defer dm.lock.Unlock()
 
 	for _, device := range dm.devices {
-		if pod, ok := runningAllocators[device.AllocatorPodId]; !ok {
-			log.Printf("[GC] Allocator %s is not running, removing device %s", device.AllocatorPodId, device.Id)
+		if pod, ok := runningAllocators[device.AllocatorPod.Key()]; !ok || !device.AllocatorPod.Matches(podRef(pod)) {
+			log.Printf("[GC] Allocator %s is not running, removing device %s", device.AllocatorPod, device.Id)
 			dm.deregisterDevice(device.Id)
 		} else if len(device.Pods) == 0 && time.Now().After(device.LastUsedAt.Add(DeviceCleanupPeriod)) {
-			log.Printf("[GC] Device %s has not been used for 5 minutes, removing it", device.Id)
-			err := dm.deleteAllocatorDeployment(&pod)
+			log.Printf("[GC] Device %s has not been used for %v, removing it", device.Id, DeviceCleanupPeriod)
+			err := dm.deleteAllocatorDeployment(pod)
 			if err != nil {
-				log.Printf("[GC] Error deleting allocator deployment %s: %v", device.AllocatorPodId, err)
+				log.Printf("[GC] Error deleting allocator deployment %s: %v", device.AllocatorPod, err)
 			}
 		}
 	}
@@ -63,19 +149,7 @@ func (dm *DeviceManager) deleteAllocatorDeployment(allocatorPod *v1.Pod) error {
 		return fmt.Errorf("allocator pod %s does not have a deployment name", allocatorPod.Name)
 	}
 
-	config, err := rest.InClusterConfig()
-	if err != nil {
-		return nil
-	}
-
-	// creates the clientset
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return fmt.Errorf("failed to create client: %v", err)
-	}
-
-	err = clientset.AppsV1().Deployments("default").Delete(context.Background(), deploymentName, metav1.DeleteOptions{})
-	return err
+	return dm.clientset.AppsV1().Deployments(allocatorPod.Namespace).Delete(context.Background(), deploymentName, metav1.DeleteOptions{})
 }
 
 func (dm *DeviceManager) deregisterDevice(deviceId string) {
@@ -85,13 +159,12 @@ func (dm *DeviceManager) deregisterDevice(deviceId string) {
 
 		device.sch.Stop()
 		delete(dm.devices, deviceId)
+		dm.persist(store.Op{Type: store.OpDeregisterDevice, DeviceId: deviceId})
 	}
 }
 
-func (dm *DeviceManager) garbageCollectPodQuotas(wg *sync.WaitGroup) {
-	defer wg.Done()
-
-	runningPods, err := getRunningPods(PodTypeClient)
+func (dm *DeviceManager) garbageCollectPodQuotas() {
+	runningPods, err := dm.getRunningPods(PodTypeClient)
 	if err != nil {
 		log.Printf("[GC] Error getting running pods: %v", err)
 		return
@@ -102,29 +175,26 @@ func (dm *DeviceManager) garbageCollectPodQuotas(wg *sync.WaitGroup) {
 
 	for _, device := range dm.devices {
 		device.lock.Lock()
-		for podId := range device.Pods {
-			if _, ok := runningPods[podId]; !ok {
-				log.Printf("[GC] Pod %s is not running, removing it from device %s", podId, device.Id)
-				dm.unreservePodQuota(device.Id, podId)
+		for podId, reserved := range device.Pods {
+			if pod, ok := runningPods[podId]; !ok || !reserved.Matches(podRef(pod)) {
+				log.Printf("[GC] Pod %s is not running, removing it from device %s", reserved, device.Id)
+				dm.unreservePodQuota(device, podId)
 			}
 		}
 		device.lock.Unlock()
 	}
 }
 
-func (dm *DeviceManager) unreservePodQuota(deviceId, podId string) {
-	device := dm.devices[deviceId]
-	if device == nil {
-		return
-	}
-
-	if !device.Pods[podId] {
+// unreservePodQuota releases the quota of the pod, the caller holds the lock of the device.
+func (dm *DeviceManager) unreservePodQuota(device *Device, podId string) {
+	if _, ok := device.Pods[podId]; !ok {
 		return
 	}
 
 	device.sch.UnreservePodQuota(podId)
 	device.mm.UnreservePodQuota(podId)
 	delete(device.Pods, podId)
+	dm.persist(store.Op{Type: store.OpUnreservePod, DeviceId: device.Id, PodId: podId})
 
 	if len(device.Pods) == 0 {
 		device.LastUsedAt = time.Now()
@@ -138,34 +208,59 @@ const (
 	PodTypeAllocator ShareDevPodType = "allocator"
 )
 
-func getRunningPods(podType ShareDevPodType) (map[string]v1.Pod, error) {
-	// creates the in-cluster config
-	config, err := rest.InClusterConfig()
+// getRunningPods lists the pods of the type from the informer cache by their
+// namespace/name key.
+func (dm *DeviceManager) getRunningPods(podType ShareDevPodType) (map[string]*v1.Pod, error) {
+	pods, err := dm.pods.List(labels.SelectorFromSet(labels.Set{sharedevLabel: string(podType)}))
 	if err != nil {
-		// skip eviction if not running in a k8s cluster
-		return nil, nil
+		return nil, err
 	}
 
-	// creates the clientset
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create client: %v", err)
+	runningPods := make(map[string]*v1.Pod)
+	for _, pod := range pods {
+		if isPodActive(pod) {
+			runningPods[podRef(pod).Key()] = pod
+		}
 	}
+	return runningPods, nil
+}
 
-	// TODO: Refactor all the code to use namespaces for Pods
-	pods, err := clientset.CoreV1().Pods("default").List(
-		context.Background(),
-		metav1.ListOptions{LabelSelector: fmt.Sprintf("sharedev=%s", podType)},
-	)
+// podGroup is the tenant group of the pod from its label, empty if it is not
+// set or the pod is unknown, which groups it by its namespace.
+func (dm *DeviceManager) podGroup(ref podref.Ref) string {
+	if dm.pods == nil {
+		return ""
+	}
+
+	pod, err := dm.pods.Pods(ref.Namespace).Get(ref.Name)
 	if err != nil {
-		return nil, err
+		return ""
 	}
+	return pod.Labels[groupLabel]
+}
 
-	runningPods := make(map[string]v1.Pod)
-	for _, pod := range pods.Items {
-		if pod.Status.Phase == "Running" || pod.Status.Phase == "Pending" {
-			runningPods[pod.Name] = pod
-		}
+// labeledQuota reads the quota of a client pod from its sharedev.* labels,
+// it is empty if the pod is not known.
+func (dm *DeviceManager) labeledQuota(ref podref.Ref) (quota.Quota, error) {
+	if dm.pods == nil {
+		return quota.Quota{}, nil
 	}
-	return runningPods, nil
+
+	pod, err := dm.pods.Pods(ref.Namespace).Get(ref.Name)
+	if err != nil || !ref.Matches(podRef(pod)) {
+		return quota.Quota{}, nil
+	}
+	q, err := quota.FromLabels(pod.Labels)
+	if err != nil {
+		return quota.Quota{}, fmt.Errorf("pod %s: %w", ref, err)
+	}
+	return q, nil
+}
+
+func podRef(pod *v1.Pod) podref.Ref {
+	return podref.New(pod.Namespace, pod.Name, string(pod.UID))
+}
+
+func isPodActive(pod *v1.Pod) bool {
+	return pod.Status.Phase == v1.PodRunning || pod.Status.Phase == v1.PodPending
 }
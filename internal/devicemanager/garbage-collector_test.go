@@ -0,0 +1,220 @@
+package devicemanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zbsss/device-manager/internal/scheduler"
+	"github.com/zbsss/device-manager/internal/store"
+	pb "github.com/zbsss/device-manager/pkg/devicemanager"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newPod(name string, podType ShareDevPodType) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{sharedevLabel: string(podType), "app": name},
+		},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}
+}
+
+func newTestSchedulerFactory(t testing.TB) scheduler.SchedulerFactory {
+	t.Helper()
+
+	sf, err := scheduler.NewSchedulerFactory(scheduler.SchedulerConfig{
+		WindowDuration: time.Minute,
+		EvictionPeriod: time.Minute,
+		Policy:         scheduler.PolicyFairShare,
+	})
+	if err != nil {
+		t.Fatalf("failed to create scheduler factory: %v", err)
+	}
+	return sf
+}
+
+func registerDevice(t testing.TB, dm *DeviceManager, deviceId, allocatorPodId string, pods ...string) {
+	t.Helper()
+
+	ctx := context.Background()
+	_, err := dm.RegisterDevice(ctx, &pb.RegisterDeviceRequest{
+		AllocatorPodId: allocatorPodId, DeviceId: deviceId, Vendor: "vendor", Model: "model", MemoryB: 1024,
+	})
+	assert.Nil(t, err)
+
+	for _, podId := range pods {
+		_, err := dm.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{DeviceId: deviceId, PodId: podId, Requests: 0.1, Limit: 0.1, Memory: 0.1})
+		assert.Nil(t, err)
+	}
+}
+
+// reservedPods returns the keys of the pods reserved on the device, nil if it is not registered.
+func reservedPods(dm *DeviceManager, deviceId string) map[string]bool {
+	device := dm.GetDev(deviceId)
+	if device == nil {
+		return nil
+	}
+
+	device.lock.RLock()
+	defer device.lock.RUnlock()
+
+	pods := map[string]bool{}
+	for podId := range device.Pods {
+		pods[podId] = true
+	}
+	return pods
+}
+
+func TestGarbageCollectorReleasesTerminatedPods(t *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset(
+		newPod("allocator", PodTypeAllocator),
+		newPod("a", PodTypeClient),
+		newPod("b", PodTypeClient),
+		newPod("c", PodTypeClient),
+	)
+	dm := NewDeviceManager(newTestSchedulerFactory(t), clientset, nil, nil, nil)
+	defer dm.Stop()
+
+	registerDevice(t, dm, "device", "allocator", "a", "b", "c")
+
+	succeeded := newPod("a", PodTypeClient)
+	succeeded.Status.Phase = v1.PodSucceeded
+	_, err := clientset.CoreV1().Pods("default").UpdateStatus(ctx, succeeded, metav1.UpdateOptions{})
+	assert.Nil(t, err)
+
+	assert.Eventually(t, func() bool {
+		return len(reservedPods(dm, "device")) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, map[string]bool{"default/b": true, "default/c": true}, reservedPods(dm, "device"))
+
+	err = clientset.CoreV1().Pods("default").Delete(ctx, "b", metav1.DeleteOptions{})
+	assert.Nil(t, err)
+
+	assert.Eventually(t, func() bool {
+		return len(reservedPods(dm, "device")) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, map[string]bool{"default/c": true}, reservedPods(dm, "device"))
+
+	err = clientset.CoreV1().Pods("default").Delete(ctx, "allocator", metav1.DeleteOptions{})
+	assert.Nil(t, err)
+
+	assert.Eventually(t, func() bool {
+		return dm.GetDev("device") == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestGarbageCollectorReconcilesRestoredState(t *testing.T) {
+	dir := t.TempDir()
+	st, err := store.Open(store.Config{Dir: dir})
+	assert.Nil(t, err)
+
+	dm := NewDeviceManager(newTestSchedulerFactory(t), nil, nil, st, nil)
+	registerDevice(t, dm, "device", "allocator", "a", "b")
+	registerDevice(t, dm, "orphan", "stopped-allocator", "a")
+	dm.Stop()
+
+	// pod b and the allocator of the orphan device terminated while the manager was down
+	clientset := fake.NewSimpleClientset(
+		newPod("allocator", PodTypeAllocator),
+		newPod("a", PodTypeClient),
+	)
+	restarted := NewDeviceManager(newTestSchedulerFactory(t), clientset, nil, st, nil)
+	defer restarted.Stop()
+
+	assert.Equal(t, map[string]bool{"default/a": true}, reservedPods(restarted, "device"))
+	assert.Nil(t, restarted.GetDev("orphan"))
+
+	state := st.State()
+	assert.Len(t, state.Devices, 1)
+	assert.Len(t, state.Devices["device"].Pods, 1)
+}
+
+func TestGarbageCollectorResync(t *testing.T) {
+	resyncPeriod := GCResyncPeriod
+	GCResyncPeriod = 10 * time.Millisecond
+	defer func() { GCResyncPeriod = resyncPeriod }()
+
+	clientset := fake.NewSimpleClientset(newPod("allocator", PodTypeAllocator), newPod("a", PodTypeClient))
+	dm := NewDeviceManager(newTestSchedulerFactory(t), clientset, nil, nil, nil)
+	defer dm.Stop()
+
+	// pods which never existed get no events
+	registerDevice(t, dm, "device", "allocator", "a", "unknown")
+
+	assert.Eventually(t, func() bool {
+		return len(reservedPods(dm, "device")) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, map[string]bool{"default/a": true}, reservedPods(dm, "device"))
+}
+
+func TestGarbageCollectorDisabledOutsideOfCluster(t *testing.T) {
+	dm := NewDeviceManager(newTestSchedulerFactory(t), nil, nil, nil, nil)
+	defer dm.Stop()
+
+	registerDevice(t, dm, "device", "allocator", "a")
+	dm.collectGarbage()
+
+	assert.Equal(t, map[string]bool{"default/a": true}, reservedPods(dm, "device"))
+}
+
+func TestGarbageCollectorWorksInAnyNamespace(t *testing.T) {
+	ctx := context.Background()
+	team := newPod("a", PodTypeClient)
+	team.Namespace = "team"
+	team.UID = "team-uid"
+	allocator := newPod("allocator", PodTypeAllocator)
+	allocator.Namespace = "sharedev"
+
+	clientset := fake.NewSimpleClientset(allocator, newPod("a", PodTypeClient), team)
+	dm := NewDeviceManager(newTestSchedulerFactory(t), clientset, nil, nil, nil)
+	defer dm.Stop()
+
+	_, err := dm.RegisterDevice(ctx, &pb.RegisterDeviceRequest{
+		AllocatorPodId: "allocator", AllocatorPodNamespace: "sharedev", DeviceId: "device", Vendor: "vendor", Model: "model", MemoryB: 1024,
+	})
+	assert.Nil(t, err)
+	registerDevice(t, dm, "other", "allocator")
+	for _, pod := range []*v1.Pod{newPod("a", PodTypeClient), team} {
+		_, err := dm.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{
+			DeviceId: "device", PodId: pod.Name, PodNamespace: pod.Namespace, PodUid: string(pod.UID), Requests: 0.1, Limit: 0.1,
+		})
+		assert.Nil(t, err)
+	}
+
+	err = clientset.CoreV1().Pods("team").Delete(ctx, "a", metav1.DeleteOptions{})
+	assert.Nil(t, err)
+
+	assert.Eventually(t, func() bool {
+		return len(reservedPods(dm, "device")) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, map[string]bool{"default/a": true}, reservedPods(dm, "device"))
+
+	// the allocator of the other device is in the default namespace
+	dm.collectGarbage()
+	assert.Nil(t, dm.GetDev("other"))
+	assert.NotNil(t, dm.GetDev("device"))
+}
+
+func TestGarbageCollectorReleasesRecreatedPods(t *testing.T) {
+	ctx := context.Background()
+	recreated := newPod("a", PodTypeClient)
+	recreated.UID = "new-uid"
+
+	clientset := fake.NewSimpleClientset(newPod("allocator", PodTypeAllocator), recreated)
+	dm := NewDeviceManager(newTestSchedulerFactory(t), clientset, nil, nil, nil)
+	defer dm.Stop()
+
+	registerDevice(t, dm, "device", "allocator")
+	_, err := dm.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{DeviceId: "device", PodId: "a", PodUid: "old-uid", Requests: 0.1, Limit: 0.1})
+	assert.Nil(t, err)
+
+	dm.collectGarbage()
+	assert.Empty(t, reservedPods(dm, "device"))
+}
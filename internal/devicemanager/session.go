@@ -0,0 +1,274 @@
+package devicemanager
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/zbsss/device-manager/internal/podref"
+	"github.com/zbsss/device-manager/internal/scheduler"
+	pb "github.com/zbsss/device-manager/pkg/devicemanager"
+)
+
+// session serves the token and the memory of a pod over an OpenSession stream.
+// The stream breaking is the client going away, the lease it holds then ends
+// and the memory it did not free is reported.
+type session struct {
+	dm     *DeviceManager
+	device *Device
+	pod    podref.Ref
+	stream pb.DeviceManager_OpenSessionServer
+	ctx    context.Context
+
+	sendLock *sync.Mutex
+	// acquiring counts the requests waiting for the token.
+	acquiring *sync.WaitGroup
+
+	lock *sync.Mutex
+	// leases are the ids of the leases granted and not released.
+	leases map[uint64]bool
+	// allocations are the sizes of the allocations made and not freed by id.
+	allocations map[uint64]uint64
+}
+
+func (dm *DeviceManager) OpenSession(stream pb.DeviceManager_OpenSessionServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	in := first.GetOpen()
+	if in == nil {
+		return fmt.Errorf("session not opened")
+	}
+	log.Printf("Received: OpenSession for device %s from pod %s", in.DeviceId, in.PodId)
+
+	if in.DeviceId == "" {
+		return fmt.Errorf("device not specified")
+	}
+	if in.PodId == "" {
+		return fmt.Errorf("pod not specified")
+	}
+
+	device := dm.GetDev(in.DeviceId)
+	if device == nil {
+		return fmt.Errorf("device %s not registered", in.DeviceId)
+	}
+
+	pod := podref.New(in.PodNamespace, in.PodId, in.PodUid)
+	if err := device.checkPod(pod); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	s := &session{
+		dm:          dm,
+		device:      device,
+		pod:         pod,
+		stream:      stream,
+		ctx:         ctx,
+		sendLock:    &sync.Mutex{},
+		acquiring:   &sync.WaitGroup{},
+		lock:        &sync.Mutex{},
+		leases:      map[uint64]bool{},
+		allocations: map[uint64]uint64{},
+	}
+	defer func() {
+		cancel()
+		s.close()
+	}()
+
+	// the session watches the lease, so that it is revoked before it is enforced
+	notices, unwatch := device.sch.WatchLease(pod.Key())
+	defer unwatch()
+
+	if err := s.send(&pb.SessionEvent{Seq: first.Seq, Event: &pb.SessionEvent_Opened{Opened: &pb.SessionOpened{}}}); err != nil {
+		return err
+	}
+
+	requests := make(chan *pb.SessionRequest)
+	broken := make(chan error, 1)
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				broken <- err
+				return
+			}
+
+			select {
+			case requests <- req:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case req := <-requests:
+			s.handle(req)
+		case err := <-broken:
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		case notice, ok := <-notices:
+			if !ok {
+				return fmt.Errorf("device %s deregistered", in.DeviceId)
+			}
+			if err := s.send(&pb.SessionEvent{Event: &pb.SessionEvent_Notice{Notice: noticeToPb(notice)}}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *session) handle(req *pb.SessionRequest) {
+	var event *pb.SessionEvent
+	var err error
+
+	switch r := req.Request.(type) {
+	case *pb.SessionRequest_Acquire:
+		// waiting for the token must not hold up the other requests
+		s.acquiring.Add(1)
+		go func() {
+			defer s.acquiring.Done()
+			s.acquire(req.Seq)
+		}()
+		return
+	case *pb.SessionRequest_Release:
+		event, err = s.release(r.Release.LeaseId)
+	case *pb.SessionRequest_Allocate:
+		event, err = s.allocate(r.Allocate.MemoryB)
+	case *pb.SessionRequest_Free:
+		event, err = s.free(r.Free.AllocationId)
+	default:
+		err = fmt.Errorf("unexpected session request %T", req.Request)
+	}
+
+	s.reply(req.Seq, event, err)
+}
+
+func (s *session) acquire(seq uint64) {
+	req := &scheduler.TokenLeaseRequest{
+		PodId:    s.pod.Key(),
+		Response: make(chan *scheduler.TokenLease, 1),
+	}
+	if err := s.device.sch.EnqueueLeaseRequest(req); err != nil {
+		s.reply(seq, nil, err)
+		return
+	}
+
+	var token *scheduler.TokenLease
+	select {
+	case token = <-req.Response:
+	case <-s.ctx.Done():
+		s.device.sch.CancelLeaseRequest(req)
+		return
+	}
+
+	if token == nil {
+		s.reply(seq, nil, fmt.Errorf("token not available"))
+		return
+	}
+
+	s.lock.Lock()
+	s.leases[token.Id] = true
+	s.lock.Unlock()
+
+	s.reply(seq, &pb.SessionEvent{Event: &pb.SessionEvent_Acquired{
+		Acquired: &pb.GetTokenReply{ExpiresAt: token.ExpiresAt.Unix(), LeaseId: token.Id},
+	}}, nil)
+}
+
+func (s *session) release(leaseId uint64) (*pb.SessionEvent, error) {
+	s.lock.Lock()
+	delete(s.leases, leaseId)
+	s.lock.Unlock()
+
+	err := s.device.sch.ReturnLease(&scheduler.TokenLease{Id: leaseId, PodId: s.pod.Key()})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SessionEvent{Event: &pb.SessionEvent_Released{Released: &pb.ReturnTokenReply{}}}, nil
+}
+
+func (s *session) allocate(memoryB uint64) (*pb.SessionEvent, error) {
+	reply, err := s.dm.AllocateMemory(s.ctx, &pb.AllocateMemoryRequest{
+		DeviceId: s.device.Id, PodId: s.pod.Name, PodNamespace: s.pod.Namespace, PodUid: s.pod.UID, MemoryB: memoryB,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	s.allocations[reply.AllocationId] = memoryB
+	s.lock.Unlock()
+
+	return &pb.SessionEvent{Event: &pb.SessionEvent_Allocated{Allocated: reply}}, nil
+}
+
+func (s *session) free(allocationId uint64) (*pb.SessionEvent, error) {
+	reply, err := s.dm.FreeMemory(s.ctx, &pb.FreeMemoryRequest{
+		DeviceId: s.device.Id, PodId: s.pod.Name, PodNamespace: s.pod.Namespace, PodUid: s.pod.UID, AllocationId: allocationId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	delete(s.allocations, allocationId)
+	s.lock.Unlock()
+
+	return &pb.SessionEvent{Event: &pb.SessionEvent_Freed{Freed: reply}}, nil
+}
+
+func (s *session) reply(seq uint64, event *pb.SessionEvent, err error) {
+	if err != nil {
+		event = &pb.SessionEvent{Error: err.Error()}
+	}
+	event.Seq = seq
+
+	if err := s.send(event); err != nil {
+		log.Printf("Failed to reply to pod %s in its session on device %s: %v", s.pod, s.device.Id, err)
+	}
+}
+
+func (s *session) send(event *pb.SessionEvent) error {
+	s.sendLock.Lock()
+	defer s.sendLock.Unlock()
+
+	return s.stream.Send(event)
+}
+
+// close ends the leases the client did not release and reports the memory it
+// did not free. The memory stays allocated, the pod may free it in a new
+// session or release its reservation.
+func (s *session) close() {
+	s.acquiring.Wait()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for leaseId := range s.leases {
+		s.device.sch.AbandonLease(&scheduler.TokenLease{Id: leaseId, PodId: s.pod.Key()})
+	}
+
+	if len(s.allocations) == 0 {
+		log.Printf("Closed session of pod %s on device %s", s.pod, s.device.Id)
+		return
+	}
+
+	ids := make([]uint64, 0, len(s.allocations))
+	var memoryB uint64
+	for id, allocatedB := range s.allocations {
+		ids = append(ids, id)
+		memoryB += allocatedB
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	log.Printf("Closed session of pod %s on device %s with %d B in allocations %v not freed", s.pod, s.device.Id, memoryB, ids)
+}
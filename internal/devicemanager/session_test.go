@@ -0,0 +1,86 @@
+package devicemanager
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	pb "github.com/zbsss/device-manager/pkg/devicemanager"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dialDeviceManager serves dm over an in-memory connection.
+func dialDeviceManager(t testing.TB, dm *DeviceManager) pb.DeviceManagerClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	pb.RegisterDeviceManagerServer(server, dm)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewDeviceManagerClient(conn)
+}
+
+func TestSessionEndsLeaseWhenStreamBreaks(t *testing.T) {
+	ctx := context.Background()
+	dm := NewDeviceManager(newTestSchedulerFactory(t), nil, nil, nil, nil)
+	defer dm.Stop()
+
+	registerDevice(t, dm, "device", "allocator", "a", "b")
+	client := dialDeviceManager(t, dm)
+
+	sessionCtx, closeSession := context.WithCancel(ctx)
+	stream, err := client.OpenSession(sessionCtx)
+	assert.Nil(t, err)
+	call := func(req *pb.SessionRequest) *pb.SessionEvent {
+		t.Helper()
+
+		assert.Nil(t, stream.Send(req))
+		event, err := stream.Recv()
+		assert.Nil(t, err)
+		assert.Equal(t, req.Seq, event.Seq)
+		return event
+	}
+
+	call(&pb.SessionRequest{Seq: 1, Request: &pb.SessionRequest_Open{Open: &pb.SessionOpen{DeviceId: "device", PodId: "a"}}})
+	acquired := call(&pb.SessionRequest{Seq: 2, Request: &pb.SessionRequest_Acquire{Acquire: &pb.SessionAcquire{}}}).GetAcquired()
+	assert.NotZero(t, acquired.LeaseId)
+
+	released := call(&pb.SessionRequest{Seq: 3, Request: &pb.SessionRequest_Release{Release: &pb.SessionRelease{LeaseId: acquired.LeaseId}}})
+	assert.Empty(t, released.Error)
+	// a second release of the same lease fails and the session stays open
+	released = call(&pb.SessionRequest{Seq: 4, Request: &pb.SessionRequest_Release{Release: &pb.SessionRelease{LeaseId: acquired.LeaseId}}})
+	assert.NotEmpty(t, released.Error)
+
+	allocated := call(&pb.SessionRequest{Seq: 5, Request: &pb.SessionRequest_Allocate{Allocate: &pb.SessionAllocate{MemoryB: 10}}}).GetAllocated()
+	assert.NotZero(t, allocated.AllocationId)
+	call(&pb.SessionRequest{Seq: 6, Request: &pb.SessionRequest_Acquire{Acquire: &pb.SessionAcquire{}}})
+
+	// the client crashes while holding the token
+	closeSession()
+
+	timeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, err = dm.GetToken(timeout, &pb.GetTokenRequest{DeviceId: "device", PodId: "b"})
+	assert.Nil(t, err)
+
+	// the memory stays allocated until the pod frees it
+	usage, err := dm.GetPodUsage(ctx, &pb.GetPodUsageRequest{DeviceId: "device", PodId: "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(10), usage.MemoryBUsed)
+	assert.False(t, usage.HoldsToken)
+}
@@ -0,0 +1,250 @@
+package devicemanager
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+
+	"github.com/zbsss/device-manager/internal/peercred"
+	"github.com/zbsss/device-manager/internal/podref"
+	pb "github.com/zbsss/device-manager/pkg/devicemanager"
+	"github.com/zbsss/device-manager/pkg/fastpath"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// SocketPolicy checks the calls made over the Unix sockets mounted into
+// client pods against the pod of the caller, resolved from its peer
+// credentials. A caller in a pod may only act as that pod and read its own
+// history, pods of trusted service accounts are not checked. Calls over TCP
+// are not checked.
+type SocketPolicy struct {
+	dm                     *DeviceManager
+	trustedServiceAccounts map[string]bool
+}
+
+// NewSocketPolicy returns the policy of the Unix sockets of dm, pods running
+// as one of the `trustedServiceAccounts`, given as namespace/name, are not
+// checked.
+func NewSocketPolicy(dm *DeviceManager, trustedServiceAccounts map[string]bool) *SocketPolicy {
+	return &SocketPolicy{dm: dm, trustedServiceAccounts: trustedServiceAccounts}
+}
+
+// trustedMethods register devices and change quotas, they are left to the
+// pods of trusted service accounts, allocator pods may register their own
+// devices.
+var trustedMethods = map[string]bool{
+	"/device_manager.DeviceManager/RegisterDevice":  true,
+	"/device_manager.DeviceManager/ReservePodQuota": true,
+	"/device_manager.DeviceManager/UpdatePodQuota":  true,
+}
+
+// UnaryInterceptor checks the unary calls of peers on the Unix socket.
+func (p *SocketPolicy) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if cred, ok := peercred.FromContext(ctx); ok {
+			if err := p.check(cred, info.FullMethod, req); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor checks every message peers on the Unix socket send on a stream.
+func (p *SocketPolicy) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		cred, ok := peercred.FromContext(stream.Context())
+		if !ok {
+			return handler(srv, stream)
+		}
+		if err := p.check(cred, info.FullMethod, nil); err != nil {
+			return err
+		}
+		return handler(srv, &checkedStream{ServerStream: stream, policy: p, cred: cred, method: info.FullMethod})
+	}
+}
+
+type checkedStream struct {
+	grpc.ServerStream
+	policy *SocketPolicy
+	cred   peercred.Cred
+	method string
+}
+
+func (s *checkedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.policy.check(s.cred, s.method, m)
+}
+
+// check returns a PermissionDenied error if the peer may not make the call, a
+// nil request only checks the method.
+func (p *SocketPolicy) check(cred peercred.Cred, method string, req interface{}) error {
+	if err := p.allowed(cred, method, req); err != nil {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return nil
+}
+
+// checkFastPath checks the peer of a fast path connection opened by `hello`.
+func (p *SocketPolicy) checkFastPath(conn net.Conn, hello fastpath.Hello) error {
+	cred, err := peercred.Of(conn)
+	if err != nil {
+		return fmt.Errorf("failed to read peer credentials: %w", err)
+	}
+	return p.allowed(cred, "fastpath", &pb.GetTokenRequest{
+		DeviceId: hello.DeviceId, PodId: hello.PodName, PodNamespace: hello.PodNamespace, PodUid: hello.PodUid,
+	})
+}
+
+func (p *SocketPolicy) allowed(cred peercred.Cred, method string, req interface{}) error {
+	err := p.checkPod(cred, method, req)
+	if err != nil {
+		log.Printf("Denied %s to %s: %v", method, cred, err)
+	}
+	return err
+}
+
+func (p *SocketPolicy) checkPod(cred peercred.Cred, method string, req interface{}) error {
+	if cred.PodUID == "" {
+		return fmt.Errorf("caller is not a pod")
+	}
+
+	// trust is only given to pods known to the informer, any pod can run as
+	// the user of a trusted one or take the name of a reservation
+	pod, known := p.dm.informerPodByUID(cred.PodUID)
+	if known && p.trustedServiceAccounts[serviceAccount(pod)] {
+		return nil
+	}
+	if trustedMethods[method] {
+		if known && ShareDevPodType(pod.Labels[sharedevLabel]) == PodTypeAllocator {
+			return checkAllocator(pod, method, req)
+		}
+		return fmt.Errorf("only pods of trusted service accounts may call %s", method)
+	}
+
+	caller, ok := p.dm.podByUID(cred.PodUID)
+	if !ok {
+		return fmt.Errorf("pod %s of the caller is not known", cred.PodUID)
+	}
+
+	// the filters of the history and the budgets have to name the caller
+	// exactly, empty ones match every pod
+	var requested podref.Ref
+	switch r := req.(type) {
+	case nil, *pb.GetAvailableDevicesRequest:
+		return nil
+	case *pb.SessionRequest:
+		open := r.GetOpen()
+		if open == nil {
+			// the rest of the session acts as the pod it was opened for
+			return nil
+		}
+		requested = podref.New(open.PodNamespace, open.PodId, open.PodUid)
+	case *pb.GetLeaseHistoryRequest:
+		requested = podref.Ref{Namespace: r.PodNamespace, Name: r.PodId}
+	case *pb.ExportTraceRequest:
+		requested = podref.Ref{Namespace: r.PodNamespace, Name: r.PodId}
+	case *pb.GetNamespaceBudgetsRequest:
+		if r.Namespace != caller.Namespace {
+			return fmt.Errorf("caller can only read the budget of namespace %s", caller.Namespace)
+		}
+		return nil
+	case interface {
+		GetPodNamespace() string
+		GetPodId() string
+		GetPodUid() string
+	}:
+		requested = podref.New(r.GetPodNamespace(), r.GetPodId(), r.GetPodUid())
+	default:
+		return fmt.Errorf("%T not allowed over the socket", req)
+	}
+
+	if requested.Key() != caller.Key() || (requested.UID != "" && requested.UID != cred.PodUID) {
+		return fmt.Errorf("caller can only act as pod %s", caller)
+	}
+	return nil
+}
+
+// checkAllocator lets an allocator pod register the devices it allocates,
+// with itself as their allocator.
+func checkAllocator(pod *v1.Pod, method string, req interface{}) error {
+	r, ok := req.(*pb.RegisterDeviceRequest)
+	if !ok {
+		return fmt.Errorf("allocator pods may not call %s", method)
+	}
+
+	requested := podref.New(r.AllocatorPodNamespace, r.AllocatorPodId, r.AllocatorPodUid)
+	if requested.Key() != podRef(pod).Key() || (requested.UID != "" && requested.UID != string(pod.UID)) {
+		return fmt.Errorf("allocator can only register devices as pod %s", podRef(pod))
+	}
+	return nil
+}
+
+// serviceAccount returns the namespace/name of the service account the pod
+// runs as.
+func serviceAccount(pod *v1.Pod) string {
+	name := pod.Spec.ServiceAccountName
+	if name == "" {
+		name = "default"
+	}
+	return pod.Namespace + "/" + name
+}
+
+// informerPodByUID returns the pod with the UID from the pod informer.
+func (dm *DeviceManager) informerPodByUID(uid string) (*v1.Pod, bool) {
+	if dm.pods == nil {
+		return nil, false
+	}
+
+	pods, err := dm.pods.List(labels.Everything())
+	if err != nil {
+		log.Printf("Failed to list pods: %v", err)
+	}
+	for _, pod := range pods {
+		if string(pod.UID) == uid {
+			return pod, true
+		}
+	}
+	return nil, false
+}
+
+// podByUID returns the pod with the UID from the pod informer, or from the
+// reservations and allocator pods of the devices, which have the UID the
+// scheduler and the allocator reserved them with.
+func (dm *DeviceManager) podByUID(uid string) (podref.Ref, bool) {
+	if pod, ok := dm.informerPodByUID(uid); ok {
+		return podRef(pod), true
+	}
+
+	dm.lock.RLock()
+	defer dm.lock.RUnlock()
+
+	for _, device := range dm.devices {
+		device.lock.RLock()
+		pod, ok := device.podByUIDNoLock(uid)
+		device.lock.RUnlock()
+		if ok {
+			return pod, true
+		}
+	}
+	return podref.Ref{}, false
+}
+
+func (d *Device) podByUIDNoLock(uid string) (podref.Ref, bool) {
+	if d.AllocatorPod.UID == uid {
+		return d.AllocatorPod, true
+	}
+	for _, pod := range d.Pods {
+		if pod.UID == uid {
+			return pod, true
+		}
+	}
+	return podref.Ref{}, false
+}
@@ -0,0 +1,233 @@
+package devicemanager
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zbsss/device-manager/internal/peercred"
+	pb "github.com/zbsss/device-manager/pkg/devicemanager"
+	"github.com/zbsss/device-manager/pkg/fastpath"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	podUidA = "6b3c2b6e-1f0a-4c5e-9d8a-0e4f2a1b3c4d"
+	podUidB = "0d9f1c2e-7a6b-4e3d-8c5b-1a2f3e4d5c6b"
+)
+
+// runAsPod makes the test process look like it runs in the pod `podUid` to
+// the peer credential checks.
+func runAsPod(t *testing.T, podUid string) {
+	t.Helper()
+
+	procRoot := t.TempDir()
+	dir := filepath.Join(procRoot, fmt.Sprint(os.Getpid()))
+	assert.Nil(t, os.MkdirAll(dir, 0o755))
+	cgroup := fmt.Sprintf("0::/kubepods/burstable/pod%s/0123456789abcdef\n", podUid)
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "cgroup"), []byte(cgroup), 0o644))
+
+	previous := peercred.ProcRoot
+	peercred.ProcRoot = procRoot
+	t.Cleanup(func() { peercred.ProcRoot = previous })
+}
+
+// dialSocket serves dm on a Unix socket checked by policy.
+func dialSocket(t *testing.T, dm *DeviceManager, policy *SocketPolicy) pb.DeviceManagerClient {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "device-manager.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := grpc.NewServer(
+		grpc.Creds(peercred.NewServerCredentials()),
+		grpc.UnaryInterceptor(policy.UnaryInterceptor()),
+		grpc.StreamInterceptor(policy.StreamInterceptor()),
+	)
+	pb.RegisterDeviceManagerServer(server, dm)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("unix://"+path, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewDeviceManagerClient(conn)
+}
+
+func TestSocketPolicyLimitsPodsToThemselves(t *testing.T) {
+	ctx := context.Background()
+	dm := NewDeviceManager(newTestSchedulerFactory(t), nil, nil, nil, nil)
+	defer dm.Stop()
+
+	registerDevice(t, dm, "device", "allocator")
+	for pod, uid := range map[string]string{"a": podUidA, "b": podUidB} {
+		_, err := dm.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{DeviceId: "device", PodId: pod, PodUid: uid, Requests: 0.1, Limit: 0.1, Memory: 0.1})
+		assert.Nil(t, err)
+	}
+
+	runAsPod(t, podUidA)
+	client := dialSocket(t, dm, NewSocketPolicy(dm, nil))
+
+	token, err := client.GetToken(ctx, &pb.GetTokenRequest{DeviceId: "device", PodId: "a", PodUid: podUidA})
+	assert.Nil(t, err)
+	_, err = client.ReturnToken(ctx, &pb.ReturnTokenRequest{DeviceId: "device", PodId: "a", PodUid: podUidA, LeaseId: token.LeaseId})
+	assert.Nil(t, err)
+
+	// the token of another pod, named or not
+	_, err = client.GetToken(ctx, &pb.GetTokenRequest{DeviceId: "device", PodId: "b", PodUid: podUidB})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = client.GetToken(ctx, &pb.GetTokenRequest{DeviceId: "device", PodId: "b"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	// another pod with the UID of the caller
+	_, err = client.GetToken(ctx, &pb.GetTokenRequest{DeviceId: "device", PodId: "b", PodUid: podUidA})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = client.ReturnToken(ctx, &pb.ReturnTokenRequest{DeviceId: "device", PodId: "b", PodUid: podUidA, LeaseId: token.LeaseId})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = client.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{DeviceId: "device", PodId: "a", PodUid: podUidA, Requests: 0.5, Limit: 0.5})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = client.RegisterDevice(ctx, &pb.RegisterDeviceRequest{Vendor: "vendor", Model: "model", DeviceId: "device-a", MemoryB: 1, AllocatorPodId: "a", AllocatorPodUid: podUidA})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = client.GetAvailableDevices(ctx, &pb.GetAvailableDevicesRequest{Vendor: "vendor", Model: "model"})
+	assert.Nil(t, err)
+
+	// the history and the budgets of the caller only
+	_, err = client.GetLeaseHistory(ctx, &pb.GetLeaseHistoryRequest{DeviceId: "device"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = client.GetLeaseHistory(ctx, &pb.GetLeaseHistoryRequest{DeviceId: "device", PodNamespace: "default", PodId: "b"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = client.GetLeaseHistory(ctx, &pb.GetLeaseHistoryRequest{DeviceId: "device", PodNamespace: "default", PodId: "a"})
+	assert.NotEqual(t, codes.PermissionDenied, status.Code(err))
+	_, err = client.ExportTrace(ctx, &pb.ExportTraceRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = client.GetNamespaceBudgets(ctx, &pb.GetNamespaceBudgetsRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = client.GetNamespaceBudgets(ctx, &pb.GetNamespaceBudgetsRequest{Namespace: "default"})
+	assert.Nil(t, err)
+
+	stream, err := client.OpenSession(ctx)
+	assert.Nil(t, err)
+	assert.Nil(t, stream.Send(&pb.SessionRequest{Seq: 1, Request: &pb.SessionRequest_Open{Open: &pb.SessionOpen{DeviceId: "device", PodId: "b", PodUid: podUidB}}}))
+	_, err = stream.Recv()
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestSocketPolicyTrustsPodsByIdentity(t *testing.T) {
+	ctx := context.Background()
+	allocator := newPod("allocator", PodTypeAllocator)
+	allocator.UID = podUidA
+	trusted := newPod("scheduler", PodTypeClient)
+	trusted.UID = podUidB
+	trusted.Spec.ServiceAccountName = "scheduler"
+	clientset := fake.NewSimpleClientset(allocator, trusted)
+	dm := NewDeviceManager(newTestSchedulerFactory(t), clientset, nil, nil, nil)
+	defer dm.Stop()
+	policy := NewSocketPolicy(dm, map[string]bool{"default/scheduler": true})
+
+	// allocators only register devices of their own
+	runAsPod(t, podUidA)
+	client := dialSocket(t, dm, policy)
+	_, err := client.RegisterDevice(ctx, &pb.RegisterDeviceRequest{Vendor: "vendor", Model: "model", DeviceId: "device", MemoryB: 1024, AllocatorPodId: "allocator", AllocatorPodUid: podUidA})
+	assert.Nil(t, err)
+	_, err = client.RegisterDevice(ctx, &pb.RegisterDeviceRequest{Vendor: "vendor", Model: "model", DeviceId: "device-b", MemoryB: 1024, AllocatorPodId: "b"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = client.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{DeviceId: "device", PodId: "a", Requests: 0.1, Limit: 0.1, Memory: 0.1})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// pods of trusted service accounts act as any pod
+	runAsPod(t, podUidB)
+	client = dialSocket(t, dm, policy)
+	_, err = client.ReservePodQuota(ctx, &pb.ReservePodQuotaRequest{DeviceId: "device", PodId: "a", Requests: 0.1, Limit: 0.1, Memory: 0.1})
+	assert.Nil(t, err)
+	_, err = client.TryGetToken(ctx, &pb.GetTokenRequest{DeviceId: "device", PodId: "a"})
+	assert.Nil(t, err)
+
+	// the same pod without the service account is a client
+	trusted.Spec.ServiceAccountName = ""
+	_, err = clientset.CoreV1().Pods("default").Update(ctx, trusted, metav1.UpdateOptions{})
+	assert.Nil(t, err)
+	assert.Eventually(t, func() bool {
+		_, err := client.UpdatePodQuota(ctx, &pb.UpdatePodQuotaRequest{DeviceId: "device", PodId: "a", RequestsMilli: 100})
+		return status.Code(err) == codes.PermissionDenied
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSocketPolicyRejectsUnknownPods(t *testing.T) {
+	ctx := context.Background()
+	dm := NewDeviceManager(newTestSchedulerFactory(t), nil, nil, nil, nil)
+	defer dm.Stop()
+
+	// reserved without a UID, the caller can not be told apart from other pods
+	registerDevice(t, dm, "device", "allocator", "a")
+	runAsPod(t, podUidA)
+	client := dialSocket(t, dm, NewSocketPolicy(dm, nil))
+
+	_, err := client.GetToken(ctx, &pb.GetTokenRequest{DeviceId: "device", PodId: "a", PodUid: podUidA})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = client.GetAvailableDevices(ctx, &pb.GetAvailableDevicesRequest{Vendor: "vendor", Model: "model"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestSocketPolicyResolvesPodsFromInformer(t *testing.T) {
+	ctx := context.Background()
+	pod := newPod("a", PodTypeClient)
+	pod.UID = podUidA
+	clientset := fake.NewSimpleClientset(newPod("allocator", PodTypeAllocator), pod)
+	dm := NewDeviceManager(newTestSchedulerFactory(t), clientset, nil, nil, nil)
+	defer dm.Stop()
+
+	registerDevice(t, dm, "device", "allocator", "a", "b")
+	runAsPod(t, podUidA)
+	client := dialSocket(t, dm, NewSocketPolicy(dm, nil))
+
+	_, err := client.TryGetToken(ctx, &pb.GetTokenRequest{DeviceId: "device", PodId: "a", PodUid: podUidA})
+	assert.Nil(t, err)
+	_, err = client.TryGetToken(ctx, &pb.GetTokenRequest{DeviceId: "device", PodId: "b", PodUid: podUidA})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestSocketPolicyChecksFastPath(t *testing.T) {
+	dm := NewDeviceManager(newTestSchedulerFactory(t), nil, nil, nil, nil)
+	defer dm.Stop()
+
+	registerDevice(t, dm, "device", "allocator")
+	for pod, uid := range map[string]string{"a": podUidA, "b": podUidB} {
+		_, err := dm.ReservePodQuota(context.Background(), &pb.ReservePodQuotaRequest{DeviceId: "device", PodId: pod, PodUid: uid, Requests: 0.1, Limit: 0.1, Memory: 0.1})
+		assert.Nil(t, err)
+	}
+	runAsPod(t, podUidA)
+
+	path := filepath.Join(t.TempDir(), "token.sock")
+	listener, err := net.Listen("unix", path)
+	assert.Nil(t, err)
+	go func() { _ = dm.ServeFastPath(listener, NewSocketPolicy(dm, nil)) }()
+	defer listener.Close()
+
+	_, err = fastpath.Dial(path, fastpath.Hello{DeviceId: "device", PodName: "b", PodUid: podUidB})
+	assert.NotNil(t, err)
+	_, err = fastpath.Dial(path, fastpath.Hello{DeviceId: "device", PodName: "b", PodUid: podUidA})
+	assert.NotNil(t, err)
+
+	client, err := fastpath.Dial(path, fastpath.Hello{DeviceId: "device", PodName: "a", PodUid: podUidA})
+	assert.Nil(t, err)
+	defer client.Close()
+	_, _, err = client.Acquire()
+	assert.Nil(t, err)
+}
@@ -1,10 +1,12 @@
 package devicemanager
 
 import (
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/zbsss/device-manager/internal/memorymanager"
+	"github.com/zbsss/device-manager/internal/podref"
 	"github.com/zbsss/device-manager/internal/scheduler"
 )
 
@@ -13,10 +15,24 @@ type Device struct {
 	mm   memorymanager.MemoryManager
 	sch  scheduler.Scheduler
 
-	Id             string
-	AllocatorPodId string
-	Vendor         string
-	Model          string
-	Pods           map[string]bool
-	LastUsedAt     time.Time
+	Id           string
+	AllocatorPod podref.Ref
+	Vendor       string
+	Model        string
+	MemoryB      uint64
+	// Pods are the pods with reserved quota by their namespace/name key.
+	Pods       map[string]podref.Ref
+	LastUsedAt time.Time
+}
+
+// checkPod rejects a pod which was recreated after the quota was reserved,
+// pods without reserved quota are left to the scheduler and memory manager.
+func (d *Device) checkPod(pod podref.Ref) error {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	if reserved, ok := d.Pods[pod.Key()]; ok && !reserved.Matches(pod) {
+		return fmt.Errorf("pod %s was recreated, its quota has to be reserved again", pod)
+	}
+	return nil
 }
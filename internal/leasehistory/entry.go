@@ -0,0 +1,45 @@
+package leasehistory
+
+import "time"
+
+// Entry is a single lease of a device token, written as one JSON line.
+type Entry struct {
+	DeviceId string `json:"deviceId"`
+	// PodId is the namespace/name key of the pod, older entries hold the bare name.
+	PodId      string    `json:"podId"`
+	LeasedAt   time.Time `json:"leasedAt"`
+	ReturnedAt time.Time `json:"returnedAt"`
+	// EnqueuedAt is when the pod asked for the token, zero in entries written
+	// by older versions.
+	EnqueuedAt time.Time `json:"enqueuedAt"`
+	// Requests and Limit are the quota of the pod at the time of the lease.
+	Requests float64 `json:"requests,omitempty"`
+	Limit    float64 `json:"limit,omitempty"`
+	// EndReason is one of the End* constants, empty in entries written by
+	// older versions.
+	EndReason string `json:"endReason,omitempty"`
+}
+
+// Reasons for a lease to end.
+const (
+	// EndReturned means the pod returned the token.
+	EndReturned = "returned"
+	// EndYielded means the pod returned the token after it was asked to.
+	EndYielded = "yielded"
+	EndEvicted = "evicted"
+	EndDeleted = "deleted"
+	// EndRevoked means the token was taken away from the pod.
+	EndRevoked = "revoked"
+	// EndAbandoned means the client of the pod gave up on the token before it
+	// was granted or went away while holding it.
+	EndAbandoned = "abandoned"
+	// EndUnreserved means the quota of the pod was released while it held the
+	// token.
+	EndUnreserved = "unreserved"
+)
+
+// Sink stores batches of lease history entries.
+type Sink interface {
+	Write(entries []Entry) error
+	Close() error
+}
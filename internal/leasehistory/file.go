@@ -0,0 +1,218 @@
+package leasehistory
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	currentFileName = "lease-history.jsonl"
+	rotatedPrefix   = "lease-history-"
+	rotatedTime     = "2006-01-02-15-04-05.000000000"
+)
+
+type FileSinkConfig struct {
+	Dir string
+	// MaxBytes is the size after which the current file is rotated.
+	MaxBytes int64
+	// MaxFiles is the number of rotated files kept, zero keeps all of them.
+	MaxFiles int
+	// Compress gzips the files when they are rotated.
+	Compress bool
+}
+
+// FileSink writes entries as JSON lines to a file in Dir, rotating it once it
+// grows above MaxBytes.
+type FileSink struct {
+	config FileSinkConfig
+	file   *os.File
+	size   int64
+}
+
+func NewFileSink(config FileSinkConfig) (*FileSink, error) {
+	err := os.MkdirAll(config.Dir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create history dir: %w", err)
+	}
+
+	sink := &FileSink{config: config}
+	err = sink.open()
+	if err != nil {
+		return nil, err
+	}
+
+	return sink, nil
+}
+
+func (f *FileSink) open() error {
+	file, err := os.OpenFile(filepath.Join(f.config.Dir, currentFileName), os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat history file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+func (f *FileSink) Write(entries []Entry) error {
+	w := bufio.NewWriter(f.file)
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal entry: %w", err)
+		}
+
+		n, err := w.Write(append(data, '\n'))
+		f.size += int64(n)
+		if err != nil {
+			return fmt.Errorf("failed to write entry: %w", err)
+		}
+	}
+
+	err := w.Flush()
+	if err != nil {
+		return fmt.Errorf("failed to write entries: %w", err)
+	}
+
+	if f.config.MaxBytes > 0 && f.size >= f.config.MaxBytes {
+		return f.rotate()
+	}
+
+	return nil
+}
+
+func (f *FileSink) Close() error {
+	return f.file.Close()
+}
+
+func (f *FileSink) rotate() error {
+	err := f.file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close history file: %w", err)
+	}
+
+	rotated := filepath.Join(f.config.Dir, rotatedPrefix+time.Now().UTC().Format(rotatedTime)+".jsonl")
+	err = os.Rename(filepath.Join(f.config.Dir, currentFileName), rotated)
+	if err != nil {
+		return fmt.Errorf("failed to rotate history file: %w", err)
+	}
+
+	err = f.open()
+	if err != nil {
+		return err
+	}
+
+	if f.config.Compress {
+		err = compressFile(rotated)
+		if err != nil {
+			log.Printf("Failed to compress %s: %v", rotated, err)
+		}
+	}
+
+	return f.prune()
+}
+
+// prune removes the oldest rotated files above MaxFiles.
+func (f *FileSink) prune() error {
+	if f.config.MaxFiles <= 0 {
+		return nil
+	}
+
+	rotated, err := filepath.Glob(filepath.Join(f.config.Dir, rotatedPrefix+"*"))
+	if err != nil {
+		return err
+	}
+
+	// timestamps in the names sort chronologically
+	sort.Strings(rotated)
+	for len(rotated) > f.config.MaxFiles {
+		err = os.Remove(rotated[0])
+		if err != nil {
+			return fmt.Errorf("failed to remove old history file: %w", err)
+		}
+		rotated = rotated[1:]
+	}
+
+	return nil
+}
+
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	_, err = io.Copy(gz, src)
+	if err == nil {
+		err = gz.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst.Name())
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// ReadFile reads the entries of a history file, gzipped if its name ends with .gz.
+func ReadFile(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var entry Entry
+		err = json.Unmarshal([]byte(line), &entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
@@ -0,0 +1,58 @@
+package leasehistory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSinkAppendsJSONLines(t *testing.T) {
+	dir := t.TempDir()
+	entries := testEntries(4)
+
+	sink, err := NewFileSink(FileSinkConfig{Dir: filepath.Join(dir, "history")})
+	assert.Nil(t, err)
+	assert.Nil(t, sink.Write(entries[:2]))
+	assert.Nil(t, sink.Close())
+
+	// entries written before a restart are kept
+	sink, err = NewFileSink(FileSinkConfig{Dir: filepath.Join(dir, "history")})
+	assert.Nil(t, err)
+	assert.Nil(t, sink.Write(entries[2:]))
+	assert.Nil(t, sink.Close())
+
+	read, err := ReadFile(filepath.Join(dir, "history", currentFileName))
+	assert.Nil(t, err)
+	assert.Equal(t, entries, read)
+}
+
+func TestFileSinkRotatesAndCompresses(t *testing.T) {
+	dir := t.TempDir()
+	entries := testEntries(10)
+
+	sink, err := NewFileSink(FileSinkConfig{Dir: dir, MaxBytes: 1, MaxFiles: 3, Compress: true})
+	assert.Nil(t, err)
+	for i := range entries {
+		assert.Nil(t, sink.Write(entries[i:i+1]))
+	}
+	assert.Nil(t, sink.Close())
+
+	rotated, err := filepath.Glob(filepath.Join(dir, rotatedPrefix+"*.jsonl.gz"))
+	assert.Nil(t, err)
+	assert.Len(t, rotated, 3)
+
+	uncompressed, err := filepath.Glob(filepath.Join(dir, rotatedPrefix+"*.jsonl"))
+	assert.Nil(t, err)
+	assert.Empty(t, uncompressed)
+
+	// the newest rotated file holds the last entry
+	read, err := ReadFile(rotated[len(rotated)-1])
+	assert.Nil(t, err)
+	assert.Equal(t, entries[9:], read)
+
+	info, err := os.Stat(filepath.Join(dir, currentFileName))
+	assert.Nil(t, err)
+	assert.Zero(t, info.Size())
+}
@@ -0,0 +1,119 @@
+package leasehistory
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	recorderBufferSize = 4096
+	recorderBatchSize  = 256
+	// FlushInterval is the longest an entry waits in the recorder before it is written.
+	FlushInterval = time.Second
+)
+
+// Recorder writes entries to its sinks asynchronously and in batches, so that
+// recording never blocks the scheduler. Entries recorded while the buffer is
+// full are dropped and counted, entries recorded after Close are ignored.
+type Recorder struct {
+	lock    sync.RWMutex
+	closed  bool
+	entries chan Entry
+	done    chan struct{}
+	sinks   []Sink
+	dropped atomic.Uint64
+}
+
+func NewRecorder(sinks ...Sink) *Recorder {
+	r := &Recorder{
+		entries: make(chan Entry, recorderBufferSize),
+		done:    make(chan struct{}),
+		sinks:   sinks,
+	}
+
+	go r.run()
+	return r
+}
+
+func (r *Recorder) Record(entry Entry) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if r.closed {
+		return
+	}
+	select {
+	case r.entries <- entry:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// Dropped returns the number of entries lost because the buffer was full.
+func (r *Recorder) Dropped() uint64 {
+	return r.dropped.Load()
+}
+
+// Close writes the buffered entries and closes the sinks. Entries recorded
+// afterwards are ignored.
+func (r *Recorder) Close() {
+	r.lock.Lock()
+	if r.closed {
+		r.lock.Unlock()
+		return
+	}
+	r.closed = true
+	close(r.entries)
+	r.lock.Unlock()
+
+	<-r.done
+
+	for _, sink := range r.sinks {
+		err := sink.Close()
+		if err != nil {
+			log.Printf("Failed to close lease history sink: %v", err)
+		}
+	}
+}
+
+func (r *Recorder) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Entry, 0, recorderBatchSize)
+	for {
+		select {
+		case entry, ok := <-r.entries:
+			if !ok {
+				r.flush(batch)
+				return
+			}
+
+			batch = append(batch, entry)
+			if len(batch) >= recorderBatchSize {
+				r.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			r.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (r *Recorder) flush(batch []Entry) {
+	if len(batch) == 0 {
+		return
+	}
+
+	for _, sink := range r.sinks {
+		err := sink.Write(batch)
+		if err != nil {
+			log.Printf("Failed to write lease history: %v", err)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package leasehistory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorderWritesToAllSinksOnClose(t *testing.T) {
+	ring := NewRingBuffer(10)
+	other := NewRingBuffer(10)
+	entries := testEntries(5)
+
+	recorder := NewRecorder(ring, other)
+	for _, entry := range entries {
+		recorder.Record(entry)
+	}
+	recorder.Close()
+
+	assert.Equal(t, entries, ring.Query(Filter{}))
+	assert.Equal(t, entries, other.Query(Filter{}))
+	assert.Zero(t, recorder.Dropped())
+}
+
+func TestRecorderIgnoresEntriesAfterClose(t *testing.T) {
+	ring := NewRingBuffer(10)
+	entries := testEntries(2)
+
+	recorder := NewRecorder(ring)
+	recorder.Record(entries[0])
+	recorder.Close()
+	recorder.Record(entries[1])
+	recorder.Close()
+
+	assert.Equal(t, entries[:1], ring.Query(Filter{}))
+}
+
+type blockingSink struct {
+	release chan struct{}
+}
+
+func (s *blockingSink) Write(entries []Entry) error {
+	<-s.release
+	return nil
+}
+
+func (s *blockingSink) Close() error {
+	return nil
+}
+
+func TestRecorderDropsEntriesWhenFull(t *testing.T) {
+	sink := &blockingSink{release: make(chan struct{})}
+	recorder := NewRecorder(sink)
+
+	entries := testEntries(1)
+	for i := 0; i < recorderBufferSize+2*recorderBatchSize; i++ {
+		recorder.Record(entries[0])
+	}
+
+	assert.NotZero(t, recorder.Dropped())
+
+	close(sink.release)
+	recorder.Close()
+}
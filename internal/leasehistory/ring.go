@@ -0,0 +1,87 @@
+package leasehistory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zbsss/device-manager/internal/podref"
+)
+
+// Filter selects entries, zero fields match everything. Since and Until are
+// compared with the moment the lease was returned.
+type Filter struct {
+	DeviceId     string
+	PodNamespace string
+	PodName      string
+	Since        time.Time
+	Until        time.Time
+}
+
+func (f *Filter) matches(entry *Entry) bool {
+	pod := podref.ParseKey(entry.PodId)
+	return (f.DeviceId == "" || f.DeviceId == entry.DeviceId) &&
+		(f.PodNamespace == "" || f.PodNamespace == pod.Namespace) &&
+		(f.PodName == "" || f.PodName == pod.Name) &&
+		(f.Since.IsZero() || !entry.ReturnedAt.Before(f.Since)) &&
+		(f.Until.IsZero() || entry.ReturnedAt.Before(f.Until))
+}
+
+// RingBuffer keeps the most recent entries in memory, so that they can be
+// queried without reading the files.
+type RingBuffer struct {
+	lock    sync.RWMutex
+	entries []Entry
+	next    int
+	full    bool
+}
+
+func NewRingBuffer(size int) *RingBuffer {
+	return &RingBuffer{
+		entries: make([]Entry, size),
+	}
+}
+
+func (r *RingBuffer) Write(entries []Entry) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if len(r.entries) == 0 {
+		return nil
+	}
+
+	for _, entry := range entries {
+		r.entries[r.next] = entry
+		r.next = (r.next + 1) % len(r.entries)
+		if r.next == 0 {
+			r.full = true
+		}
+	}
+
+	return nil
+}
+
+func (r *RingBuffer) Close() error {
+	return nil
+}
+
+// Query returns the entries matching the filter, oldest first.
+func (r *RingBuffer) Query(filter Filter) []Entry {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	result := []Entry{}
+
+	start, count := 0, r.next
+	if r.full {
+		start, count = r.next, len(r.entries)
+	}
+
+	for i := 0; i < count; i++ {
+		entry := &r.entries[(start+i)%len(r.entries)]
+		if filter.matches(entry) {
+			result = append(result, *entry)
+		}
+	}
+
+	return result
+}
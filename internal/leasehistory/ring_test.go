@@ -0,0 +1,53 @@
+package leasehistory
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testEntries(n int) []Entry {
+	start := time.Date(2023, 7, 13, 9, 0, 0, 0, time.UTC)
+	entries := []Entry{}
+	for i := 0; i < n; i++ {
+		entries = append(entries, Entry{
+			DeviceId:   fmt.Sprintf("device%d", i%2),
+			PodId:      fmt.Sprintf("team%d/pod%d", i%2, i%3),
+			LeasedAt:   start.Add(time.Duration(i) * time.Second),
+			ReturnedAt: start.Add(time.Duration(i)*time.Second + 500*time.Millisecond),
+		})
+	}
+	return entries
+}
+
+func TestRingBufferKeepsMostRecentEntries(t *testing.T) {
+	ring := NewRingBuffer(4)
+	entries := testEntries(6)
+
+	assert.Nil(t, ring.Write(entries[:3]))
+	assert.Equal(t, entries[:3], ring.Query(Filter{}))
+
+	assert.Nil(t, ring.Write(entries[3:]))
+	assert.Equal(t, entries[2:], ring.Query(Filter{}))
+}
+
+func TestRingBufferQuery(t *testing.T) {
+	ring := NewRingBuffer(10)
+	entries := testEntries(6)
+	assert.Nil(t, ring.Write(entries))
+
+	assert.Equal(t, []Entry{entries[0], entries[2], entries[4]}, ring.Query(Filter{DeviceId: "device0"}))
+	assert.Equal(t, []Entry{entries[1], entries[4]}, ring.Query(Filter{PodName: "pod1"}))
+	assert.Equal(t, []Entry{entries[4]}, ring.Query(Filter{PodNamespace: "team0", PodName: "pod1"}))
+	assert.Equal(t, []Entry{entries[2], entries[3]}, ring.Query(Filter{
+		Since: entries[2].ReturnedAt,
+		Until: entries[4].ReturnedAt,
+	}))
+}
+
+func TestRingBufferEmpty(t *testing.T) {
+	assert.Nil(t, NewRingBuffer(0).Write(testEntries(2)))
+	assert.Empty(t, NewRingBuffer(0).Query(Filter{}))
+}
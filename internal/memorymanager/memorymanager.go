@@ -2,23 +2,69 @@ package memorymanager
 
 import (
 	"fmt"
+	"log"
+	"sort"
 	"strings"
 	"sync"
+
+	"github.com/zbsss/device-manager/internal/admission"
+	"github.com/zbsss/device-manager/internal/podref"
+	"github.com/zbsss/device-manager/pkg/quota"
 )
 
 type PodMemory struct {
-	Id           string
-	MemoryQuota  float64
+	// Id is the namespace/name key of the pod.
+	Id  string
+	UID string
+	// MemoryBLimit is the reserved memory of the pod.
 	MemoryBLimit uint64
 	MemoryBUsed  uint64
+	// Allocations are the outstanding allocations of the pod ordered by id.
+	Allocations []Allocation
+}
+
+type Allocation struct {
+	Id      uint64
+	MemoryB uint64
+}
+
+type allocation struct {
+	podId   string
+	memoryB uint64
 }
 
 type MemoryManager interface {
-	AllocateMemory(podId string, memoryB uint64) error
-	FreeMemory(podId string, memoryB uint64)
+	// AllocateMemory allocates memoryB bytes within the reservation of the pod
+	// and returns the id of the allocation.
+	AllocateMemory(podId string, memoryB uint64) (uint64, error)
+	// FreeMemory frees an allocation of the pod and returns its size, it fails
+	// if the allocation was freed already or was never made.
+	FreeMemory(podId string, allocationId uint64) (uint64, error)
+	// RestoreAllocation makes an allocation with the id it had before a restart,
+	// it may exceed the reservation of a pod whose quota was reduced.
+	RestoreAllocation(podId string, allocationId, memoryB uint64) error
+	// SkipAllocationIds keeps ids up to lastAllocationId, which were handed out
+	// before a restart, from being reused.
+	SkipAllocationIds(lastAllocationId uint64)
+	// GetLeaks counts the allocations which were never freed, because the pod
+	// they belong to was unreserved first.
+	GetLeaks() (allocations int, memoryB uint64)
 
-	GetAvailableQuota() float64
-	ReservePodQuota(podId string, memoryQuota float64) error
+	// GetAvailableQuota is the share of memory which is not reserved, negative
+	// if the device is overcommitted.
+	GetAvailableQuota() quota.MilliShares
+	// GetAvailableMemoryB is the memory which is not reserved.
+	GetAvailableMemoryB() uint64
+	// GetNamespaceMemoryB is the memory reserved by the pods in the namespace.
+	GetNamespaceMemoryB(namespace string) uint64
+	GetPodMemory(podId string) (PodMemory, error)
+	// ReservePodQuota reserves memoryB bytes for the pod if the admission
+	// policy of the device admits them. Reserving again replaces the
+	// reservation and keeps the allocations, it fails below them.
+	ReservePodQuota(pod podref.Ref, memoryB uint64) (admission.Result, error)
+	// ResizePodQuota changes the reserved memory of the pod and keeps its
+	// allocations, which may then exceed the new reservation.
+	ResizePodQuota(podId string, memoryB uint64) (admission.Result, error)
 	UnreservePodQuota(podId string)
 
 	PrintState() string
@@ -30,92 +76,265 @@ type memoryManager struct {
 	MemoryBTotal uint64
 	MemoryBUsed  uint64
 	PodsMem      map[string]*PodMemory
+	admission    admission.Policy
+
+	allocations      map[uint64]*allocation
+	lastAllocationId uint64
+	leakedCount      int
+	leakedB          uint64
 }
 
-func NewMemoryManager(deviceId string, memoryBTotal uint64) MemoryManager {
+// NewMemoryManager creates the memory manager of a device, the sum of memory
+// quotas is admitted by the policy. Allocations never exceed the memory of the
+// device, whatever the policy.
+func NewMemoryManager(deviceId string, memoryBTotal uint64, policy admission.Policy) MemoryManager {
 	return &memoryManager{
 		lock:         &sync.RWMutex{},
 		DeviceId:     deviceId,
 		MemoryBTotal: memoryBTotal,
 		MemoryBUsed:  0,
 		PodsMem:      map[string]*PodMemory{},
+		admission:    policy,
+		allocations:  map[uint64]*allocation{},
 	}
 }
 
-func (mm *memoryManager) GetAvailableQuota() float64 {
+func (mm *memoryManager) GetAvailableQuota() quota.MilliShares {
+	mm.lock.RLock()
+	defer mm.lock.RUnlock()
+
+	return quota.Device - quota.ShareOf(mm.reservedNoLock(""), mm.MemoryBTotal)
+}
+
+func (mm *memoryManager) GetAvailableMemoryB() uint64 {
 	mm.lock.RLock()
 	defer mm.lock.RUnlock()
 
-	availableQuota := 1.0
-	for _, podMem := range mm.PodsMem {
-		availableQuota -= podMem.MemoryQuota
+	reserved := mm.reservedNoLock("")
+	if reserved > mm.MemoryBTotal {
+		return 0
 	}
-	return availableQuota
+	return mm.MemoryBTotal - reserved
 }
 
-func (mm *memoryManager) ReservePodQuota(podId string, memoryQuota float64) error {
-	availableQuota := mm.GetAvailableQuota()
+func (mm *memoryManager) GetNamespaceMemoryB(namespace string) uint64 {
+	mm.lock.RLock()
+	defer mm.lock.RUnlock()
 
-	if memoryQuota > availableQuota {
-		return fmt.Errorf("OOM: memory limit exceeded")
+	var reserved uint64
+	for podId, podMem := range mm.PodsMem {
+		if podref.ParseKey(podId).Namespace == namespace {
+			reserved += podMem.MemoryBLimit
+		}
+	}
+	return reserved
+}
+
+// reservedNoLock sums the memory reserved by all pods except `exceptPodId`.
+func (mm *memoryManager) reservedNoLock(exceptPodId string) uint64 {
+	var reserved uint64
+	for podId, podMem := range mm.PodsMem {
+		if podId != exceptPodId {
+			reserved += podMem.MemoryBLimit
+		}
 	}
+	return reserved
+}
+
+func (mm *memoryManager) GetPodMemory(podId string) (PodMemory, error) {
+	mm.lock.RLock()
+	defer mm.lock.RUnlock()
 
+	pod := mm.PodsMem[podId]
+	if pod == nil {
+		return PodMemory{}, fmt.Errorf("pod %s not registered", podId)
+	}
+
+	podMem := *pod
+	podMem.Allocations = []Allocation{}
+	for id, a := range mm.allocations {
+		if a.podId == podId {
+			podMem.Allocations = append(podMem.Allocations, Allocation{Id: id, MemoryB: a.memoryB})
+		}
+	}
+	sort.Slice(podMem.Allocations, func(i, j int) bool {
+		return podMem.Allocations[i].Id < podMem.Allocations[j].Id
+	})
+	return podMem, nil
+}
+
+func (mm *memoryManager) ReservePodQuota(pod podref.Ref, memoryB uint64) (admission.Result, error) {
 	mm.lock.Lock()
 	defer mm.lock.Unlock()
 
-	mm.PodsMem[podId] = &PodMemory{
-		Id:           podId,
-		MemoryQuota:  memoryQuota,
-		MemoryBLimit: uint64(memoryQuota * float64(mm.MemoryBTotal)),
+	// no policy lets a single pod allocate more than the device has
+	if memoryB > mm.MemoryBTotal {
+		return "", fmt.Errorf("OOM: %d B requested, the device has %d B", memoryB, mm.MemoryBTotal)
+	}
+
+	result, err := mm.admission.AdmitBytes(mm.reservedNoLock(pod.Key()), memoryB, mm.MemoryBTotal)
+	if err != nil {
+		return "", fmt.Errorf("OOM: memory limit exceeded: %w", err)
+	}
+
+	if reserved := mm.PodsMem[pod.Key()]; reserved != nil {
+		reservedPod := podref.ParseKey(reserved.Id)
+		reservedPod.UID = reserved.UID
+		if pod.Matches(reservedPod) {
+			// the pod still holds its allocations
+			if memoryB < reserved.MemoryBUsed {
+				return "", fmt.Errorf("pod %s allocated %d B, more than the new memory quota of %d B", pod, reserved.MemoryBUsed, memoryB)
+			}
+			reserved.MemoryBLimit = memoryB
+			if pod.UID != "" {
+				reserved.UID = pod.UID
+			}
+			return result, nil
+		}
+
+		// the allocations of a recreated pod died with the old one
+		mm.releaseNoLock(pod.Key())
+	}
+
+	mm.PodsMem[pod.Key()] = &PodMemory{
+		Id:           pod.Key(),
+		UID:          pod.UID,
+		MemoryBLimit: memoryB,
 		MemoryBUsed:  0,
 	}
 
-	return nil
+	return result, nil
+}
+
+func (mm *memoryManager) ResizePodQuota(podId string, memoryB uint64) (admission.Result, error) {
+	mm.lock.Lock()
+	defer mm.lock.Unlock()
+
+	pod := mm.PodsMem[podId]
+	if pod == nil {
+		return "", fmt.Errorf("pod %s not registered", podId)
+	}
+	if memoryB > mm.MemoryBTotal {
+		return "", fmt.Errorf("OOM: %d B requested, the device has %d B", memoryB, mm.MemoryBTotal)
+	}
+
+	result, err := mm.admission.AdmitBytes(mm.reservedNoLock(podId), memoryB, mm.MemoryBTotal)
+	if err != nil {
+		return "", fmt.Errorf("OOM: memory limit exceeded: %w", err)
+	}
+
+	pod.MemoryBLimit = memoryB
+	return result, nil
 }
 
 func (mm *memoryManager) UnreservePodQuota(podId string) {
 	mm.lock.Lock()
 	defer mm.lock.Unlock()
 
+	mm.releaseNoLock(podId)
+	delete(mm.PodsMem, podId)
+}
+
+// releaseNoLock drops the allocations of the pod, which it did not free.
+func (mm *memoryManager) releaseNoLock(podId string) {
 	pod := mm.PodsMem[podId]
 	if pod == nil {
 		return
 	}
 
-	mm.MemoryBUsed -= mm.PodsMem[podId].MemoryBUsed
-	delete(mm.PodsMem, podId)
+	leaked := 0
+	for id, a := range mm.allocations {
+		if a.podId == podId {
+			delete(mm.allocations, id)
+			leaked++
+		}
+	}
+	if leaked > 0 {
+		log.Printf("Pod %s leaked %d allocations of %d B on device %s", podId, leaked, pod.MemoryBUsed, mm.DeviceId)
+		mm.leakedCount += leaked
+		mm.leakedB += pod.MemoryBUsed
+	}
+
+	mm.MemoryBUsed -= pod.MemoryBUsed
+	pod.MemoryBUsed = 0
+}
+
+func (mm *memoryManager) AllocateMemory(podId string, memoryB uint64) (uint64, error) {
+	mm.lock.Lock()
+	defer mm.lock.Unlock()
+
+	if err := mm.allocateNoLock(podId, mm.lastAllocationId+1, memoryB, true); err != nil {
+		return 0, err
+	}
+	return mm.lastAllocationId, nil
 }
 
-func (mm *memoryManager) AllocateMemory(podId string, memoryB uint64) error {
+func (mm *memoryManager) RestoreAllocation(podId string, allocationId, memoryB uint64) error {
 	mm.lock.Lock()
 	defer mm.lock.Unlock()
 
+	if _, ok := mm.allocations[allocationId]; ok || allocationId == 0 {
+		return fmt.Errorf("allocation %d already exists", allocationId)
+	}
+	return mm.allocateNoLock(podId, allocationId, memoryB, false)
+}
+
+func (mm *memoryManager) allocateNoLock(podId string, allocationId, memoryB uint64, withinLimit bool) error {
 	pod := mm.PodsMem[podId]
 	if pod == nil {
 		return fmt.Errorf("pod %s not registered", podId)
 	}
 
-	if mm.MemoryBUsed+memoryB > mm.MemoryBTotal || pod.MemoryBUsed+memoryB > pod.MemoryBLimit {
+	if mm.MemoryBUsed+memoryB > mm.MemoryBTotal || withinLimit && pod.MemoryBUsed+memoryB > pod.MemoryBLimit {
 		return fmt.Errorf("OOM: memory limit exceeded")
 	}
 
 	mm.MemoryBUsed += memoryB
 	pod.MemoryBUsed += memoryB
+	mm.allocations[allocationId] = &allocation{podId: podId, memoryB: memoryB}
+	if allocationId > mm.lastAllocationId {
+		mm.lastAllocationId = allocationId
+	}
 
 	return nil
 }
 
-func (mm *memoryManager) FreeMemory(podId string, memoryB uint64) {
+func (mm *memoryManager) SkipAllocationIds(lastAllocationId uint64) {
 	mm.lock.Lock()
 	defer mm.lock.Unlock()
 
-	pod := mm.PodsMem[podId]
-	if pod == nil {
-		return
+	if lastAllocationId > mm.lastAllocationId {
+		mm.lastAllocationId = lastAllocationId
+	}
+}
+
+func (mm *memoryManager) FreeMemory(podId string, allocationId uint64) (uint64, error) {
+	mm.lock.Lock()
+	defer mm.lock.Unlock()
+
+	a := mm.allocations[allocationId]
+	switch {
+	case a == nil && allocationId > 0 && allocationId <= mm.lastAllocationId:
+		return 0, fmt.Errorf("allocation %d was already freed", allocationId)
+	case a == nil:
+		return 0, fmt.Errorf("unknown allocation %d", allocationId)
+	case a.podId != podId:
+		return 0, fmt.Errorf("allocation %d does not belong to pod %s", allocationId, podId)
 	}
 
-	mm.MemoryBUsed -= memoryB
-	pod.MemoryBUsed -= memoryB
+	pod := mm.PodsMem[podId]
+	mm.MemoryBUsed -= a.memoryB
+	pod.MemoryBUsed -= a.memoryB
+	delete(mm.allocations, allocationId)
+
+	return a.memoryB, nil
+}
+
+func (mm *memoryManager) GetLeaks() (int, uint64) {
+	mm.lock.RLock()
+	defer mm.lock.RUnlock()
+
+	return mm.leakedCount, mm.leakedB
 }
 
 func (mm *memoryManager) PrintState() string {
@@ -125,7 +344,7 @@ func (mm *memoryManager) PrintState() string {
 	var sb strings.Builder
 
 	totalMemUsed := float64(mm.MemoryBUsed) / float64(mm.MemoryBTotal)
-	sb.WriteString(fmt.Sprintf("\nDevice %s: %f", mm.DeviceId, totalMemUsed))
+	sb.WriteString(fmt.Sprintf("\nDevice %s: %f (leaked: %d allocations, %d B)", mm.DeviceId, totalMemUsed, mm.leakedCount, mm.leakedB))
 
 	for _, pod := range mm.PodsMem {
 		podMemUsed := float64(pod.MemoryBUsed) / float64(pod.MemoryBLimit)
@@ -0,0 +1,95 @@
+package memorymanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zbsss/device-manager/internal/admission"
+	"github.com/zbsss/device-manager/internal/podref"
+)
+
+func TestReservingAgainKeepsAllocations(t *testing.T) {
+	mm := NewMemoryManager("device", 1000, admission.Policy{Mode: admission.Strict})
+	pod := podref.New("default", "a", "uid")
+
+	_, err := mm.ReservePodQuota(pod, 400)
+	assert.Nil(t, err)
+	id, err := mm.AllocateMemory(pod.Key(), 300)
+	assert.Nil(t, err)
+
+	// a retried reservation, with or without the uid
+	_, err = mm.ReservePodQuota(pod, 500)
+	assert.Nil(t, err)
+	_, err = mm.ReservePodQuota(podref.New("default", "a", ""), 500)
+	assert.Nil(t, err)
+
+	podMem, err := mm.GetPodMemory(pod.Key())
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(500), podMem.MemoryBLimit)
+	assert.Equal(t, uint64(300), podMem.MemoryBUsed)
+	assert.Equal(t, "uid", podMem.UID)
+	assert.Equal(t, []Allocation{{Id: id, MemoryB: 300}}, podMem.Allocations)
+
+	// the device counts the allocation once and it is not leaked
+	_, err = mm.AllocateMemory(pod.Key(), 200)
+	assert.Nil(t, err)
+	_, err = mm.AllocateMemory(pod.Key(), 1)
+	assert.NotNil(t, err)
+	leaked, _ := mm.GetLeaks()
+	assert.Zero(t, leaked)
+
+	// the reservation cannot drop below the allocations
+	_, err = mm.ReservePodQuota(pod, 400)
+	assert.NotNil(t, err)
+	freed, err := mm.FreeMemory(pod.Key(), id)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(300), freed)
+	_, err = mm.ReservePodQuota(pod, 400)
+	assert.Nil(t, err)
+}
+
+func TestReservingRecreatedPodLeaksAllocations(t *testing.T) {
+	mm := NewMemoryManager("device", 1000, admission.Policy{Mode: admission.Strict})
+
+	_, err := mm.ReservePodQuota(podref.New("default", "a", "old"), 400)
+	assert.Nil(t, err)
+	_, err = mm.AllocateMemory("default/a", 300)
+	assert.Nil(t, err)
+
+	_, err = mm.ReservePodQuota(podref.New("default", "a", "new"), 100)
+	assert.Nil(t, err)
+
+	podMem, err := mm.GetPodMemory("default/a")
+	assert.Nil(t, err)
+	assert.Equal(t, "new", podMem.UID)
+	assert.Zero(t, podMem.MemoryBUsed)
+	assert.Empty(t, podMem.Allocations)
+
+	leaked, leakedB := mm.GetLeaks()
+	assert.Equal(t, 1, leaked)
+	assert.Equal(t, uint64(300), leakedB)
+}
+
+func TestUnreservingPodLeaksAllocations(t *testing.T) {
+	mm := NewMemoryManager("device", 1000, admission.Policy{Mode: admission.Strict})
+	pod := podref.New("default", "a", "")
+
+	_, err := mm.ReservePodQuota(pod, 1000)
+	assert.Nil(t, err)
+	id, err := mm.AllocateMemory(pod.Key(), 600)
+	assert.Nil(t, err)
+	mm.UnreservePodQuota(pod.Key())
+
+	leaked, leakedB := mm.GetLeaks()
+	assert.Equal(t, 1, leaked)
+	assert.Equal(t, uint64(600), leakedB)
+
+	// the memory is available again and the id is not reused
+	_, err = mm.ReservePodQuota(pod, 1000)
+	assert.Nil(t, err)
+	next, err := mm.AllocateMemory(pod.Key(), 1000)
+	assert.Nil(t, err)
+	assert.Greater(t, next, id)
+	_, err = mm.FreeMemory(pod.Key(), id)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,124 @@
+// Package peercred identifies the process on the other end of a Unix socket
+// connection by its SO_PEERCRED credentials and the pod it runs in.
+package peercred
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+)
+
+// Cred identifies the peer of a connection, PodUID is empty if the peer does
+// not run in a pod or its pid is not visible to the device manager.
+type Cred struct {
+	Pid    int32
+	Uid    uint32
+	Gid    uint32
+	PodUID string
+}
+
+func (c Cred) String() string {
+	if c.PodUID == "" {
+		return fmt.Sprintf("pid %d uid %d", c.Pid, c.Uid)
+	}
+	return fmt.Sprintf("pid %d uid %d of pod %s", c.Pid, c.Uid, c.PodUID)
+}
+
+// Of returns the credentials of the peer of a Unix socket connection.
+func Of(conn net.Conn) (Cred, error) {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return Cred{}, fmt.Errorf("%s connection has no peer credentials", conn.LocalAddr().Network())
+	}
+
+	cred, err := ofUnixConn(unixConn)
+	if err != nil {
+		return Cred{}, err
+	}
+	if cred.Pid > 0 {
+		cred.PodUID = podUIDOf(cred.Pid)
+	}
+	return cred, nil
+}
+
+// ProcRoot is where the cgroups of peers are read from, /proc of the host when
+// the device manager runs in its pid namespace.
+var ProcRoot = "/proc"
+
+func podUIDOf(pid int32) string {
+	cgroup, err := os.ReadFile(fmt.Sprintf("%s/%d/cgroup", ProcRoot, pid))
+	if err != nil {
+		return ""
+	}
+	return parsePodUID(string(cgroup))
+}
+
+// podUIDPattern matches the pod in cgroup paths of the cgroupfs
+// (kubepods/burstable/pod<uid>/...) and the systemd (kubepods-burstable-pod<uid>.slice)
+// drivers, the latter with the dashes of the uid replaced by underscores.
+var podUIDPattern = regexp.MustCompile(`pod([0-9a-f]{8}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{12})`)
+
+func parsePodUID(cgroup string) string {
+	match := podUIDPattern.FindStringSubmatch(cgroup)
+	if match == nil {
+		return ""
+	}
+	return strings.ReplaceAll(match[1], "_", "-")
+}
+
+// AuthInfo carries the credentials of the peer of a Unix socket connection.
+type AuthInfo struct {
+	credentials.CommonAuthInfo
+	Cred Cred
+}
+
+func (AuthInfo) AuthType() string {
+	return "peercred"
+}
+
+// FromContext returns the credentials of the peer of a gRPC call, false if it
+// did not come over a Unix socket.
+func FromContext(ctx context.Context) (Cred, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return Cred{}, false
+	}
+	info, ok := p.AuthInfo.(AuthInfo)
+	if !ok {
+		return Cred{}, false
+	}
+	return info.Cred, true
+}
+
+// NewServerCredentials returns transport credentials which read the peer
+// credentials of Unix socket connections and leave other connections insecure.
+func NewServerCredentials() credentials.TransportCredentials {
+	return serverCredentials{TransportCredentials: insecure.NewCredentials()}
+}
+
+type serverCredentials struct {
+	credentials.TransportCredentials
+}
+
+func (c serverCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	if _, ok := conn.(*net.UnixConn); !ok {
+		return c.TransportCredentials.ServerHandshake(conn)
+	}
+
+	cred, err := Of(conn)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, AuthInfo{CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.NoSecurity}, Cred: cred}, nil
+}
+
+func (c serverCredentials) Clone() credentials.TransportCredentials {
+	return serverCredentials{TransportCredentials: c.TransportCredentials.Clone()}
+}
@@ -0,0 +1,28 @@
+//go:build linux
+
+package peercred
+
+import (
+	"net"
+	"syscall"
+)
+
+func ofUnixConn(conn *net.UnixConn) (Cred, error) {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return Cred{}, err
+	}
+
+	var ucred *syscall.Ucred
+	var sockErr error
+	err = raw.Control(func(fd uintptr) {
+		ucred, sockErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err != nil {
+		return Cred{}, err
+	}
+	if sockErr != nil {
+		return Cred{}, sockErr
+	}
+	return Cred{Pid: ucred.Pid, Uid: ucred.Uid, Gid: ucred.Gid}, nil
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package peercred
+
+import (
+	"fmt"
+	"net"
+)
+
+func ofUnixConn(conn *net.UnixConn) (Cred, error) {
+	return Cred{}, fmt.Errorf("peer credentials are only supported on linux")
+}
@@ -0,0 +1,50 @@
+package peercred
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePodUID(t *testing.T) {
+	cgroupfs := "0::/kubepods/burstable/pod6b3c2b6e-1f0a-4c5e-9d8a-0e4f2a1b3c4d/0123456789abcdef\n"
+	assert.Equal(t, "6b3c2b6e-1f0a-4c5e-9d8a-0e4f2a1b3c4d", parsePodUID(cgroupfs))
+
+	systemd := "12:memory:/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod6b3c2b6e_1f0a_4c5e_9d8a_0e4f2a1b3c4d.slice/cri-containerd-0123.scope\n"
+	assert.Equal(t, "6b3c2b6e-1f0a-4c5e-9d8a-0e4f2a1b3c4d", parsePodUID(systemd))
+
+	assert.Equal(t, "", parsePodUID("0::/user.slice/user-1000.slice/session-1.scope\n"))
+}
+
+func TestOfReadsPeerOfUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "peer.sock")
+	listener, err := net.Listen("unix", path)
+	assert.Nil(t, err)
+	defer listener.Close()
+
+	client, err := net.Dial("unix", path)
+	assert.Nil(t, err)
+	defer client.Close()
+
+	conn, err := listener.Accept()
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	cred, err := Of(conn)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(os.Getpid()), cred.Pid)
+	assert.Equal(t, uint32(os.Getuid()), cred.Uid)
+
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer tcp.Close()
+	tcpClient, err := net.Dial("tcp", tcp.Addr().String())
+	assert.Nil(t, err)
+	defer tcpClient.Close()
+
+	_, err = Of(tcpClient)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,46 @@
+// Package podref identifies the pods sharing a device.
+package podref
+
+import "strings"
+
+// DefaultNamespace is the namespace of pods whose clients only send the pod name.
+const DefaultNamespace = "default"
+
+// Ref identifies a pod by its namespace and name, UID tells apart pods which
+// were recreated with the same name and is empty when the client did not send it.
+type Ref struct {
+	Namespace string
+	Name      string
+	UID       string
+}
+
+func New(namespace, name, uid string) Ref {
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	return Ref{Namespace: namespace, Name: name, UID: uid}
+}
+
+// Key is "namespace/name", it keys the pod in the device manager, the
+// scheduler and the lease history.
+func (r Ref) Key() string {
+	return r.Namespace + "/" + r.Name
+}
+
+func (r Ref) String() string {
+	return r.Key()
+}
+
+// ParseKey is the inverse of Key, a bare name is in the default namespace.
+func ParseKey(key string) Ref {
+	if namespace, name, ok := strings.Cut(key, "/"); ok {
+		return New(namespace, name, "")
+	}
+	return New("", key, "")
+}
+
+// Matches reports whether the refs identify the same pod, a missing UID
+// matches any.
+func (r Ref) Matches(other Ref) bool {
+	return r.Key() == other.Key() && (r.UID == "" || other.UID == "" || r.UID == other.UID)
+}
@@ -0,0 +1,25 @@
+package podref
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefKey(t *testing.T) {
+	assert.Equal(t, "team/pod", New("team", "pod", "uid").Key())
+	assert.Equal(t, "default/pod", New("", "pod", "").Key())
+
+	assert.Equal(t, New("team", "pod", ""), ParseKey("team/pod"))
+	assert.Equal(t, New("default", "pod", ""), ParseKey("pod"))
+}
+
+func TestRefMatches(t *testing.T) {
+	pod := New("team", "pod", "uid")
+
+	assert.True(t, pod.Matches(New("team", "pod", "uid")))
+	assert.True(t, pod.Matches(New("team", "pod", "")))
+	assert.False(t, pod.Matches(New("team", "pod", "recreated")))
+	assert.False(t, pod.Matches(New("other", "pod", "uid")))
+	assert.False(t, pod.Matches(New("", "pod", "")))
+}
@@ -2,45 +2,93 @@ package scheduler
 
 import (
 	"fmt"
+	"log"
 	"strings"
+	"time"
+
+	"github.com/zbsss/device-manager/internal/admission"
+	"github.com/zbsss/device-manager/internal/leasehistory"
+	"github.com/zbsss/device-manager/internal/podref"
+	"github.com/zbsss/device-manager/pkg/quota"
 )
 
 type Scheduler interface {
+	// Stop stops the scheduling loop and waits for running enforcements, no
+	// lease is recorded afterwards.
 	Stop()
 
-	EnqueueLeaseRequest(req *TokenLeaseRequest)
+	// EnqueueLeaseRequest queues the request for the token, it fails if the
+	// pod has no quota reserved.
+	EnqueueLeaseRequest(req *TokenLeaseRequest) error
+	// CancelLeaseRequest removes the request from the queue, a lease it was
+	// granted already is returned.
+	CancelLeaseRequest(req *TokenLeaseRequest)
+	// AbandonLease ends the lease if it was not returned yet, its client is gone.
+	AbandonLease(lease *TokenLease)
+	// TryLease grants the token to the pod if it can have it right away,
+	// otherwise it estimates how long a request of the pod would wait. It
+	// fails if the pod has no quota reserved.
+	TryLease(podId string) (*TokenLease, *QueueEstimate, error)
+	// ReturnLease ends the lease with the id of `lease`, it fails if that lease
+	// already ended or `lease` has no id.
 	ReturnLease(lease *TokenLease) error
+	// WatchLease subscribes to notices for the leases of the pod. The channel
+	// is closed when the scheduler stops, call cancel to unsubscribe.
+	WatchLease(podId string) (notices <-chan *LeaseNotice, cancel func())
+	// SendNotice delivers the notice to the watchers of the pod, it reports
+	// whether the pod has any.
+	SendNotice(notice *LeaseNotice) bool
 
-	GetAvailableQuota() float64
-	ReservePodQuota(podQuota *PodQuota) error
+	GetAvailableQuota() quota.MilliShares
+	// GetNamespaceQuota is the sum of requests of the pods in the namespace.
+	GetNamespaceQuota(namespace string) quota.MilliShares
+	// ReservePodQuota reserves the requests of the pod if the admission policy
+	// of the device admits them, reserving again replaces the reservation.
+	ReservePodQuota(podQuota *PodQuota) (admission.Result, error)
 	UnreservePodQuota(podId string)
+	GetPodUsage(podId string) (*PodUsage, error)
 
 	PrintState() string
 }
 
 func (s *scheduler) Stop() {
-	s.isRunning.Store(false)
+	if s.isRunning.CompareAndSwap(true, false) {
+		close(s.done)
+		<-s.stopped
+		s.enforcements.Wait()
+
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		for podId, watchers := range s.watchers {
+			for watcher := range watchers {
+				close(watcher)
+			}
+			delete(s.watchers, podId)
+		}
+	}
 }
 
 func (s *scheduler) PrintState() string {
-	s.lock.RLock()
-	defer s.lock.RUnlock()
+	// calculating used quota drops history outside of the window
+	s.lock.Lock()
+	defer s.lock.Unlock()
 
 	var sb strings.Builder
 	total := 0.0
 	usedQuota := s.calculateUsedQuotaPerPod()
 	for podId, podQuota := range s.podQuota {
 		total += usedQuota[podId]
-		sb.WriteString(fmt.Sprintf("\n\tPod %s: %f (req: %f, limit: %f)", podId, usedQuota[podId], podQuota.Requests, podQuota.Limit))
+		sb.WriteString(fmt.Sprintf("\n\tPod %s: %f (req: %s, limit: %s, priority: %d)", podId, usedQuota[podId], podQuota.Requests, podQuota.Limit, podQuota.Priority))
 	}
 	return fmt.Sprintf("\nDevice %s: %f", s.deviceId, total) + sb.String()
 }
 
-func (s *scheduler) GetAvailableQuota() float64 {
+func (s *scheduler) GetAvailableQuota() quota.MilliShares {
 	s.lock.RLock()
 	defer s.lock.RUnlock()
 
-	availableQuota := 1.0
+	availableQuota := quota.Device
 	for _, podQuota := range s.podQuota {
 		availableQuota -= podQuota.Requests
 	}
@@ -48,17 +96,39 @@ func (s *scheduler) GetAvailableQuota() float64 {
 	return availableQuota
 }
 
-func (s *scheduler) ReservePodQuota(podQuota *PodQuota) error {
-	availableQuota := s.GetAvailableQuota()
-	if availableQuota <= 0 {
-		return fmt.Errorf("not enough quota available")
+func (s *scheduler) GetNamespaceQuota(namespace string) quota.MilliShares {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var namespaceQuota quota.MilliShares
+	for podId, podQuota := range s.podQuota {
+		if podref.ParseKey(podId).Namespace == namespace {
+			namespaceQuota += podQuota.Requests
+		}
 	}
 
+	return namespaceQuota
+}
+
+func (s *scheduler) ReservePodQuota(podQuota *PodQuota) (admission.Result, error) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
+	var reserved quota.MilliShares
+	for podId, reservedQuota := range s.podQuota {
+		if podId != podQuota.PodId {
+			reserved += reservedQuota.Requests
+		}
+	}
+
+	result, err := s.admission.Admit(reserved, podQuota.Requests)
+	if err != nil {
+		return "", fmt.Errorf("not enough quota available: %w", err)
+	}
+
 	s.podQuota[podQuota.PodId] = podQuota
-	return nil
+	s.notify()
+	return result, nil
 }
 
 func (s *scheduler) UnreservePodQuota(podId string) {
@@ -66,29 +136,165 @@ func (s *scheduler) UnreservePodQuota(podId string) {
 	defer s.lock.Unlock()
 
 	if s.currentLease != nil && s.currentLease.PodId == podId {
-		s.currentLease = nil
+		s.cancelLeaseNoLock(leasehistory.EndUnreserved)
 	}
 
-	if len(s.queue) > 0 {
-		for i, req := range s.queue {
-			if req.PodId == podId {
-				close(req.Response)
-				s.queue = append(s.queue[:i], s.queue[i+1:]...)
-			}
+	queue := make([]*TokenLeaseRequest, 0, len(s.queue))
+	for _, req := range s.queue {
+		if req.PodId == podId {
+			close(req.Response)
+			continue
 		}
+		queue = append(queue, req)
 	}
+	s.queue = queue
 
 	delete(s.podQuota, podId)
+	delete(s.penalties, podId)
+	delete(s.queueWaits, podId)
+	s.policy.PodRemoved(podId)
+	s.notify()
 }
 
-func (s *scheduler) EnqueueLeaseRequest(req *TokenLeaseRequest) {
+func (s *scheduler) EnqueueLeaseRequest(req *TokenLeaseRequest) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
+	if s.podQuota[req.PodId] == nil {
+		return fmt.Errorf("pod %s has no quota reserved on device %s", req.PodId, s.deviceId)
+	}
+
+	req.EnqueuedAt = s.clock.Now()
 	s.queue = append(s.queue, req)
+	s.notify()
+	return nil
+}
+
+func (s *scheduler) CancelLeaseRequest(req *TokenLeaseRequest) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.removeRequestNoLock(req) {
+		s.notify()
+		return
+	}
+
+	// leases are sent under the lock, so a request which left the queue
+	// already has its lease in the buffer
+	select {
+	case lease := <-req.Response:
+		if lease != nil {
+			s.abandonLeaseNoLock(lease, "gave up on the token before it was granted")
+		}
+	default:
+	}
+}
+
+func (s *scheduler) AbandonLease(lease *TokenLease) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.abandonLeaseNoLock(lease, "is gone")
+}
+
+func (s *scheduler) abandonLeaseNoLock(lease *TokenLease, reason string) {
+	if s.currentLease == nil || s.currentLease.Id != lease.Id || s.currentLease.PodId != lease.PodId {
+		return
+	}
+
+	log.Printf("Pod %s holding lease %d of device %s %s", lease.PodId, lease.Id, s.deviceId, reason)
+	s.cancelLeaseNoLock(leasehistory.EndAbandoned)
+	s.notify()
+}
+
+func (s *scheduler) TryLease(podId string) (*TokenLease, *QueueEstimate, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.podQuota[podId] == nil {
+		return nil, nil, fmt.Errorf("pod %s has no quota reserved on device %s", podId, s.deviceId)
+	}
+
+	if s.currentLease == nil {
+		// the pod is only granted the token if the policy picks it over the
+		// queued requests, which are left to the run loop
+		now := s.clock.Now()
+		req := &TokenLeaseRequest{PodId: podId, Response: make(chan *TokenLease, 1), EnqueuedAt: now}
+		usedQuotaPerPod := s.calculateUsedQuotaPerPod()
+		requests := append(append([]*TokenLeaseRequest{}, s.queue...), req)
+
+		candidates, _ := s.candidatesNoLock(requests, usedQuotaPerPod, now)
+		if len(candidates) > 0 && s.pickNoLock(candidates, usedQuotaPerPod, now) == req {
+			lease := s.grantNoLock(req, now)
+			// the run loop has to pick up the deadlines of the lease
+			s.notify()
+			return lease, nil, nil
+		}
+	}
+
+	return nil, s.estimateQueueNoLock(podId), nil
+}
+
+// removeRequestNoLock removes the request from the queue, it reports whether
+// the request was still queued.
+func (s *scheduler) removeRequestNoLock(req *TokenLeaseRequest) bool {
+	for i, queued := range s.queue {
+		if queued == req {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// estimateQueueNoLock assumes that the lease holder keeps the token until it
+// expires and every request ahead holds it for as long as leases in the window
+// did on average. The pod also waits for its penalty to end and for its usage
+// to drop below its limit.
+func (s *scheduler) estimateQueueNoLock(podId string) *QueueEstimate {
+	now := s.clock.Now()
+	usedQuotaPerPod := s.calculateUsedQuotaPerPod()
+
+	var held time.Duration
+	for _, entry := range s.leaseHistory {
+		held += entry.ReturnedAt.Sub(entry.LeasedAt)
+	}
+	meanLease := s.evictionPeriod
+	if len(s.leaseHistory) > 0 {
+		meanLease = held / time.Duration(len(s.leaseHistory))
+	}
+
+	estimate := &QueueEstimate{Position: 1}
+	for _, req := range s.queue {
+		if req.PodId != podId {
+			estimate.Position++
+		}
+	}
+
+	var wait time.Duration
+	if s.currentLease != nil && s.currentLease.ExpiresAt.After(now) {
+		wait = s.currentLease.ExpiresAt.Sub(now)
+	}
+	wait += time.Duration(estimate.Position-1) * meanLease
+
+	if penalty, ok := s.penalties[podId]; ok && penalty.Sub(now) > wait {
+		wait = penalty.Sub(now)
+	}
+	if podQuota := s.podQuota[podId]; podQuota != nil && usedQuotaPerPod[podId] >= podQuota.Limit.Fraction() {
+		if releasedAt := s.podLimitReleasedAtNoLock(podId, usedQuotaPerPod[podId]); releasedAt.Sub(now) > wait {
+			wait = releasedAt.Sub(now)
+		}
+	}
+
+	estimate.Wait = wait
+	return estimate
 }
 
 func (s *scheduler) ReturnLease(lease *TokenLease) error {
+	if lease.Id == 0 {
+		return fmt.Errorf("lease not specified")
+	}
+
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
@@ -100,7 +306,81 @@ func (s *scheduler) ReturnLease(lease *TokenLease) error {
 		return fmt.Errorf("pod %s does not have a lease", lease.PodId)
 	}
 
-	s.cancelLeaseNoLock()
+	if s.currentLease.Id != lease.Id {
+		return fmt.Errorf("lease %d of pod %s already ended, it holds lease %d", lease.Id, lease.PodId, s.currentLease.Id)
+	}
+
+	endReason := leasehistory.EndReturned
+	if !s.currentLease.YieldBy.IsZero() {
+		endReason = leasehistory.EndYielded
+	}
+
+	s.cancelLeaseNoLock(endReason)
+	s.notify()
 
 	return nil
 }
+
+func (s *scheduler) WatchLease(podId string) (<-chan *LeaseNotice, func()) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	watcher := make(chan *LeaseNotice, 1)
+	if !s.isRunning.Load() {
+		close(watcher)
+		return watcher, func() {}
+	}
+
+	if s.watchers[podId] == nil {
+		s.watchers[podId] = map[chan *LeaseNotice]struct{}{}
+	}
+	s.watchers[podId][watcher] = struct{}{}
+
+	cancel := func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		if _, ok := s.watchers[podId][watcher]; ok {
+			delete(s.watchers[podId], watcher)
+			if len(s.watchers[podId]) == 0 {
+				delete(s.watchers, podId)
+			}
+			close(watcher)
+		}
+	}
+
+	return watcher, cancel
+}
+
+func (s *scheduler) SendNotice(notice *LeaseNotice) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.sendNoticeNoLock(notice)
+}
+
+func (s *scheduler) GetPodUsage(podId string) (*PodUsage, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	podQuota, ok := s.podQuota[podId]
+	if !ok {
+		return nil, fmt.Errorf("pod %s not registered", podId)
+	}
+
+	usage := &PodUsage{
+		PodQuota:       *podQuota,
+		Used:           s.calculateUsedQuotaPerPod()[podId],
+		WindowDuration: s.windowDuration,
+		HoldsToken:     s.currentLease != nil && s.currentLease.PodId == podId,
+		QueueWait:      s.queueWaitStatsNoLock(podId),
+	}
+
+	for _, req := range s.queue {
+		if req.PodId == podId {
+			usage.QueuedRequests++
+		}
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,124 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time of a scheduler, replaced by a VirtualClock to
+// run the scheduler deterministically.
+type Clock interface {
+	Now() time.Time
+	// NewTimer creates a timer which fires once `d` elapses on the clock.
+	NewTimer(d time.Duration) Timer
+}
+
+type Timer interface {
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, it reports whether it was active.
+	Stop() bool
+}
+
+// RealClock is the wall clock.
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+func (RealClock) NewTimer(d time.Duration) Timer {
+	return &realTimer{timer: time.NewTimer(d)}
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (t *realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t *realTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+// VirtualClock only moves when it is set, firing the timers which are due.
+type VirtualClock struct {
+	lock   sync.Mutex
+	now    time.Time
+	timers []*virtualTimer
+}
+
+func NewVirtualClock(start time.Time) *VirtualClock {
+	return &VirtualClock{now: start}
+}
+
+func (c *VirtualClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.now
+}
+
+func (c *VirtualClock) NewTimer(d time.Duration) Timer {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	timer := &virtualTimer{clock: c, deadline: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		timer.c <- c.now
+		return timer
+	}
+
+	c.timers = append(c.timers, timer)
+	return timer
+}
+
+// Advance moves the clock forward by `d`.
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the clock to `now`, which must not be before the current time.
+func (c *VirtualClock) Set(now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if now.Before(c.now) {
+		return
+	}
+	c.now = now
+
+	pending := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.deadline.After(now) {
+			pending = append(pending, timer)
+			continue
+		}
+		timer.c <- now
+	}
+	c.timers = pending
+}
+
+type virtualTimer struct {
+	clock    *VirtualClock
+	deadline time.Time
+	c        chan time.Time
+}
+
+func (t *virtualTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *virtualTimer) Stop() bool {
+	t.clock.lock.Lock()
+	defer t.clock.lock.Unlock()
+
+	for i, timer := range t.clock.timers {
+		if timer == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
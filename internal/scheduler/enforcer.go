@@ -0,0 +1,195 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/zbsss/device-manager/internal/leasehistory"
+	"github.com/zbsss/device-manager/internal/podref"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// EnforcementAction is what happens to a pod whose lease has to be terminated
+// but which did not return the token.
+type EnforcementAction string
+
+const (
+	// ActionEvict evicts the pod through the Eviction API, respecting PodDisruptionBudgets.
+	ActionEvict EnforcementAction = "evict"
+	// ActionDelete deletes the pod.
+	ActionDelete EnforcementAction = "delete"
+	// ActionRevoke takes the token away and keeps the pod out of the queue for the penalty period.
+	ActionRevoke EnforcementAction = "revoke"
+	// ActionLog only reports the overrun, the pod keeps the token.
+	ActionLog EnforcementAction = "log"
+	// ActionAnnotate marks the pod with an annotation, the pod keeps the token.
+	ActionAnnotate EnforcementAction = "annotate"
+)
+
+const (
+	// EnforcementAnnotation is set on pods by ActionAnnotate.
+	EnforcementAnnotation = "sharedev/lease-overrun"
+	// EnforcementEventReason is the reason of the Event emitted on enforced pods.
+	EnforcementEventReason = "LeaseOverrun"
+
+	enforcementTimeout = 10 * time.Second
+	enforcementSource  = "device-manager"
+)
+
+// ParseEnforcementAction validates the name of an action.
+func ParseEnforcementAction(name string) (EnforcementAction, error) {
+	action := EnforcementAction(name)
+	switch action {
+	case ActionEvict, ActionDelete, ActionRevoke, ActionLog, ActionAnnotate:
+		return action, nil
+	}
+	return "", fmt.Errorf("unknown enforcement action %q", name)
+}
+
+// TerminatesLease reports whether the lease of the pod ends once the action
+// is enforced, otherwise the pod keeps the token.
+func (a EnforcementAction) TerminatesLease() bool {
+	return a == ActionEvict || a == ActionDelete || a == ActionRevoke
+}
+
+// endReason is recorded in the lease history when the action terminates a lease.
+func (a EnforcementAction) endReason() string {
+	switch a {
+	case ActionDelete:
+		return leasehistory.EndDeleted
+	case ActionRevoke:
+		return leasehistory.EndRevoked
+	default:
+		return leasehistory.EndEvicted
+	}
+}
+
+// EnforcementConfig selects the action for a pod. An action set for the
+// priority of the pod takes precedence over the one set for the device.
+type EnforcementConfig struct {
+	Action          EnforcementAction
+	DeviceActions   map[string]EnforcementAction
+	PriorityActions map[int32]EnforcementAction
+	// PenaltyDuration is how long a pod whose lease was revoked cannot get the token.
+	PenaltyDuration time.Duration
+}
+
+func (c *EnforcementConfig) actionFor(deviceId string, priority int32) EnforcementAction {
+	if action, ok := c.PriorityActions[priority]; ok {
+		return action
+	}
+	if action, ok := c.DeviceActions[deviceId]; ok {
+		return action
+	}
+	if c.Action == "" {
+		return ActionEvict
+	}
+	return c.Action
+}
+
+type Enforcer interface {
+	// Enforce applies the action to the pod and records it as an Event on the
+	// pod. A pod recreated with the same name, i.e. with another UID, is left alone.
+	Enforce(action EnforcementAction, pod podref.Ref, reason string) error
+}
+
+type kubeEnforcer struct {
+	clientset kubernetes.Interface
+}
+
+// NewEnforcer creates an Enforcer acting on pods through the clientset. When
+// the clientset is nil, i.e. outside of a cluster, actions are only logged.
+func NewEnforcer(clientset kubernetes.Interface) Enforcer {
+	return &kubeEnforcer{clientset: clientset}
+}
+
+func (e *kubeEnforcer) Enforce(action EnforcementAction, ref podref.Ref, reason string) error {
+	log.Printf("Enforcing %s on pod %s: %s\n", action, ref, reason)
+
+	if e.clientset == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), enforcementTimeout)
+	defer cancel()
+
+	if action == ActionRevoke || action == ActionLog {
+		// the pod is left alone, it only has to be named by the event
+		e.recordEvent(ctx, &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      ref.Name,
+			Namespace: ref.Namespace,
+			UID:       types.UID(ref.UID),
+		}}, action, reason)
+		return nil
+	}
+
+	pod, err := e.clientset.CoreV1().Pods(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get pod: %w", err)
+	}
+	if ref.UID != "" && string(pod.UID) != ref.UID {
+		log.Printf("Pod %s was recreated, skipping %s\n", ref, action)
+		return nil
+	}
+
+	// the preconditions keep a pod recreated in the meantime alive
+	preconditions := &metav1.Preconditions{UID: &pod.UID}
+	switch action {
+	case ActionEvict:
+		eviction := &policyv1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ref.Name,
+				Namespace: ref.Namespace,
+			},
+			DeleteOptions: &metav1.DeleteOptions{Preconditions: preconditions},
+		}
+		err = e.clientset.PolicyV1().Evictions(ref.Namespace).Evict(ctx, eviction)
+	case ActionDelete:
+		err = e.clientset.CoreV1().Pods(ref.Namespace).Delete(ctx, ref.Name, metav1.DeleteOptions{Preconditions: preconditions})
+	case ActionAnnotate:
+		patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, EnforcementAnnotation, time.Now().Format(time.RFC3339))
+		_, err = e.clientset.CoreV1().Pods(ref.Namespace).Patch(ctx, ref.Name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to %s pod: %w", action, err)
+	}
+
+	e.recordEvent(ctx, pod, action, reason)
+	return nil
+}
+
+func (e *kubeEnforcer) recordEvent(ctx context.Context, pod *v1.Pod, action EnforcementAction, reason string) {
+	now := metav1.Now()
+	event := &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: pod.Name + "-",
+			Namespace:    pod.Namespace,
+		},
+		InvolvedObject: v1.ObjectReference{
+			Kind:            "Pod",
+			APIVersion:      "v1",
+			Name:            pod.Name,
+			Namespace:       pod.Namespace,
+			UID:             pod.UID,
+			ResourceVersion: pod.ResourceVersion,
+		},
+		Reason:         EnforcementEventReason,
+		Message:        fmt.Sprintf("Lease on shared device terminated (%s), action: %s", reason, action),
+		Type:           v1.EventTypeWarning,
+		Source:         v1.EventSource{Component: enforcementSource},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+
+	_, err := e.clientset.CoreV1().Events(pod.Namespace).Create(ctx, event, metav1.CreateOptions{})
+	if err != nil {
+		log.Printf("Failed to record event for pod %s/%s: %v\n", pod.Namespace, pod.Name, err)
+	}
+}
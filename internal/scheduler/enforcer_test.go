@@ -0,0 +1,291 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zbsss/device-manager/internal/leasehistory"
+	"github.com/zbsss/device-manager/internal/podref"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newFakeClientset() *fake.Clientset {
+	return fake.NewSimpleClientset(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "team", UID: "uid"},
+	})
+}
+
+func podActions(clientset *fake.Clientset) []k8stesting.Action {
+	actions := []k8stesting.Action{}
+	for _, action := range clientset.Actions() {
+		if action.GetResource().Resource == "pods" && action.GetVerb() != "get" {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+func assertEventRecorded(t *testing.T, clientset *fake.Clientset, action EnforcementAction) {
+	t.Helper()
+
+	events, err := clientset.CoreV1().Events("team").List(context.Background(), metav1.ListOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, events.Items, 1)
+
+	event := events.Items[0]
+	assert.Equal(t, EnforcementEventReason, event.Reason)
+	assert.Equal(t, "pod", event.InvolvedObject.Name)
+	assert.Equal(t, "uid", string(event.InvolvedObject.UID))
+	assert.Contains(t, event.Message, string(action))
+}
+
+func TestEnforcerEvict(t *testing.T) {
+	clientset := newFakeClientset()
+
+	err := NewEnforcer(clientset).Enforce(ActionEvict, podref.New("team", "pod", "uid"), "lease expired")
+	assert.Nil(t, err)
+
+	actions := podActions(clientset)
+	assert.Len(t, actions, 1)
+	assert.Equal(t, "create", actions[0].GetVerb())
+	assert.Equal(t, "eviction", actions[0].GetSubresource())
+	assert.Equal(t, "team", actions[0].GetNamespace())
+	assertEventRecorded(t, clientset, ActionEvict)
+}
+
+func TestEnforcerDelete(t *testing.T) {
+	clientset := newFakeClientset()
+
+	err := NewEnforcer(clientset).Enforce(ActionDelete, podref.New("team", "pod", "uid"), "lease expired")
+	assert.Nil(t, err)
+
+	_, err = clientset.CoreV1().Pods("team").Get(context.Background(), "pod", metav1.GetOptions{})
+	assert.NotNil(t, err)
+	assertEventRecorded(t, clientset, ActionDelete)
+}
+
+func TestEnforcerAnnotate(t *testing.T) {
+	clientset := newFakeClientset()
+
+	err := NewEnforcer(clientset).Enforce(ActionAnnotate, podref.New("team", "pod", ""), "lease expired")
+	assert.Nil(t, err)
+
+	pod, err := clientset.CoreV1().Pods("team").Get(context.Background(), "pod", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Contains(t, pod.Annotations, EnforcementAnnotation)
+	assertEventRecorded(t, clientset, ActionAnnotate)
+}
+
+func TestEnforcerRevokeAndLogOnlyRecordEvent(t *testing.T) {
+	for _, action := range []EnforcementAction{ActionRevoke, ActionLog} {
+		clientset := newFakeClientset()
+
+		err := NewEnforcer(clientset).Enforce(action, podref.New("team", "pod", "uid"), "lease expired")
+		assert.Nil(t, err)
+
+		// the pod is not even read
+		assert.Len(t, clientset.Actions(), 1, action)
+		assertEventRecorded(t, clientset, action)
+	}
+}
+
+func TestEnforcerMissingPod(t *testing.T) {
+	err := NewEnforcer(fake.NewSimpleClientset()).Enforce(ActionEvict, podref.New("team", "pod", "uid"), "lease expired")
+	assert.NotNil(t, err)
+}
+
+func TestEnforcerSkipsRecreatedPod(t *testing.T) {
+	clientset := newFakeClientset()
+
+	err := NewEnforcer(clientset).Enforce(ActionDelete, podref.New("team", "pod", "old-uid"), "lease expired")
+	assert.Nil(t, err)
+
+	_, err = clientset.CoreV1().Pods("team").Get(context.Background(), "pod", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, podActions(clientset), 0)
+}
+
+func TestEnforcementConfigActionFor(t *testing.T) {
+	config := EnforcementConfig{
+		Action:          ActionLog,
+		DeviceActions:   map[string]EnforcementAction{"device": ActionRevoke},
+		PriorityActions: map[int32]EnforcementAction{10: ActionAnnotate},
+	}
+
+	assert.Equal(t, ActionLog, config.actionFor("other", 0))
+	assert.Equal(t, ActionRevoke, config.actionFor("device", 0))
+	assert.Equal(t, ActionAnnotate, config.actionFor("device", 10))
+	assert.Equal(t, ActionEvict, (&EnforcementConfig{}).actionFor("device", 0))
+}
+
+type recordingEnforcer struct {
+	actions []EnforcementAction
+	pods    []podref.Ref
+}
+
+func (e *recordingEnforcer) Enforce(action EnforcementAction, pod podref.Ref, reason string) error {
+	e.actions = append(e.actions, action)
+	e.pods = append(e.pods, pod)
+	return nil
+}
+
+func TestSchedulerRevokeKeepsPodInPenaltyBox(t *testing.T) {
+	enforcer := &recordingEnforcer{}
+	config := SchedulerConfig{
+		WindowDuration: time.Minute,
+		EvictionPeriod: 20 * time.Millisecond,
+		Enforcer:       enforcer,
+		Enforcement:    EnforcementConfig{Action: ActionRevoke, PenaltyDuration: 100 * time.Millisecond},
+	}
+	s := startScheduler("device", config, &fifoPolicy{}).(*scheduler)
+	defer s.Stop()
+
+	reserve(t, s, &PodQuota{PodId: "a", Requests: 500, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "b", Requests: 500, Limit: 1000})
+
+	awaitLease(t, enqueue(s, "a"), time.Second)
+	waitingA := enqueue(s, "a")
+	waitingB := enqueue(s, "b")
+
+	// a arrived first, but its lease was revoked
+	lease := awaitLease(t, waitingB, time.Second)
+	assert.Equal(t, "b", lease.PodId)
+	assert.Nil(t, s.ReturnLease(lease))
+
+	start := time.Now()
+	awaitLease(t, waitingA, time.Second)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Equal(t, []EnforcementAction{ActionRevoke}, enforcer.actions)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	assert.Len(t, s.leaseHistory, 2)
+	assert.Equal(t, leasehistory.EndReturned, s.leaseHistory[0].EndReason)
+	assert.Equal(t, leasehistory.EndRevoked, s.leaseHistory[1].EndReason)
+	assert.Equal(t, 0.5, s.leaseHistory[1].Requests)
+	assert.False(t, s.leaseHistory[1].EnqueuedAt.IsZero())
+}
+
+func TestSchedulerDryRunKeepsLease(t *testing.T) {
+	enforcer := &recordingEnforcer{}
+	config := SchedulerConfig{
+		WindowDuration: time.Minute,
+		EvictionPeriod: 20 * time.Millisecond,
+		Enforcer:       enforcer,
+		Enforcement:    EnforcementConfig{Action: ActionLog},
+	}
+	s := startScheduler("device", config, &fifoPolicy{})
+	defer s.Stop()
+
+	reserve(t, s, &PodQuota{PodId: "team/a", UID: "uid", Requests: 500, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "team/b", Requests: 500, Limit: 1000})
+
+	held := awaitLease(t, enqueue(s, "team/a"), time.Second)
+	waiting := enqueue(s, "team/b")
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, waiting.Response, 0)
+	assert.Nil(t, s.ReturnLease(held))
+	awaitLease(t, waiting, time.Second)
+
+	// the action is enforced once per lease
+	assert.Equal(t, []EnforcementAction{ActionLog}, enforcer.actions)
+	assert.Equal(t, []podref.Ref{podref.New("team", "a", "uid")}, enforcer.pods)
+}
+
+// blockingEnforcer holds every enforcement until it is released.
+type blockingEnforcer struct {
+	started chan EnforcementAction
+	release chan struct{}
+}
+
+func (e *blockingEnforcer) Enforce(action EnforcementAction, pod podref.Ref, reason string) error {
+	e.started <- action
+	<-e.release
+	return nil
+}
+
+func TestSchedulerEnforcesWithoutHoldingLock(t *testing.T) {
+	enforcer := &blockingEnforcer{started: make(chan EnforcementAction, 1), release: make(chan struct{})}
+	config := SchedulerConfig{
+		WindowDuration: time.Minute,
+		EvictionPeriod: 20 * time.Millisecond,
+		Enforcer:       enforcer,
+		Enforcement:    EnforcementConfig{Action: ActionEvict},
+	}
+	s := startScheduler("device", config, &fifoPolicy{}).(*scheduler)
+	defer s.Stop()
+
+	reserve(t, s, &PodQuota{PodId: "a", Requests: 300, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "b", Requests: 300, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "c", Requests: 300, Limit: 1000})
+
+	held := awaitLease(t, enqueue(s, "a"), time.Second)
+	waiting := enqueue(s, "b")
+	assert.Equal(t, ActionEvict, <-enforcer.started)
+
+	// token requests are served while the pod is evicted
+	_, estimate, err := s.TryLease("c")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, estimate.Position)
+	assert.Nil(t, s.ReturnLease(held))
+	assert.Equal(t, "b", awaitLease(t, waiting, time.Second).PodId)
+
+	// the eviction ends after the lease was returned, the next lease is kept
+	close(enforcer.release)
+	time.Sleep(10 * time.Millisecond)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	assert.Equal(t, "b", s.currentLease.PodId)
+	assert.Equal(t, leasehistory.EndReturned, s.leaseHistory[0].EndReason)
+}
+
+func TestSchedulerStopWaitsForEnforcement(t *testing.T) {
+	enforcer := &blockingEnforcer{started: make(chan EnforcementAction, 1), release: make(chan struct{})}
+	ring := leasehistory.NewRingBuffer(10)
+	history := leasehistory.NewRecorder(ring)
+	config := SchedulerConfig{
+		WindowDuration: time.Minute,
+		EvictionPeriod: 20 * time.Millisecond,
+		Enforcer:       enforcer,
+		Enforcement:    EnforcementConfig{Action: ActionRevoke},
+		History:        history,
+	}
+	s := startScheduler("device", config, &fifoPolicy{})
+
+	reserve(t, s, &PodQuota{PodId: "a", Requests: 300, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "b", Requests: 300, Limit: 1000})
+
+	awaitLease(t, enqueue(s, "a"), time.Second)
+	enqueue(s, "b")
+	assert.Equal(t, ActionRevoke, <-enforcer.started)
+
+	stopped := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(stopped)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case <-stopped:
+		t.Fatal("scheduler stopped during an enforcement")
+	default:
+	}
+
+	// the revoked lease is recorded before the recorder is closed
+	close(enforcer.release)
+	<-stopped
+	history.Close()
+
+	entries := ring.Query(leasehistory.Filter{})
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, leasehistory.EndRevoked, entries[0].EndReason)
+	}
+}
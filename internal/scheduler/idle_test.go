@@ -0,0 +1,43 @@
+//go:build linux || darwin
+
+package scheduler
+
+import (
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func processCPUTime() time.Duration {
+	var usage syscall.Rusage
+	_ = syscall.Getrusage(syscall.RUSAGE_SELF, &usage)
+
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+}
+
+// BenchmarkIdleCPU reports the CPU time consumed by idle schedulers, each
+// holding a reserved pod and an outstanding lease but no queued requests.
+func BenchmarkIdleCPU(b *testing.B) {
+	const devices = 16
+
+	for i := 0; i < devices; i++ {
+		s := startScheduler(fmt.Sprintf("device%d", i), SchedulerConfig{WindowDuration: time.Minute, EvictionPeriod: time.Minute}, &fairSharePolicy{})
+		defer s.Stop()
+
+		_, _ = s.ReservePodQuota(&PodQuota{PodId: "pod", Requests: 500, Limit: 1000})
+		<-enqueue(s, "pod").Response
+	}
+
+	cpuStart := processCPUTime()
+	wallStart := time.Now()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	b.StopTimer()
+
+	cpu := processCPUTime() - cpuStart
+	b.ReportMetric(cpu.Seconds()/time.Since(wallStart).Seconds(), "cpu-cores")
+}
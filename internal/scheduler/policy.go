@@ -0,0 +1,251 @@
+package scheduler
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/zbsss/device-manager/pkg/quota"
+)
+
+const (
+	PolicyFairShare = "fair-share"
+	PolicyFIFO      = "fifo"
+	PolicyPriority  = "priority"
+	PolicyLottery   = "lottery"
+	PolicyStride    = "stride"
+)
+
+// SchedulingPolicy decides which of the waiting pods is granted the token next.
+// Pods which have reached their limit are filtered out by the scheduler before
+// the policy is consulted. Policies are called with the scheduler lock held.
+type SchedulingPolicy interface {
+	// Pick returns the index in candidates of the request to grant. Candidates
+	// are ordered by arrival and there is always at least one.
+	Pick(candidates []*TokenLeaseRequest, state *SchedulingState) int
+
+	// LeaseEnded is called whenever a pod stops holding the token.
+	LeaseEnded(entry LeaseHistoryEntry, podQuota *PodQuota)
+	// PodRemoved is called when the quota of a pod is unreserved.
+	PodRemoved(podId string)
+}
+
+// SchedulingState is the view of the scheduler a policy bases its decision on.
+type SchedulingState struct {
+	PodQuota map[string]*PodQuota
+	// UsedQuota is the share of the current window each pod held the token for.
+	UsedQuota map[string]float64
+	// GroupWeights split the device between the groups of pods, groups which
+	// are not listed have weight 1.
+	GroupWeights map[string]float64
+	// Now is the time of the decision.
+	Now time.Time
+	// AgingPeriod is how long a request waits to be raised by one priority
+	// level, zero disables aging.
+	AgingPeriod time.Duration
+}
+
+// effectivePriority is the priority of the pod raised by one level for every
+// aging period the request has been waiting, so that pods with low priority
+// are not starved by a steady stream of high priority ones.
+func (s *SchedulingState) effectivePriority(req *TokenLeaseRequest) int64 {
+	priority := int64(s.PodQuota[req.PodId].Priority)
+	if s.AgingPeriod > 0 {
+		priority += int64(s.Now.Sub(req.EnqueuedAt) / s.AgingPeriod)
+	}
+	return priority
+}
+
+func (s *SchedulingState) groupWeight(group string) float64 {
+	if weight, ok := s.GroupWeights[group]; ok {
+		return weight
+	}
+	return 1
+}
+
+var schedulingPolicies = map[string]func() SchedulingPolicy{
+	PolicyFairShare: func() SchedulingPolicy { return &fairSharePolicy{} },
+	PolicyFIFO:      func() SchedulingPolicy { return &fifoPolicy{} },
+	PolicyPriority:  func() SchedulingPolicy { return &priorityPolicy{} },
+	PolicyLottery: func() SchedulingPolicy {
+		return &lotteryPolicy{rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+	},
+	PolicyStride: func() SchedulingPolicy { return &stridePolicy{pass: map[string]float64{}} },
+}
+
+// validateGroupWeights rejects weights which cannot split the device.
+func validateGroupWeights(weights map[string]float64) error {
+	for group, weight := range weights {
+		if weight <= 0 {
+			return fmt.Errorf("group %s: weight must be positive", group)
+		}
+	}
+	return nil
+}
+
+// NewSchedulingPolicy creates a fresh instance of the policy with the given name.
+func NewSchedulingPolicy(name string) (SchedulingPolicy, error) {
+	newPolicy, ok := schedulingPolicies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown scheduling policy %q, available: %v", name, SchedulingPolicies())
+	}
+	return newPolicy(), nil
+}
+
+// SchedulingPolicies returns the names of all available policies.
+func SchedulingPolicies() []string {
+	names := make([]string, 0, len(schedulingPolicies))
+	for name := range schedulingPolicies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// preemptivePolicy is implemented by policies which serve pods by their
+// priority, only they let a waiting pod preempt a lease of lower priority.
+type preemptivePolicy interface {
+	preemptive()
+}
+
+// statelessPolicy can be embedded by policies which only look at SchedulingState.
+type statelessPolicy struct{}
+
+func (statelessPolicy) LeaseEnded(LeaseHistoryEntry, *PodQuota) {}
+func (statelessPolicy) PodRemoved(string)                       {}
+
+// fairSharePolicy splits the device time between groups of pods by their
+// weight first and between the pods of a group by their requested quota, so
+// that a group does not get a bigger share by starting more pods. On both
+// levels the lowest used/weight ratio over the sliding window is served next.
+type fairSharePolicy struct {
+	statelessPolicy
+}
+
+func (p *fairSharePolicy) Pick(candidates []*TokenLeaseRequest, state *SchedulingState) int {
+	// the group holds what all of its pods used, not only the waiting ones
+	groupUsed := map[string]float64{}
+	for podId, podQuota := range state.PodQuota {
+		groupUsed[podQuota.GroupName()] += state.UsedQuota[podId]
+	}
+
+	selected := 0
+	selectedGroupShare, selectedShare := math.MaxFloat64, math.MaxFloat64
+	for i, req := range candidates {
+		podQuota := state.PodQuota[req.PodId]
+		group := podQuota.GroupName()
+		groupShare := groupUsed[group] / state.groupWeight(group)
+		share := state.UsedQuota[req.PodId] / fairShareWeight(podQuota)
+
+		if groupShare < selectedGroupShare || (groupShare == selectedGroupShare && share < selectedShare) {
+			selected, selectedGroupShare, selectedShare = i, groupShare, share
+		}
+	}
+	return selected
+}
+
+// fairShareWeight is the requested quota of the pod, or its limit for pods
+// which only set a limit.
+func fairShareWeight(podQuota *PodQuota) float64 {
+	if podQuota.Requests > 0 {
+		return podQuota.Requests.Fraction()
+	}
+	return podQuota.Limit.Fraction()
+}
+
+// fifoPolicy grants the token in arrival order.
+type fifoPolicy struct {
+	statelessPolicy
+}
+
+func (p *fifoPolicy) Pick(candidates []*TokenLeaseRequest, state *SchedulingState) int {
+	return 0
+}
+
+// priorityPolicy always serves the pod with the highest effective priority
+// first, pods with equal priority are served in arrival order. It is the only
+// policy under which pods preempt leases of lower priority.
+type priorityPolicy struct {
+	statelessPolicy
+}
+
+func (p *priorityPolicy) preemptive() {}
+
+func (p *priorityPolicy) Pick(candidates []*TokenLeaseRequest, state *SchedulingState) int {
+	selected, selectedPriority := 0, state.effectivePriority(candidates[0])
+	for i, req := range candidates {
+		if priority := state.effectivePriority(req); priority > selectedPriority {
+			selected, selectedPriority = i, priority
+		}
+	}
+	return selected
+}
+
+// lotteryPolicy draws the next pod at random, each pod holds a number of
+// tickets proportional to its requested quota.
+type lotteryPolicy struct {
+	statelessPolicy
+	rand *rand.Rand
+}
+
+func (p *lotteryPolicy) Pick(candidates []*TokenLeaseRequest, state *SchedulingState) int {
+	var tickets quota.MilliShares
+	for _, req := range candidates {
+		tickets += state.PodQuota[req.PodId].Requests
+	}
+	if tickets <= 0 {
+		return p.rand.Intn(len(candidates))
+	}
+
+	winner := quota.MilliShares(p.rand.Int63n(int64(tickets)))
+	for i, req := range candidates {
+		winner -= state.PodQuota[req.PodId].Requests
+		if winner < 0 {
+			return i
+		}
+	}
+	return len(candidates) - 1
+}
+
+// stridePolicy is the deterministic counterpart of lottery scheduling. Every
+// pod advances its pass by the time it held the token divided by its requested
+// quota and the pod with the lowest pass is served next.
+type stridePolicy struct {
+	pass map[string]float64
+}
+
+func (p *stridePolicy) Pick(candidates []*TokenLeaseRequest, state *SchedulingState) int {
+	// pods joining late start from the lowest pass, so they cannot claim
+	// the time that passed before they were reserved
+	minPass := math.MaxFloat64
+	for _, pass := range p.pass {
+		minPass = math.Min(minPass, pass)
+	}
+	if minPass == math.MaxFloat64 {
+		minPass = 0
+	}
+
+	selected := 0
+	for i, req := range candidates {
+		if _, ok := p.pass[req.PodId]; !ok {
+			p.pass[req.PodId] = minPass
+		}
+		if p.pass[req.PodId] < p.pass[candidates[selected].PodId] {
+			selected = i
+		}
+	}
+	return selected
+}
+
+func (p *stridePolicy) LeaseEnded(entry LeaseHistoryEntry, podQuota *PodQuota) {
+	if podQuota == nil || podQuota.Requests <= 0 {
+		return
+	}
+	p.pass[entry.PodId] += entry.ReturnedAt.Sub(entry.LeasedAt).Seconds() / podQuota.Requests.Fraction()
+}
+
+func (p *stridePolicy) PodRemoved(podId string) {
+	delete(p.pass, podId)
+}
@@ -0,0 +1,143 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func policyTestState() ([]*TokenLeaseRequest, *SchedulingState) {
+	candidates := []*TokenLeaseRequest{{PodId: "a"}, {PodId: "b"}, {PodId: "c"}}
+	state := &SchedulingState{
+		PodQuota: map[string]*PodQuota{
+			"a": {PodId: "a", Requests: 200, Limit: 1000, Priority: 0},
+			"b": {PodId: "b", Requests: 500, Limit: 1000, Priority: 10},
+			"c": {PodId: "c", Requests: 300, Limit: 1000, Priority: 10},
+		},
+		UsedQuota: map[string]float64{"a": 0.1, "b": 0.4, "c": 0.1},
+	}
+	return candidates, state
+}
+
+func TestNewSchedulingPolicy(t *testing.T) {
+	for _, name := range SchedulingPolicies() {
+		policy, err := NewSchedulingPolicy(name)
+		assert.Nil(t, err, name)
+		assert.NotNil(t, policy, name)
+	}
+
+	_, err := NewSchedulingPolicy("round-robin")
+	assert.NotNil(t, err)
+}
+
+func TestFairSharePolicy(t *testing.T) {
+	candidates, state := policyTestState()
+
+	// c used 1/3 of its requests, a 1/2 and b 4/5
+	assert.Equal(t, 2, (&fairSharePolicy{}).Pick(candidates, state))
+}
+
+func TestFairSharePolicySplitsBetweenGroupsFirst(t *testing.T) {
+	candidates := []*TokenLeaseRequest{}
+	state := &SchedulingState{PodQuota: map[string]*PodQuota{}, UsedQuota: map[string]float64{}}
+	for _, podId := range []string{"many/a", "many/b", "many/c", "many/d", "one/a"} {
+		candidates = append(candidates, &TokenLeaseRequest{PodId: podId})
+		state.PodQuota[podId] = &PodQuota{PodId: podId, Requests: 100, Limit: 1000}
+		state.UsedQuota[podId] = 0.1
+	}
+	state.UsedQuota["one/a"] = 0.3
+
+	// every pod of many used less than one/a, but together they used more
+	assert.Equal(t, 4, (&fairSharePolicy{}).Pick(candidates, state))
+
+	// a group with weight 3 gets three times the share
+	state.GroupWeights = map[string]float64{"many": 3}
+	assert.Equal(t, 0, (&fairSharePolicy{}).Pick(candidates, state))
+
+	// inside the group the pod with the lowest used/requests ratio is served
+	state.PodQuota["many/c"].Requests = 200
+	assert.Equal(t, 2, (&fairSharePolicy{}).Pick(candidates, state))
+
+	// the group overrides the namespace
+	for _, podId := range []string{"many/a", "many/b", "many/c", "many/d"} {
+		state.PodQuota[podId].Group = "one"
+	}
+	state.GroupWeights = nil
+	assert.Equal(t, 2, (&fairSharePolicy{}).Pick(candidates, state))
+}
+
+func TestFIFOPolicy(t *testing.T) {
+	candidates, state := policyTestState()
+
+	assert.Equal(t, 0, (&fifoPolicy{}).Pick(candidates, state))
+}
+
+func TestPriorityPolicy(t *testing.T) {
+	candidates, state := policyTestState()
+
+	// b and c share the highest priority, b arrived first
+	assert.Equal(t, 1, (&priorityPolicy{}).Pick(candidates, state))
+
+	// a waited long enough to be raised above them
+	state.Now = time.Now()
+	state.AgingPeriod = time.Second
+	for _, req := range candidates {
+		req.EnqueuedAt = state.Now
+	}
+	candidates[0].EnqueuedAt = state.Now.Add(-11 * time.Second)
+	assert.Equal(t, 0, (&priorityPolicy{}).Pick(candidates, state))
+}
+
+func TestLotteryPolicy(t *testing.T) {
+	candidates, state := policyTestState()
+	policy, _ := NewSchedulingPolicy(PolicyLottery)
+
+	wins := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		wins[candidates[policy.Pick(candidates, state)].PodId]++
+	}
+
+	assert.InDelta(t, 2000, wins["a"], 300)
+	assert.InDelta(t, 5000, wins["b"], 300)
+	assert.InDelta(t, 3000, wins["c"], 300)
+}
+
+func TestStridePolicy(t *testing.T) {
+	candidates, state := policyTestState()
+	policy := &stridePolicy{pass: map[string]float64{}}
+
+	held := map[string]time.Duration{}
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		podId := candidates[policy.Pick(candidates, state)].PodId
+		held[podId] += time.Second
+		policy.LeaseEnded(LeaseHistoryEntry{PodId: podId, LeasedAt: now, ReturnedAt: now.Add(time.Second)}, state.PodQuota[podId])
+	}
+
+	assert.InDelta(t, 20, held["a"].Seconds(), 1)
+	assert.InDelta(t, 50, held["b"].Seconds(), 1)
+	assert.InDelta(t, 30, held["c"].Seconds(), 1)
+
+	policy.PodRemoved("a")
+	assert.NotContains(t, policy.pass, "a")
+}
+
+func TestSchedulerFactoryValidatesPolicies(t *testing.T) {
+	config := SchedulerConfig{
+		WindowDuration: time.Minute,
+		EvictionPeriod: time.Minute,
+		Policy:         PolicyFairShare,
+		DevicePolicies: map[string]string{"device": "unknown"},
+	}
+	_, err := NewSchedulerFactory(config)
+	assert.NotNil(t, err)
+
+	config.DevicePolicies["device"] = PolicyStride
+	sf, err := NewSchedulerFactory(config)
+	assert.Nil(t, err)
+
+	s := sf.StartScheduler("device").(*scheduler)
+	defer s.Stop()
+	assert.IsType(t, &stridePolicy{}, s.policy)
+}
@@ -1,23 +1,119 @@
 package scheduler
 
-import "time"
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/zbsss/device-manager/internal/admission"
+)
 
 type SchedulerFactory interface {
 	StartScheduler(deviceId string) Scheduler
+	// AdmissionPolicy is the admission policy of the device, its memory is
+	// admitted by the same policy as its requests.
+	AdmissionPolicy(deviceId string) admission.Policy
+}
+
+type SchedulerConfig struct {
+	// WindowDuration is the sliding window over which used quota is measured.
+	WindowDuration time.Duration
+	// EvictionPeriod is the lifetime of a lease, after which the pod holding it
+	// can be evicted if other pods are waiting.
+	EvictionPeriod time.Duration
+	// PreemptionGrace is how long a lease is protected from preemption by a
+	// higher priority pod under the priority policy, zero disables preemption.
+	PreemptionGrace time.Duration
+	// AgingPeriod is how long a request has to wait to be raised by one priority
+	// level under the priority policy, zero disables aging.
+	AgingPeriod time.Duration
+	// RevocationGrace is how long a pod watching its lease has to return the
+	// token after being notified, before it is evicted. Zero evicts right away.
+	RevocationGrace time.Duration
+
+	// Enforcer acts on pods which did not return the token in time.
+	Enforcer    Enforcer
+	Enforcement EnforcementConfig
+	// History records every lease, it is optional.
+	History LeaseHistoryRecorder
+	// Clock defaults to the wall clock.
+	Clock Clock
+
+	// Policy is used by all devices not listed in DevicePolicies.
+	Policy         string
+	DevicePolicies map[string]string
+	// GroupWeights split the device time between the groups of pods in the
+	// fair-share policy, groups which are not listed have weight 1.
+	GroupWeights map[string]float64
+
+	// Admission is used by all devices not listed in DeviceAdmission.
+	Admission       admission.Policy
+	DeviceAdmission map[string]admission.Policy
 }
 
 type schedulerFactory struct {
-	windowDuration time.Duration
-	evictionPeriod time.Duration
+	config SchedulerConfig
 }
 
-func NewSchedulerFactory(windowDuration, evictionPeriod time.Duration) *schedulerFactory {
-	return &schedulerFactory{
-		windowDuration: windowDuration,
-		evictionPeriod: evictionPeriod,
+// NewSchedulerFactory creates a factory which starts schedulers using the
+// default policy, unless a different one is set for the device.
+func NewSchedulerFactory(config SchedulerConfig) (*schedulerFactory, error) {
+	if _, err := NewSchedulingPolicy(config.Policy); err != nil {
+		return nil, err
+	}
+	for deviceId, devicePolicy := range config.DevicePolicies {
+		if _, err := NewSchedulingPolicy(devicePolicy); err != nil {
+			return nil, fmt.Errorf("device %s: %w", deviceId, err)
+		}
+	}
+	if err := validateGroupWeights(config.GroupWeights); err != nil {
+		return nil, err
+	}
+	if err := config.Admission.Validate(); err != nil {
+		return nil, err
+	}
+	for deviceId, policy := range config.DeviceAdmission {
+		if err := policy.Validate(); err != nil {
+			return nil, fmt.Errorf("device %s: %w", deviceId, err)
+		}
 	}
+	if config.Enforcement.Action != "" {
+		if _, err := ParseEnforcementAction(string(config.Enforcement.Action)); err != nil {
+			return nil, err
+		}
+	}
+	for deviceId, action := range config.Enforcement.DeviceActions {
+		if _, err := ParseEnforcementAction(string(action)); err != nil {
+			return nil, fmt.Errorf("device %s: %w", deviceId, err)
+		}
+	}
+	for priority, action := range config.Enforcement.PriorityActions {
+		if _, err := ParseEnforcementAction(string(action)); err != nil {
+			return nil, fmt.Errorf("priority %d: %w", priority, err)
+		}
+	}
+
+	return &schedulerFactory{config: config}, nil
 }
 
 func (sf *schedulerFactory) StartScheduler(deviceId string) Scheduler {
-	return startScheduler(deviceId, sf.windowDuration, sf.evictionPeriod)
+	policyName := sf.config.Policy
+	if devicePolicy, ok := sf.config.DevicePolicies[deviceId]; ok {
+		policyName = devicePolicy
+	}
+
+	// policy names are validated when the factory is created
+	policy, _ := NewSchedulingPolicy(policyName)
+	config := sf.config
+	config.Admission = sf.AdmissionPolicy(deviceId)
+	log.Printf("Starting scheduler for device %s with %s policy and %s admission", deviceId, policyName, config.Admission)
+
+	return startScheduler(deviceId, config, policy)
+}
+
+func (sf *schedulerFactory) AdmissionPolicy(deviceId string) admission.Policy {
+	if policy, ok := sf.config.DeviceAdmission[deviceId]; ok {
+		return policy
+	}
+	return sf.config.Admission
 }
@@ -1,97 +1,298 @@
 package scheduler
 
 import (
-	"encoding/json"
-	"fmt"
 	"log"
 	"math"
-	"os"
 	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/zbsss/device-manager/internal/admission"
+	"github.com/zbsss/device-manager/internal/podref"
 )
 
+// evictionRetryPeriod is how long the scheduler waits before retrying a failed
+// enforcement on a Pod holding an expired lease.
+var evictionRetryPeriod = time.Second
+
 type scheduler struct {
-	isRunning           atomic.Bool
-	lock                sync.RWMutex
-	deviceId            string
-	queue               []*TokenLeaseRequest
-	currentLease        *TokenLease
-	leaseHistory        []*LeaseHistoryEntry
-	leaseHistoryLogFile string
-	podQuota            map[string]*PodQuota
-	windowDuration      time.Duration
-	evictionPeriod      time.Duration
-}
-
-func startScheduler(deviceId string, windowDuration, evictionPeriod time.Duration) Scheduler {
-	s := &scheduler{
-		lock:         sync.RWMutex{},
-		deviceId:     deviceId,
-		queue:        []*TokenLeaseRequest{},
-		currentLease: nil,
-		leaseHistory: []*LeaseHistoryEntry{},
-		// TODO: when running in Pod we need some sidecar to upload logs to S3?
-		leaseHistoryLogFile: fmt.Sprintf("data/data-%s.json", time.Now().Format("2006-01-02-15-04-05")),
-		podQuota:            map[string]*PodQuota{},
-		windowDuration:      windowDuration,
-		evictionPeriod:      evictionPeriod,
-	}
+	isRunning       atomic.Bool
+	clock           Clock
+	wakeup          chan struct{}
+	done            chan struct{}
+	stopped         chan struct{}
+	lock            sync.RWMutex
+	deviceId        string
+	queue           []*TokenLeaseRequest
+	currentLease    *TokenLease
+	leaseHistory    []*LeaseHistoryEntry
+	history         LeaseHistoryRecorder
+	podQuota        map[string]*PodQuota
+	policy          SchedulingPolicy
+	groupWeights    map[string]float64
+	admission       admission.Policy
+	windowDuration  time.Duration
+	evictionPeriod  time.Duration
+	preemptionGrace time.Duration
+	agingPeriod     time.Duration
+	revocationGrace time.Duration
+	enforcer        Enforcer
+	enforcement     EnforcementConfig
+	penalties       map[string]time.Time
+	queueWaits      map[string][]queueWait
+	watchers        map[string]map[chan *LeaseNotice]struct{}
+	lastLeaseId     uint64
+	// enforcing is set while the action on the current lease is enforced.
+	enforcing bool
+	// enforcementRetryAt is when a failed enforcement on the current lease is retried.
+	enforcementRetryAt time.Time
+	// enforcements are the running enforcements, Stop waits for them.
+	enforcements sync.WaitGroup
+}
 
+func startScheduler(deviceId string, config SchedulerConfig, policy SchedulingPolicy) Scheduler {
+	s := newScheduler(deviceId, config, policy)
+
+	s.isRunning.Store(true)
 	go s.run()
 	return s
 }
 
+// newScheduler creates a scheduler which is not running, its state only
+// changes when tick is called.
+func newScheduler(deviceId string, config SchedulerConfig, policy SchedulingPolicy) *scheduler {
+	if config.Enforcer == nil {
+		config.Enforcer = NewEnforcer(nil)
+	}
+	if config.Clock == nil {
+		config.Clock = RealClock{}
+	}
+
+	return &scheduler{
+		clock:           config.Clock,
+		wakeup:          make(chan struct{}, 1),
+		done:            make(chan struct{}),
+		stopped:         make(chan struct{}),
+		lock:            sync.RWMutex{},
+		deviceId:        deviceId,
+		queue:           []*TokenLeaseRequest{},
+		currentLease:    nil,
+		leaseHistory:    []*LeaseHistoryEntry{},
+		history:         config.History,
+		podQuota:        map[string]*PodQuota{},
+		policy:          policy,
+		groupWeights:    config.GroupWeights,
+		admission:       config.Admission,
+		windowDuration:  config.WindowDuration,
+		evictionPeriod:  config.EvictionPeriod,
+		preemptionGrace: config.PreemptionGrace,
+		agingPeriod:     config.AgingPeriod,
+		revocationGrace: config.RevocationGrace,
+		enforcer:        config.Enforcer,
+		enforcement:     config.Enforcement,
+		penalties:       map[string]time.Time{},
+		queueWaits:      map[string][]queueWait{},
+		watchers:        map[string]map[chan *LeaseNotice]struct{}{},
+		lastLeaseId:     firstLeaseId(config.Clock.Now()),
+	}
+}
+
+// firstLeaseId starts lease ids at the microsecond the scheduler starts, no
+// scheduler grants a lease per microsecond, so ids keep growing across restarts.
+func firstLeaseId(now time.Time) uint64 {
+	if micros := now.UnixMicro(); micros > 0 {
+		return uint64(micros)
+	}
+	return 0
+}
+
+// notify wakes up the scheduling loop. It never blocks, pending wake-ups are
+// coalesced into one.
+func (s *scheduler) notify() {
+	select {
+	case s.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// run sleeps until something that can change the scheduling decision happens:
+// a request is enqueued, a lease is returned, a pod is unreserved, an
+// enforcement is done or the next deadline computed by tick passes.
 func (s *scheduler) run() {
-	s.isRunning.Store(true)
+	defer close(s.stopped)
+
+	for {
+		var timeout <-chan time.Time
+		var timer Timer
+
+		deadline, pending := s.tick()
+		if pending != nil {
+			s.enforcements.Add(1)
+			go func() {
+				defer s.enforcements.Done()
+				s.enforce(pending)
+			}()
+		}
+		if !deadline.IsZero() {
+			timer = s.clock.NewTimer(deadline.Sub(s.clock.Now()))
+			timeout = timer.C()
+		}
+
+		select {
+		case <-s.done:
+		case <-s.wakeup:
+		case <-timeout:
+		}
 
-	for s.isRunning.Load() {
-		s.tryScheduleLease()
-		s.tryTerminateExpiredLease()
+		if timer != nil {
+			timer.Stop()
+		}
+		if !s.isRunning.Load() {
+			return
+		}
 	}
 }
 
-func (s *scheduler) tryScheduleLease() {
+// tick runs a single scheduling round and returns the moment at which the
+// scheduler has to look at its state again, or zero time if only an external
+// event can change it, and the enforcement the caller has to run.
+func (s *scheduler) tick() (time.Time, *enforcement) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
+	retryAt, pending := s.tryTerminateExpiredLeaseNoLock()
+	limitReleasedAt := s.tryScheduleLeaseNoLock()
+
+	deadline := earliest(retryAt, limitReleasedAt)
+	if s.currentLease != nil && retryAt.IsZero() {
+		now := s.clock.Now()
+		if s.currentLease.ExpiresAt.After(now) && s.areOtherPodsInQueueNoLock(s.currentLease.PodId) {
+			deadline = earliest(deadline, s.currentLease.ExpiresAt)
+		}
+		if preemptibleAt := s.preemptibleAtNoLock(); preemptibleAt.After(now) && s.isHigherPriorityPodInQueueNoLock() {
+			deadline = earliest(deadline, preemptibleAt)
+		}
+	}
+
+	return deadline, pending
+}
+
+// tryScheduleLeaseNoLock grants the token to the next request in the queue.
+// If every queued pod has reached its limit it returns the moment at which the
+// first of them drops below it.
+func (s *scheduler) tryScheduleLeaseNoLock() time.Time {
 	if s.currentLease == nil && len(s.queue) > 0 {
 		// calculate used quota for each pod in current time window
 		usedQuotaPerPod := s.calculateUsedQuotaPerPod()
+		now := s.clock.Now()
 
-		// order by used/requested quota
-		sort.Slice(s.queue, func(i, j int) bool {
-			iUsed := usedQuotaPerPod[s.queue[i].PodId]
-			jUsed := usedQuotaPerPod[s.queue[j].PodId]
-			iQuota := iUsed / s.podQuota[s.queue[i].PodId].Requests
-			jQuota := jUsed / s.podQuota[s.queue[j].PodId].Requests
-			if iUsed >= s.podQuota[s.queue[i].PodId].Limit {
-				iQuota = math.MaxFloat64
-			}
-			if jUsed >= s.podQuota[s.queue[j].PodId].Limit {
-				jQuota = math.MaxFloat64
+		candidates, penaltyEndsAt := s.candidatesNoLock(s.queue, usedQuotaPerPod, now)
+		if len(candidates) == 0 {
+			log.Printf("None of the pods waiting for device %s can be granted the token\n", s.deviceId)
+			return earliest(penaltyEndsAt, s.limitReleasedAtNoLock(usedQuotaPerPod))
+		}
+
+		s.grantNoLock(s.pickNoLock(candidates, usedQuotaPerPod, now), now)
+	}
+
+	return time.Time{}
+}
+
+// candidatesNoLock returns the requests which can be granted the token and
+// the moment at which the first penalty of the others ends. Pods which reached
+// their limit have to wait for the window to slide and pods in the penalty box
+// until their penalty is over.
+func (s *scheduler) candidatesNoLock(requests []*TokenLeaseRequest, usedQuotaPerPod map[string]float64, now time.Time) ([]*TokenLeaseRequest, time.Time) {
+	penaltyEndsAt := time.Time{}
+	candidates := make([]*TokenLeaseRequest, 0, len(requests))
+	for _, req := range requests {
+		if penalty, ok := s.penalties[req.PodId]; ok {
+			if now.Before(penalty) {
+				penaltyEndsAt = earliest(penaltyEndsAt, penalty)
+				continue
 			}
-			return iQuota < jQuota
-		})
+			delete(s.penalties, req.PodId)
+		}
+
+		if usedQuotaPerPod[req.PodId] < s.podQuota[req.PodId].Limit.Fraction() {
+			candidates = append(candidates, req)
+		}
+	}
+	return candidates, penaltyEndsAt
+}
 
-		selected := s.queue[0]
+// pickNoLock returns the candidate the policy grants the token to.
+func (s *scheduler) pickNoLock(candidates []*TokenLeaseRequest, usedQuotaPerPod map[string]float64, now time.Time) *TokenLeaseRequest {
+	return candidates[s.policy.Pick(candidates, &SchedulingState{
+		PodQuota:     s.podQuota,
+		UsedQuota:    usedQuotaPerPod,
+		GroupWeights: s.groupWeights,
+		Now:          now,
+		AgingPeriod:  s.agingPeriod,
+	})]
+}
 
-		if usedQuotaPerPod[selected.PodId] >= s.podQuota[selected.PodId].Limit {
-			log.Printf("Pod %s has reached its quota limit\n", selected.PodId)
-			return
+// grantNoLock grants the token to the request and removes it from the queue.
+func (s *scheduler) grantNoLock(req *TokenLeaseRequest, now time.Time) *TokenLease {
+	s.lastLeaseId++
+	s.currentLease = &TokenLease{
+		Id:         s.lastLeaseId,
+		PodId:      req.PodId,
+		EnqueuedAt: req.EnqueuedAt,
+		LeasedAt:   now,
+		ExpiresAt:  now.Add(s.evictionPeriod),
+	}
+
+	s.recordQueueWaitNoLock(req.PodId, now, now.Sub(req.EnqueuedAt))
+	req.Response <- s.currentLease
+	s.removeRequestNoLock(req)
+
+	return s.currentLease
+}
+
+// limitReleasedAtNoLock returns the earliest moment at which one of the queued
+// pods falls below its limit as the window slides over its lease history.
+func (s *scheduler) limitReleasedAtNoLock(usedQuotaPerPod map[string]float64) time.Time {
+	releasedAt := time.Time{}
+
+	for _, req := range s.queue {
+		if usedQuotaPerPod[req.PodId] < s.podQuota[req.PodId].Limit.Fraction() {
+			continue
 		}
+		releasedAt = earliest(releasedAt, s.podLimitReleasedAtNoLock(req.PodId, usedQuotaPerPod[req.PodId]))
+	}
 
-		s.currentLease = &TokenLease{
-			PodId:     selected.PodId,
-			ExpiresAt: time.Now().Add(s.evictionPeriod),
+	return releasedAt
+}
+
+// podLimitReleasedAtNoLock returns the moment at which the pod, which used
+// `used` of the window, falls below its limit.
+func (s *scheduler) podLimitReleasedAtNoLock(podId string, used float64) time.Time {
+	windowStart := s.clock.Now().Add(-s.windowDuration)
+	excess := time.Duration((used - s.podQuota[podId].Limit.Fraction()) * float64(s.windowDuration))
+
+	// history is ordered from the newest entry, the window drops the oldest first
+	for i := len(s.leaseHistory) - 1; i >= 0; i-- {
+		entry := s.leaseHistory[i]
+		if entry.PodId != podId || !entry.ReturnedAt.After(windowStart) {
+			continue
 		}
 
-		selected.Response <- s.currentLease
+		leasedAt := entry.LeasedAt
+		if leasedAt.Before(windowStart) {
+			leasedAt = windowStart
+		}
 
-		// remove `selected` from s.queue
-		s.queue = s.queue[1:]
+		held := entry.ReturnedAt.Sub(leasedAt)
+		if held > excess {
+			// one extra millisecond so that used quota is strictly below the limit
+			return leasedAt.Add(excess + s.windowDuration + time.Millisecond)
+		}
+		excess -= held
 	}
+
+	return time.Time{}
 }
 
 func (s *scheduler) areOtherPodsInQueueNoLock(podId string) bool {
@@ -104,34 +305,162 @@ func (s *scheduler) areOtherPodsInQueueNoLock(podId string) bool {
 	return false
 }
 
-func (s *scheduler) tryTerminateExpiredLease() {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+// isHigherPriorityPodInQueueNoLock checks if a pod with a higher priority than
+// the lease holder is waiting. Aging is not taken into account, it only orders
+// the queue and never makes a pod preempt another one.
+func (s *scheduler) isHigherPriorityPodInQueueNoLock() bool {
+	holder := s.podQuota[s.currentLease.PodId]
+	if holder == nil {
+		return false
+	}
 
+	for _, req := range s.queue {
+		if s.podQuota[req.PodId].Priority > holder.Priority {
+			return true
+		}
+	}
+
+	return false
+}
+
+// preemptibleAtNoLock returns the moment after which the current lease can be
+// preempted by a higher priority pod, or zero time if preemption is disabled
+// or the policy does not serve pods by priority.
+func (s *scheduler) preemptibleAtNoLock() time.Time {
+	if _, ok := s.policy.(preemptivePolicy); !ok || s.preemptionGrace <= 0 {
+		return time.Time{}
+	}
+	return s.currentLease.LeasedAt.Add(s.preemptionGrace)
+}
+
+// tryTerminateExpiredLeaseNoLock returns the enforcement terminating an
+// expired lease, or a lease held past the preemption grace period while a
+// higher priority pod is waiting. If the holder was given time to return the
+// token or the last enforcement failed it returns the moment at which to look
+// at the lease again.
+func (s *scheduler) tryTerminateExpiredLeaseNoLock() (time.Time, *enforcement) {
+	if s.currentLease == nil {
+		return time.Time{}, nil
+	}
+
+	now := s.clock.Now()
 	// only evict Pod if there are other Pods waiting in the queue
-	if s.currentLease != nil &&
-		time.Now().After(s.currentLease.ExpiresAt) &&
-		s.areOtherPodsInQueueNoLock(s.currentLease.PodId) {
-		log.Printf("Lease for pod %s has expired\n", s.currentLease.PodId)
-
-		err := evictPod(s.currentLease.PodId, "default")
-		if err != nil {
-			log.Printf("Failed to evict pod %s: %v\n", s.currentLease.PodId, err)
-			return
+	expired := !now.Before(s.currentLease.ExpiresAt) && s.areOtherPodsInQueueNoLock(s.currentLease.PodId)
+
+	preemptibleAt := s.preemptibleAtNoLock()
+	preempted := !preemptibleAt.IsZero() && !now.Before(preemptibleAt) && s.isHigherPriorityPodInQueueNoLock()
+
+	if (expired || preempted) && !s.currentLease.Enforced {
+		reason := "lease expired"
+		if !expired {
+			reason = "preempted by a higher priority pod"
 		}
 
-		// Once the Pod is deleted it will be garbage collected by the DeviceManager
-		log.Printf("Pod %s evicted\n", s.currentLease.PodId)
+		if s.currentLease.YieldBy.IsZero() {
+			log.Printf("Lease for pod %s has to be terminated: %s\n", s.currentLease.PodId, reason)
 
-		s.cancelLeaseNoLock()
+			// give cooperative clients a chance to return the token before enforcing
+			yieldBy := now.Add(s.revocationGrace)
+			if s.revocationGrace > 0 && s.sendNoticeNoLock(&LeaseNotice{PodId: s.currentLease.PodId, LeaseId: s.currentLease.Id, Type: NoticeRevoke, YieldBy: yieldBy, Reason: reason}) {
+				log.Printf("Asked pod %s to return the token by %s\n", s.currentLease.PodId, yieldBy.Format(time.RFC3339))
+				s.currentLease.YieldBy = yieldBy
+			}
+		}
+
+		if now.Before(s.currentLease.YieldBy) {
+			return s.currentLease.YieldBy, nil
+		}
+
+		if s.enforcing {
+			// enforce wakes the scheduler up once it is done
+			return time.Time{}, nil
+		}
+		if now.Before(s.enforcementRetryAt) {
+			return s.enforcementRetryAt, nil
+		}
+
+		var priority int32
+		pod := podref.ParseKey(s.currentLease.PodId)
+		if podQuota := s.podQuota[s.currentLease.PodId]; podQuota != nil {
+			priority = podQuota.Priority
+			pod.UID = podQuota.UID
+		}
+
+		s.enforcing = true
+		return time.Time{}, &enforcement{
+			lease:  s.currentLease,
+			action: s.enforcement.actionFor(s.deviceId, priority),
+			pod:    pod,
+			reason: reason,
+		}
 	}
+
+	return time.Time{}, nil
+}
+
+// enforcement is an action on the pod holding the token, decided on in a
+// scheduling round and enforced after the lock is released.
+type enforcement struct {
+	lease  *TokenLease
+	action EnforcementAction
+	pod    podref.Ref
+	reason string
+}
+
+// enforce applies the action without holding the lock, so that the calls of
+// the enforcer to the API server do not hold up token requests, and then ends
+// the lease if it was not returned in the meantime.
+func (s *scheduler) enforce(e *enforcement) {
+	err := s.enforcer.Enforce(e.action, e.pod, e.reason)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	defer s.notify()
+
+	s.enforcing = false
+	if s.currentLease != e.lease {
+		return
+	}
+
+	if err != nil {
+		log.Printf("Failed to enforce %s on pod %s: %v\n", e.action, e.lease.PodId, err)
+		s.enforcementRetryAt = s.clock.Now().Add(evictionRetryPeriod)
+		return
+	}
+
+	if !e.action.TerminatesLease() {
+		// dry run, the pod keeps the token until it returns it
+		e.lease.Enforced = true
+		return
+	}
+
+	if e.action == ActionRevoke {
+		s.penalties[e.lease.PodId] = s.clock.Now().Add(s.enforcement.PenaltyDuration)
+	}
+
+	// Evicted or deleted Pods will be garbage collected by the DeviceManager
+	s.cancelLeaseNoLock(e.action.endReason())
+}
+
+// sendNoticeNoLock delivers the notice to every watcher of the pod. It reports
+// whether the pod has any watchers.
+func (s *scheduler) sendNoticeNoLock(notice *LeaseNotice) bool {
+	for watcher := range s.watchers[notice.PodId] {
+		select {
+		case watcher <- notice:
+		default:
+			log.Printf("Watcher of pod %s is not keeping up, dropping notice\n", notice.PodId)
+		}
+	}
+
+	return len(s.watchers[notice.PodId]) > 0
 }
 
 func (s *scheduler) calculateUsedQuotaPerPod() map[string]float64 {
 	hist := []*LeaseHistoryEntry{}
 	leaseDurationPerPod := map[string]time.Duration{}
 
-	windowStart := time.Now().Add(-s.windowDuration)
+	windowStart := s.clock.Now().Add(-s.windowDuration)
 
 	for _, entry := range s.leaseHistory {
 		entry := entry
@@ -155,40 +484,78 @@ func (s *scheduler) calculateUsedQuotaPerPod() map[string]float64 {
 	return usedQuotaPerPod
 }
 
-func (s *scheduler) cancelLeaseNoLock() {
+func (s *scheduler) cancelLeaseNoLock(endReason string) {
 	newHistEntry := LeaseHistoryEntry{
+		DeviceId:   s.deviceId,
 		PodId:      s.currentLease.PodId,
-		LeasedAt:   s.currentLease.ExpiresAt.Add(-s.evictionPeriod),
-		ReturnedAt: time.Now(),
+		LeasedAt:   s.currentLease.LeasedAt,
+		ReturnedAt: s.clock.Now(),
+		EnqueuedAt: s.currentLease.EnqueuedAt,
+		EndReason:  endReason,
+	}
+	if podQuota, ok := s.podQuota[newHistEntry.PodId]; ok {
+		newHistEntry.Requests = podQuota.Requests.Fraction()
+		newHistEntry.Limit = podQuota.Limit.Fraction()
 	}
 
 	s.leaseHistory = append([]*LeaseHistoryEntry{&newHistEntry}, s.leaseHistory...)
+	s.policy.LeaseEnded(newHistEntry, s.podQuota[newHistEntry.PodId])
 
 	s.currentLease = nil
+	s.enforcementRetryAt = time.Time{}
+
+	if s.history != nil {
+		s.history.Record(newHistEntry)
+	}
+}
 
-	// go s.saveLeaseHistoryEntry(newHistEntry)
+// recordQueueWaitNoLock stores how long a granted request waited, dropping
+// waits of the pod which fell out of the window.
+func (s *scheduler) recordQueueWaitNoLock(podId string, grantedAt time.Time, wait time.Duration) {
+	s.queueWaits[podId] = append(s.pruneQueueWaitsNoLock(podId, grantedAt), queueWait{grantedAt: grantedAt, wait: wait})
 }
 
-func (s *scheduler) saveLeaseHistoryEntry(entry LeaseHistoryEntry) {
-	file, err := os.OpenFile(s.leaseHistoryLogFile, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
-	if err != nil {
-		log.Printf("Failed to open file: %s\n", err)
+func (s *scheduler) pruneQueueWaitsNoLock(podId string, now time.Time) []queueWait {
+	waits := s.queueWaits[podId]
+	windowStart := now.Add(-s.windowDuration)
+	for len(waits) > 0 && waits[0].grantedAt.Before(windowStart) {
+		waits = waits[1:]
 	}
-	defer file.Close()
+	return waits
+}
 
-	data, err := json.Marshal(entry)
-	if err != nil {
-		log.Printf("Failed to marshal entry: %s\n", err)
+func (s *scheduler) queueWaitStatsNoLock(podId string) QueueWaitStats {
+	waits := s.pruneQueueWaitsNoLock(podId, s.clock.Now())
+	s.queueWaits[podId] = waits
+	if len(waits) == 0 {
+		return QueueWaitStats{}
 	}
 
-	// TODO: refactor
-	_, err = file.Write(data)
-	if err != nil {
-		log.Printf("Failed to write entry: %s\n", err)
+	sorted := make([]time.Duration, 0, len(waits))
+	var total time.Duration
+	for _, w := range waits {
+		sorted = append(sorted, w.wait)
+		total += w.wait
 	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
 
-	// TODO: refactor
-	_, _ = file.WriteString("\n")
+	percentile := func(p float64) time.Duration {
+		return sorted[int(math.Ceil(p*float64(len(sorted))))-1]
+	}
 
-	log.Printf("Saved entry: %s\n", data)
+	return QueueWaitStats{
+		Count: len(sorted),
+		Mean:  total / time.Duration(len(sorted)),
+		P50:   percentile(0.5),
+		P95:   percentile(0.95),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+// earliest returns the earlier of two deadlines, zero time means no deadline.
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
 }
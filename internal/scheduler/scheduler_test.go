@@ -0,0 +1,487 @@
+package scheduler
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zbsss/device-manager/internal/leasehistory"
+	"github.com/zbsss/device-manager/pkg/quota"
+)
+
+func enqueue(s Scheduler, podId string) *TokenLeaseRequest {
+	req := &TokenLeaseRequest{
+		PodId:    podId,
+		Response: make(chan *TokenLease, 1),
+	}
+	if err := s.EnqueueLeaseRequest(req); err != nil {
+		panic(err)
+	}
+	return req
+}
+
+func reserve(t *testing.T, s Scheduler, podQuota *PodQuota) {
+	t.Helper()
+
+	_, err := s.ReservePodQuota(podQuota)
+	assert.Nil(t, err)
+}
+
+func awaitLease(t *testing.T, req *TokenLeaseRequest, timeout time.Duration) *TokenLease {
+	t.Helper()
+
+	select {
+	case lease := <-req.Response:
+		return lease
+	case <-time.After(timeout):
+		t.Fatalf("pod %s was not granted a lease within %v", req.PodId, timeout)
+		return nil
+	}
+}
+
+func TestSchedulerGrantsLeaseOnReturn(t *testing.T) {
+	s := startScheduler("device", SchedulerConfig{WindowDuration: time.Minute, EvictionPeriod: time.Minute}, &fairSharePolicy{})
+	defer s.Stop()
+
+	reserve(t, s, &PodQuota{PodId: "a", Requests: 500, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "b", Requests: 500, Limit: 1000})
+
+	lease := awaitLease(t, enqueue(s, "a"), time.Second)
+	assert.Equal(t, "a", lease.PodId)
+
+	waiting := enqueue(s, "b")
+	select {
+	case <-waiting.Response:
+		t.Fatal("lease granted while another pod holds the token")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// a return without lease id does not end the lease
+	assert.NotNil(t, s.ReturnLease(&TokenLease{PodId: "a"}))
+	assert.Len(t, waiting.Response, 0)
+
+	assert.Nil(t, s.ReturnLease(lease))
+	lease = awaitLease(t, waiting, time.Second)
+	assert.Equal(t, "b", lease.PodId)
+}
+
+func TestSchedulerCancelsLeaseRequest(t *testing.T) {
+	s := startScheduler("device", SchedulerConfig{WindowDuration: time.Minute, EvictionPeriod: time.Minute}, &fairSharePolicy{})
+	defer s.Stop()
+
+	reserve(t, s, &PodQuota{PodId: "a", Requests: 300, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "b", Requests: 300, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "c", Requests: 300, Limit: 1000})
+
+	held := awaitLease(t, enqueue(s, "a"), time.Second)
+	cancelled := enqueue(s, "b")
+	waiting := enqueue(s, "c")
+	s.CancelLeaseRequest(cancelled)
+
+	assert.Nil(t, s.ReturnLease(held))
+	lease := awaitLease(t, waiting, time.Second)
+	assert.Equal(t, "c", lease.PodId)
+	assert.Len(t, cancelled.Response, 0)
+
+	// a lease granted to a cancelled request is returned
+	abandoned := enqueue(s, "b")
+	assert.Nil(t, s.ReturnLease(lease))
+	for len(abandoned.Response) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	s.CancelLeaseRequest(abandoned)
+	assert.Equal(t, "a", awaitLease(t, enqueue(s, "a"), time.Second).PodId)
+}
+
+func TestSchedulerTryLease(t *testing.T) {
+	s := startScheduler("device", SchedulerConfig{WindowDuration: time.Minute, EvictionPeriod: time.Minute}, &fairSharePolicy{})
+	defer s.Stop()
+
+	reserve(t, s, &PodQuota{PodId: "a", Requests: 300, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "b", Requests: 300, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "c", Requests: 300, Limit: 1000})
+
+	_, _, err := s.TryLease("unknown")
+	assert.NotNil(t, err)
+	assert.NotNil(t, s.EnqueueLeaseRequest(&TokenLeaseRequest{PodId: "unknown", Response: make(chan *TokenLease, 1)}))
+
+	lease, estimate, err := s.TryLease("a")
+	assert.Nil(t, err)
+	assert.Nil(t, estimate)
+	assert.Equal(t, "a", lease.PodId)
+
+	enqueue(s, "b")
+	lease, estimate, err = s.TryLease("c")
+	assert.Nil(t, err)
+	assert.Nil(t, lease)
+	assert.Equal(t, 2, estimate.Position)
+	// the lease of a expires in a minute, b holds the token for as long as an
+	// eviction period without history
+	assert.InDelta(t, 2*time.Minute, estimate.Wait, float64(time.Second))
+
+	usage, err := s.GetPodUsage("c")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, usage.QueuedRequests)
+}
+
+func TestSchedulerUnreservePodQuota(t *testing.T) {
+	s := newScheduler("device", SchedulerConfig{WindowDuration: time.Minute, EvictionPeriod: time.Minute}, &fifoPolicy{})
+
+	reserve(t, s, &PodQuota{PodId: "a", Requests: 300, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "b", Requests: 300, Limit: 1000})
+
+	leased := enqueue(s, "a")
+	s.tick()
+	awaitLease(t, leased, time.Second)
+
+	// both requests of a have to leave the queue
+	first := enqueue(s, "a")
+	second := enqueue(s, "a")
+	waiting := enqueue(s, "b")
+	s.UnreservePodQuota("a")
+
+	for _, req := range []*TokenLeaseRequest{first, second} {
+		_, ok := <-req.Response
+		assert.False(t, ok)
+	}
+	assert.Equal(t, []*TokenLeaseRequest{waiting}, s.queue)
+	assert.Len(t, s.leaseHistory, 1)
+	assert.Equal(t, leasehistory.EndUnreserved, s.leaseHistory[0].EndReason)
+	assert.Equal(t, 0.3, s.leaseHistory[0].Requests)
+
+	s.tick()
+	assert.Equal(t, "b", awaitLease(t, waiting, time.Second).PodId)
+}
+
+func TestSchedulerTryLeaseOnlyGrantsCaller(t *testing.T) {
+	s := newScheduler("device", SchedulerConfig{WindowDuration: time.Minute, EvictionPeriod: time.Minute}, &fifoPolicy{})
+
+	reserve(t, s, &PodQuota{PodId: "a", Requests: 300, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "b", Requests: 300, Limit: 1000})
+
+	// b is queued, but not scheduled yet
+	waiting := enqueue(s, "b")
+	lease, estimate, err := s.TryLease("a")
+	assert.Nil(t, err)
+	assert.Nil(t, lease)
+	assert.Equal(t, 2, estimate.Position)
+	assert.Len(t, waiting.Response, 0)
+	assert.Nil(t, s.currentLease)
+	assert.Len(t, s.queue, 1)
+
+	s.tick()
+	assert.Equal(t, "b", awaitLease(t, waiting, time.Second).PodId)
+}
+
+func TestSchedulerPrefersPodWithLowerUsedShare(t *testing.T) {
+	s := startScheduler("device", SchedulerConfig{WindowDuration: time.Minute, EvictionPeriod: time.Minute}, &fairSharePolicy{})
+	defer s.Stop()
+
+	reserve(t, s, &PodQuota{PodId: "a", Requests: 500, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "b", Requests: 500, Limit: 1000})
+
+	held := awaitLease(t, enqueue(s, "a"), time.Second)
+	time.Sleep(20 * time.Millisecond)
+
+	first := enqueue(s, "a")
+	second := enqueue(s, "b")
+	assert.Nil(t, s.ReturnLease(held))
+
+	lease := awaitLease(t, second, time.Second)
+	assert.Equal(t, "b", lease.PodId)
+	assert.Len(t, first.Response, 0)
+}
+
+func TestSchedulerTerminatesExpiredLeaseWhenOthersWait(t *testing.T) {
+	s := startScheduler("device", SchedulerConfig{WindowDuration: time.Minute, EvictionPeriod: 50 * time.Millisecond}, &fairSharePolicy{})
+	defer s.Stop()
+
+	reserve(t, s, &PodQuota{PodId: "a", Requests: 500, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "b", Requests: 500, Limit: 1000})
+
+	awaitLease(t, enqueue(s, "a"), time.Second)
+
+	// nobody returns the token, the scheduler has to wake up on lease expiry
+	lease := awaitLease(t, enqueue(s, "b"), time.Second)
+	assert.Equal(t, "b", lease.PodId)
+}
+
+func TestSchedulerWaitsUntilPodDropsBelowLimit(t *testing.T) {
+	window := 200 * time.Millisecond
+	s := startScheduler("device", SchedulerConfig{WindowDuration: window, EvictionPeriod: time.Minute}, &fairSharePolicy{})
+	defer s.Stop()
+
+	reserve(t, s, &PodQuota{PodId: "a", Requests: 100, Limit: 250})
+
+	held := awaitLease(t, enqueue(s, "a"), time.Second)
+	time.Sleep(window / 2)
+	assert.Nil(t, s.ReturnLease(held))
+
+	start := time.Now()
+	awaitLease(t, enqueue(s, "a"), time.Second)
+
+	assert.GreaterOrEqual(t, time.Since(start), window/4)
+}
+
+func TestSchedulerServesHigherPriorityFirst(t *testing.T) {
+	s := startScheduler("device", SchedulerConfig{WindowDuration: time.Minute, EvictionPeriod: time.Minute}, &priorityPolicy{})
+	defer s.Stop()
+
+	reserve(t, s, &PodQuota{PodId: "holder", Requests: 200, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "batch", Requests: 400, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "inference", Requests: 400, Limit: 1000, Priority: 10})
+
+	held := awaitLease(t, enqueue(s, "holder"), time.Second)
+	batch := enqueue(s, "batch")
+	inference := enqueue(s, "inference")
+	assert.Nil(t, s.ReturnLease(held))
+
+	lease := awaitLease(t, inference, time.Second)
+	assert.Equal(t, "inference", lease.PodId)
+	assert.Len(t, batch.Response, 0)
+}
+
+func TestSchedulerPreemptsLowerPriorityLease(t *testing.T) {
+	config := SchedulerConfig{
+		WindowDuration:  time.Minute,
+		EvictionPeriod:  time.Minute,
+		PreemptionGrace: 50 * time.Millisecond,
+	}
+	s := startScheduler("device", config, &priorityPolicy{})
+	defer s.Stop()
+
+	reserve(t, s, &PodQuota{PodId: "batch", Requests: 500, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "inference", Requests: 500, Limit: 1000, Priority: 10})
+
+	awaitLease(t, enqueue(s, "batch"), time.Second)
+
+	start := time.Now()
+	lease := awaitLease(t, enqueue(s, "inference"), time.Second)
+	assert.Equal(t, "inference", lease.PodId)
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
+
+func TestSchedulerIgnoresPriorityOfOtherPolicies(t *testing.T) {
+	config := SchedulerConfig{
+		WindowDuration:  time.Minute,
+		EvictionPeriod:  time.Minute,
+		PreemptionGrace: 10 * time.Millisecond,
+	}
+	s := startScheduler("device", config, &fifoPolicy{})
+	defer s.Stop()
+
+	reserve(t, s, &PodQuota{PodId: "holder", Requests: 200, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "batch", Requests: 400, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "inference", Requests: 400, Limit: 1000, Priority: 10})
+
+	held := awaitLease(t, enqueue(s, "holder"), time.Second)
+	batch := enqueue(s, "batch")
+	inference := enqueue(s, "inference")
+
+	// the holder is not preempted and the queue is served in arrival order
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, inference.Response, 0)
+	assert.Nil(t, s.ReturnLease(held))
+
+	lease := awaitLease(t, batch, time.Second)
+	assert.Equal(t, "batch", lease.PodId)
+	assert.Len(t, inference.Response, 0)
+}
+
+func TestSchedulerAgingPreventsStarvation(t *testing.T) {
+	config := SchedulerConfig{
+		WindowDuration: time.Minute,
+		EvictionPeriod: time.Minute,
+		AgingPeriod:    20 * time.Millisecond,
+	}
+	s := startScheduler("device", config, &priorityPolicy{})
+	defer s.Stop()
+
+	reserve(t, s, &PodQuota{PodId: "holder", Requests: 200, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "batch", Requests: 400, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "inference", Requests: 400, Limit: 1000, Priority: 1})
+
+	held := awaitLease(t, enqueue(s, "holder"), time.Second)
+	batch := enqueue(s, "batch")
+	time.Sleep(70 * time.Millisecond)
+	enqueue(s, "inference")
+	assert.Nil(t, s.ReturnLease(held))
+
+	lease := awaitLease(t, batch, time.Second)
+	assert.Equal(t, "batch", lease.PodId)
+}
+
+func TestSchedulerRevokesWatchedLeaseBeforeEviction(t *testing.T) {
+	config := SchedulerConfig{
+		WindowDuration:  time.Minute,
+		EvictionPeriod:  20 * time.Millisecond,
+		RevocationGrace: time.Minute,
+	}
+	s := startScheduler("device", config, &fairSharePolicy{})
+	defer s.Stop()
+
+	reserve(t, s, &PodQuota{PodId: "a", Requests: 500, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "b", Requests: 500, Limit: 1000})
+
+	notices, cancel := s.WatchLease("a")
+	defer cancel()
+
+	held := awaitLease(t, enqueue(s, "a"), time.Second)
+	waiting := enqueue(s, "b")
+
+	select {
+	case notice := <-notices:
+		assert.Equal(t, "a", notice.PodId)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), notice.YieldBy, time.Second)
+	case <-time.After(time.Second):
+		t.Fatal("holder was not notified")
+	}
+
+	// the holder is given the grace period instead of being evicted
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, waiting.Response, 0)
+
+	assert.Nil(t, s.ReturnLease(held))
+	lease := awaitLease(t, waiting, time.Second)
+	assert.Equal(t, "b", lease.PodId)
+}
+
+func TestSchedulerEvictsWhenRevocationIsIgnored(t *testing.T) {
+	config := SchedulerConfig{
+		WindowDuration:  time.Minute,
+		EvictionPeriod:  20 * time.Millisecond,
+		RevocationGrace: 50 * time.Millisecond,
+	}
+	s := startScheduler("device", config, &fairSharePolicy{})
+	defer s.Stop()
+
+	reserve(t, s, &PodQuota{PodId: "a", Requests: 500, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "b", Requests: 500, Limit: 1000})
+
+	_, cancel := s.WatchLease("a")
+	defer cancel()
+
+	awaitLease(t, enqueue(s, "a"), time.Second)
+	lease := awaitLease(t, enqueue(s, "b"), time.Second)
+	assert.Equal(t, "b", lease.PodId)
+}
+
+func TestSchedulerAccountsQuotaExactly(t *testing.T) {
+	s := startScheduler("device", SchedulerConfig{WindowDuration: time.Minute, EvictionPeriod: time.Minute}, &fairSharePolicy{})
+	defer s.Stop()
+
+	// ten times 0.1 is not 1 in floating point
+	for i := 0; i < 10; i++ {
+		reserve(t, s, &PodQuota{PodId: fmt.Sprintf("ns/%d", i), Requests: 100, Limit: 1000})
+	}
+	assert.Equal(t, quota.MilliShares(0), s.GetAvailableQuota())
+	assert.Equal(t, quota.Device, s.GetNamespaceQuota("ns"))
+
+	_, err := s.ReservePodQuota(&PodQuota{PodId: "ns/full", Requests: 1, Limit: 1000})
+	assert.NotNil(t, err)
+}
+
+func TestSchedulerStopClosesWatchers(t *testing.T) {
+	s := startScheduler("device", SchedulerConfig{WindowDuration: time.Minute, EvictionPeriod: time.Minute}, &fairSharePolicy{})
+
+	notices, cancel := s.WatchLease("a")
+	s.Stop()
+	cancel()
+
+	_, ok := <-notices
+	assert.False(t, ok)
+}
+
+func TestSchedulerReportsPodUsage(t *testing.T) {
+	s := startScheduler("device", SchedulerConfig{WindowDuration: time.Minute, EvictionPeriod: time.Minute}, &fairSharePolicy{})
+	defer s.Stop()
+
+	reserve(t, s, &PodQuota{PodId: "a", Requests: 500, Limit: 1000, Priority: 2})
+	reserve(t, s, &PodQuota{PodId: "b", Requests: 500, Limit: 1000})
+
+	held := awaitLease(t, enqueue(s, "a"), time.Second)
+	waiting := enqueue(s, "b")
+
+	usage, err := s.GetPodUsage("b")
+	assert.Nil(t, err)
+	assert.False(t, usage.HoldsToken)
+	assert.Equal(t, 1, usage.QueuedRequests)
+	assert.Equal(t, int32(0), usage.Priority)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Nil(t, s.ReturnLease(held))
+	awaitLease(t, waiting, time.Second)
+
+	usage, err = s.GetPodUsage("a")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), usage.Priority)
+	assert.Equal(t, time.Minute, usage.WindowDuration)
+	assert.Greater(t, usage.Used, 0.0)
+	assert.Equal(t, 1, usage.QueueWait.Count)
+
+	usage, err = s.GetPodUsage("b")
+	assert.Nil(t, err)
+	assert.True(t, usage.HoldsToken)
+	assert.Equal(t, 0, usage.QueuedRequests)
+	assert.Equal(t, 1, usage.QueueWait.Count)
+	assert.GreaterOrEqual(t, usage.QueueWait.Max, 20*time.Millisecond)
+
+	_, err = s.GetPodUsage("c")
+	assert.NotNil(t, err)
+}
+
+func TestQueueWaitStats(t *testing.T) {
+	now := time.Now()
+	s := &scheduler{clock: NewVirtualClock(now), windowDuration: time.Minute, queueWaits: make(map[string][]queueWait)}
+
+	s.queueWaits["a"] = []queueWait{{grantedAt: now.Add(-2 * time.Minute), wait: time.Hour}}
+	for i := 1; i <= 20; i++ {
+		s.queueWaits["a"] = append(s.queueWaits["a"], queueWait{grantedAt: now, wait: time.Duration(i) * time.Millisecond})
+	}
+
+	stats := s.queueWaitStatsNoLock("a")
+	assert.Equal(t, 20, stats.Count)
+	assert.Equal(t, 10*time.Millisecond+500*time.Microsecond, stats.Mean)
+	assert.Equal(t, 10*time.Millisecond, stats.P50)
+	assert.Equal(t, 19*time.Millisecond, stats.P95)
+	assert.Equal(t, 20*time.Millisecond, stats.Max)
+
+	assert.Equal(t, QueueWaitStats{}, s.queueWaitStatsNoLock("b"))
+}
+
+func TestSchedulerStop(t *testing.T) {
+	s := startScheduler("device", SchedulerConfig{WindowDuration: time.Minute, EvictionPeriod: time.Minute}, &fairSharePolicy{}).(*scheduler)
+
+	s.Stop()
+	s.Stop()
+
+	assert.False(t, s.isRunning.Load())
+}
+
+// BenchmarkReturnToGrantLatency measures the time between a pod returning the
+// token and the next waiting pod receiving it.
+func BenchmarkReturnToGrantLatency(b *testing.B) {
+	s := startScheduler("device", SchedulerConfig{WindowDuration: time.Minute, EvictionPeriod: time.Minute}, &fairSharePolicy{})
+	defer s.Stop()
+
+	pods := []string{"a", "b"}
+	for _, podId := range pods {
+		_, _ = s.ReservePodQuota(&PodQuota{PodId: podId, Requests: 500, Limit: 1000})
+	}
+
+	holder := <-enqueue(s, pods[0]).Response
+
+	var total time.Duration
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		waiting := enqueue(s, pods[(i+1)%2])
+
+		start := time.Now()
+		_ = s.ReturnLease(holder)
+		holder = <-waiting.Response
+		total += time.Since(start)
+	}
+
+	b.ReportMetric(float64(total.Nanoseconds())/float64(b.N), "ns/grant")
+}
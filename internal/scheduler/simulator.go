@@ -0,0 +1,491 @@
+package scheduler
+
+import (
+	"container/heap"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/zbsss/device-manager/internal/podref"
+	"github.com/zbsss/device-manager/pkg/quota"
+)
+
+// SimulationStart is the virtual time at which every simulation starts.
+var SimulationStart = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// simulatedDevice is the device id of the simulated scheduler.
+const simulatedDevice = "simulation"
+
+const (
+	DistributionConstant    = "constant"
+	DistributionUniform     = "uniform"
+	DistributionExponential = "exponential"
+	DistributionNormal      = "normal"
+)
+
+// Distribution of kernel durations or of gaps between kernels.
+type Distribution struct {
+	// Kind is constant (the default), uniform, exponential or normal.
+	Kind string
+	Mean time.Duration
+	// StdDev is the standard deviation of the normal distribution.
+	StdDev time.Duration
+	// Min and Max bound uniform samples and clamp the other kinds, zero Max
+	// leaves them unbounded.
+	Min time.Duration
+	Max time.Duration
+}
+
+func (d Distribution) validate() error {
+	switch d.Kind {
+	case "", DistributionConstant, DistributionExponential, DistributionNormal:
+	case DistributionUniform:
+		if d.Max < d.Min {
+			return fmt.Errorf("uniform distribution needs max >= min")
+		}
+	default:
+		return fmt.Errorf("unknown distribution %q", d.Kind)
+	}
+	if d.Mean < 0 || d.StdDev < 0 || d.Min < 0 || d.Max < 0 {
+		return fmt.Errorf("distribution parameters cannot be negative")
+	}
+	return nil
+}
+
+func (d Distribution) sample(r *rand.Rand) time.Duration {
+	var value time.Duration
+	switch d.Kind {
+	case DistributionUniform:
+		value = d.Min + time.Duration(r.Int63n(int64(d.Max-d.Min)+1))
+	case DistributionExponential:
+		value = time.Duration(r.ExpFloat64() * float64(d.Mean))
+	case DistributionNormal:
+		value = d.Mean + time.Duration(r.NormFloat64()*float64(d.StdDev))
+	default:
+		value = d.Mean
+	}
+
+	if value < d.Min {
+		value = d.Min
+	}
+	if d.Max > 0 && value > d.Max {
+		value = d.Max
+	}
+	return value
+}
+
+// Workload is a simulated pod. Every kernel of the pod asks for the token,
+// holds it for the duration of the kernel and returns it.
+type Workload struct {
+	PodId    string
+	Requests float64
+	Limit    float64
+	Priority int32
+	// Group is the tenant group of the pod, see PodQuota.
+	Group string
+	// Start is when the pod reserves its quota, relative to the simulation start.
+	Start time.Duration
+
+	// Kernels replays recorded kernels, otherwise they are generated from the
+	// fields below.
+	Kernels []Kernel
+
+	// ArrivalRate is the number of kernels per second arriving as a Poisson
+	// process. Zero runs a closed loop, where the next kernel arrives Gap
+	// after the previous one ended.
+	ArrivalRate    float64
+	Gap            Distribution
+	KernelDuration Distribution
+	// KernelCount limits the number of generated kernels, zero is unlimited.
+	KernelCount int
+}
+
+// Kernel is a recorded kernel, Arrival is relative to the simulation start.
+type Kernel struct {
+	Arrival  time.Duration
+	Duration time.Duration
+}
+
+type SimulationConfig struct {
+	// Scheduler configures the simulated scheduler. Its Clock, Enforcer and
+	// History are replaced by the simulation, every enforced action takes the
+	// token away from the pod and the kernel holding it is terminated.
+	Scheduler SchedulerConfig
+	Duration  time.Duration
+	// Seed makes the generated workloads and the lottery policy reproducible.
+	Seed      int64
+	Workloads []Workload
+}
+
+type SimulationResult struct {
+	// History holds every lease which ended before the end of the simulation.
+	History []LeaseHistoryEntry
+	Pods    []PodResult
+}
+
+// PodResult counts the kernels of a simulated pod.
+type PodResult struct {
+	PodId     string
+	Completed int
+	// Terminated kernels lost the token before they ended.
+	Terminated int
+	// Pending kernels did not finish before the end of the simulation.
+	Pending int
+	// Rejected is set if the pod could not reserve its quota.
+	Rejected bool
+}
+
+// Simulate runs the scheduler against the workloads in virtual time. The same
+// config always gives the same result.
+func Simulate(config SimulationConfig) (*SimulationResult, error) {
+	policy, err := NewSchedulingPolicy(config.Scheduler.Policy)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateGroupWeights(config.Scheduler.GroupWeights); err != nil {
+		return nil, err
+	}
+	if lottery, ok := policy.(*lotteryPolicy); ok {
+		lottery.rand = rand.New(rand.NewSource(config.Seed))
+	}
+
+	sim := &simulation{
+		clock: NewVirtualClock(SimulationStart),
+		rand:  rand.New(rand.NewSource(config.Seed)),
+	}
+
+	podIds := map[string]bool{}
+	for _, workload := range config.Workloads {
+		if err := validateWorkload(workload); err != nil {
+			return nil, fmt.Errorf("pod %s: %w", workload.PodId, err)
+		}
+		if podIds[workload.PodId] {
+			return nil, fmt.Errorf("pod %s: defined more than once", workload.PodId)
+		}
+		podIds[workload.PodId] = true
+
+		pod := &simPod{Workload: workload, result: PodResult{PodId: workload.PodId}}
+		sim.pods = append(sim.pods, pod)
+		sim.push(SimulationStart.Add(workload.Start), podStarted, pod, 0)
+	}
+
+	schedulerConfig := config.Scheduler
+	schedulerConfig.Clock = sim.clock
+	schedulerConfig.Enforcer = simEnforcer{}
+	schedulerConfig.History = sim
+	sim.sch = newScheduler(simulatedDevice, schedulerConfig, policy)
+
+	sim.run(SimulationStart.Add(config.Duration))
+
+	result := &SimulationResult{History: sim.history}
+	for _, pod := range sim.pods {
+		pod.result.Pending = len(pod.pending)
+		if pod.lease != nil {
+			pod.result.Pending++
+		}
+		result.Pods = append(result.Pods, pod.result)
+	}
+
+	return result, nil
+}
+
+// WorkloadsFromHistory turns recorded leases into workloads replaying them,
+// pods without a recorded quota share the device equally.
+func WorkloadsFromHistory(entries []LeaseHistoryEntry) []Workload {
+	if len(entries) == 0 {
+		return []Workload{}
+	}
+
+	arrival := func(entry LeaseHistoryEntry) time.Time {
+		if entry.EnqueuedAt.IsZero() {
+			return entry.LeasedAt
+		}
+		return entry.EnqueuedAt
+	}
+
+	sorted := make([]LeaseHistoryEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool { return arrival(sorted[i]).Before(arrival(sorted[j])) })
+	start := arrival(sorted[0])
+
+	workloads := []Workload{}
+	index := map[string]int{}
+	for _, entry := range sorted {
+		i, ok := index[entry.PodId]
+		if !ok {
+			i = len(workloads)
+			index[entry.PodId] = i
+			workloads = append(workloads, Workload{PodId: entry.PodId, Start: arrival(entry).Sub(start)})
+		}
+
+		workload := &workloads[i]
+		workload.Kernels = append(workload.Kernels, Kernel{
+			Arrival:  arrival(entry).Sub(start),
+			Duration: entry.ReturnedAt.Sub(entry.LeasedAt),
+		})
+		if entry.Limit > 0 {
+			workload.Requests = entry.Requests
+			workload.Limit = entry.Limit
+		}
+	}
+
+	for i := range workloads {
+		if workloads[i].Limit <= 0 {
+			workloads[i].Requests = 1 / float64(len(workloads))
+			workloads[i].Limit = 1
+		}
+	}
+
+	return workloads
+}
+
+func validateWorkload(workload Workload) error {
+	if workload.PodId == "" {
+		return fmt.Errorf("pod id not specified")
+	}
+	if workload.Limit <= 0 || workload.Requests < 0 || workload.Start < 0 {
+		return fmt.Errorf("limit has to be positive, requests and start cannot be negative")
+	}
+	if workload.ArrivalRate < 0 || workload.KernelCount < 0 {
+		return fmt.Errorf("arrival rate and kernel count cannot be negative")
+	}
+	if err := workload.KernelDuration.validate(); err != nil {
+		return fmt.Errorf("kernel duration: %w", err)
+	}
+	if err := workload.Gap.validate(); err != nil {
+		return fmt.Errorf("gap: %w", err)
+	}
+	return nil
+}
+
+type simEventKind int
+
+const (
+	podStarted simEventKind = iota
+	kernelArrived
+	kernelDone
+)
+
+type simEvent struct {
+	at   time.Time
+	seq  int
+	kind simEventKind
+	pod  *simPod
+	// duration of a recorded kernel, or generation of the lease of a kernel
+	// which is done
+	value int64
+}
+
+// simEvents is a min-heap ordered by time, events at the same time are
+// handled in the order they were pushed.
+type simEvents []*simEvent
+
+func (e simEvents) Len() int { return len(e) }
+func (e simEvents) Less(i, j int) bool {
+	if e[i].at.Equal(e[j].at) {
+		return e[i].seq < e[j].seq
+	}
+	return e[i].at.Before(e[j].at)
+}
+func (e simEvents) Swap(i, j int)       { e[i], e[j] = e[j], e[i] }
+func (e *simEvents) Push(x interface{}) { *e = append(*e, x.(*simEvent)) }
+func (e *simEvents) Pop() interface{} {
+	old := *e
+	event := old[len(old)-1]
+	*e = old[:len(old)-1]
+	return event
+}
+
+type simPod struct {
+	Workload
+	result PodResult
+	// pending holds the durations of kernels waiting for the token
+	pending []time.Duration
+	request *TokenLeaseRequest
+	lease   *TokenLease
+	// generation invalidates the done event of a terminated kernel
+	generation int64
+	generated  int
+}
+
+type simulation struct {
+	clock   *VirtualClock
+	sch     *scheduler
+	rand    *rand.Rand
+	events  simEvents
+	seq     int
+	pods    []*simPod
+	history []LeaseHistoryEntry
+}
+
+// Record collects the history of the simulated scheduler.
+func (sim *simulation) Record(entry LeaseHistoryEntry) {
+	sim.history = append(sim.history, entry)
+}
+
+// simEnforcer accepts every action without a cluster.
+type simEnforcer struct{}
+
+func (simEnforcer) Enforce(action EnforcementAction, pod podref.Ref, reason string) error {
+	return nil
+}
+
+func (sim *simulation) push(at time.Time, kind simEventKind, pod *simPod, value int64) {
+	sim.seq++
+	heap.Push(&sim.events, &simEvent{at: at, seq: sim.seq, kind: kind, pod: pod, value: value})
+}
+
+// tick runs scheduling rounds until there is nothing to enforce, enforcements
+// take no simulated time.
+func (sim *simulation) tick() time.Time {
+	deadline, pending := sim.sch.tick()
+	for pending != nil {
+		sim.sch.enforce(pending)
+		deadline, pending = sim.sch.tick()
+	}
+	return deadline
+}
+
+// run alternates scheduling rounds with jumps to the next event or deadline
+// of the scheduler, until there are none left before `end`.
+func (sim *simulation) run(end time.Time) {
+	for {
+		deadline := sim.tick()
+		for sim.update() {
+			deadline = sim.tick()
+		}
+
+		now := sim.clock.Now()
+		next := deadline
+		if !next.IsZero() && !next.After(now) {
+			next = now.Add(time.Millisecond)
+		}
+		if len(sim.events) > 0 {
+			next = earliest(next, sim.events[0].at)
+		}
+		if next.IsZero() || next.After(end) {
+			sim.clock.Set(end)
+			return
+		}
+
+		sim.clock.Set(next)
+		for len(sim.events) > 0 && !sim.events[0].at.After(next) {
+			sim.handle(heap.Pop(&sim.events).(*simEvent))
+		}
+	}
+}
+
+func (sim *simulation) handle(event *simEvent) {
+	pod := event.pod
+	now := sim.clock.Now()
+
+	switch event.kind {
+	case podStarted:
+		_, err := sim.sch.ReservePodQuota(&PodQuota{
+			PodId:    pod.PodId,
+			Requests: quota.FromFraction(pod.Requests),
+			Limit:    quota.FromFraction(pod.Limit),
+			Priority: pod.Priority,
+			Group:    pod.Group,
+		})
+		if err != nil {
+			pod.result.Rejected = true
+			return
+		}
+
+		switch {
+		case len(pod.Kernels) > 0:
+			for _, kernel := range pod.Kernels {
+				sim.push(SimulationStart.Add(kernel.Arrival), kernelArrived, pod, int64(kernel.Duration))
+			}
+		case pod.ArrivalRate > 0:
+			sim.push(now.Add(sim.interarrival(pod)), kernelArrived, pod, -1)
+		default:
+			sim.push(now, kernelArrived, pod, -1)
+		}
+
+	case kernelArrived:
+		duration := time.Duration(event.value)
+		if event.value < 0 {
+			duration = pod.KernelDuration.sample(sim.rand)
+			pod.generated++
+			if pod.ArrivalRate > 0 && (pod.KernelCount == 0 || pod.generated < pod.KernelCount) {
+				sim.push(now.Add(sim.interarrival(pod)), kernelArrived, pod, -1)
+			}
+		}
+		pod.pending = append(pod.pending, duration)
+		sim.request(pod)
+
+	case kernelDone:
+		if pod.lease == nil || event.value != pod.generation {
+			return
+		}
+		sim.sch.ReturnLease(pod.lease)
+		pod.lease = nil
+		pod.result.Completed++
+		sim.kernelEnded(pod)
+		sim.request(pod)
+	}
+}
+
+// update hands granted leases to their pods and notices leases terminated by
+// the scheduler. It reports whether a new request was enqueued.
+func (sim *simulation) update() bool {
+	enqueued := false
+	now := sim.clock.Now()
+
+	for _, pod := range sim.pods {
+		if pod.lease != nil && sim.sch.currentLease != pod.lease {
+			pod.lease = nil
+			pod.generation++
+			pod.result.Terminated++
+			sim.kernelEnded(pod)
+			enqueued = sim.request(pod) || enqueued
+		}
+
+		if pod.request == nil {
+			continue
+		}
+		select {
+		case lease := <-pod.request.Response:
+			pod.request = nil
+			pod.lease = lease
+			duration := pod.pending[0]
+			pod.pending = pod.pending[1:]
+			sim.push(now.Add(duration), kernelDone, pod, pod.generation)
+		default:
+		}
+	}
+
+	return enqueued
+}
+
+// kernelEnded schedules the next kernel of a closed loop workload.
+func (sim *simulation) kernelEnded(pod *simPod) {
+	if len(pod.Kernels) > 0 || pod.ArrivalRate > 0 {
+		return
+	}
+	if pod.KernelCount == 0 || pod.generated < pod.KernelCount {
+		sim.push(sim.clock.Now().Add(pod.Gap.sample(sim.rand)), kernelArrived, pod, -1)
+	}
+}
+
+// request asks for the token if the pod has a kernel waiting and does not
+// hold or wait for the token already.
+func (sim *simulation) request(pod *simPod) bool {
+	if pod.request != nil || pod.lease != nil || len(pod.pending) == 0 {
+		return false
+	}
+
+	request := &TokenLeaseRequest{PodId: pod.PodId, Response: make(chan *TokenLease, 1)}
+	if err := sim.sch.EnqueueLeaseRequest(request); err != nil {
+		return false
+	}
+	pod.request = request
+	return true
+}
+
+func (sim *simulation) interarrival(pod *simPod) time.Duration {
+	return time.Duration(sim.rand.ExpFloat64() / pod.ArrivalRate * float64(time.Second))
+}
@@ -0,0 +1,226 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func usedPerPod(history []LeaseHistoryEntry) map[string]time.Duration {
+	used := map[string]time.Duration{}
+	for _, entry := range history {
+		used[entry.PodId] += entry.ReturnedAt.Sub(entry.LeasedAt)
+	}
+	return used
+}
+
+func TestVirtualClockFiresDueTimers(t *testing.T) {
+	clock := NewVirtualClock(SimulationStart)
+	early := clock.NewTimer(time.Second)
+	late := clock.NewTimer(time.Minute)
+	stopped := clock.NewTimer(time.Second)
+	assert.True(t, stopped.Stop())
+
+	clock.Advance(2 * time.Second)
+	assert.Equal(t, SimulationStart.Add(2*time.Second), clock.Now())
+	assert.Len(t, early.C(), 1)
+	assert.Len(t, late.C(), 0)
+	assert.Len(t, stopped.C(), 0)
+	assert.False(t, early.Stop())
+
+	// the clock never goes back
+	clock.Set(SimulationStart)
+	assert.Equal(t, SimulationStart.Add(2*time.Second), clock.Now())
+}
+
+func TestSchedulerRunsOnVirtualClock(t *testing.T) {
+	clock := NewVirtualClock(SimulationStart)
+	s := startScheduler("device", SchedulerConfig{WindowDuration: time.Minute, EvictionPeriod: time.Second, Clock: clock, Enforcer: simEnforcer{}}, &fifoPolicy{})
+	defer s.Stop()
+
+	reserve(t, s, &PodQuota{PodId: "a", Requests: 500, Limit: 1000})
+	reserve(t, s, &PodQuota{PodId: "b", Requests: 500, Limit: 1000})
+
+	lease := awaitLease(t, enqueue(s, "a"), time.Second)
+	assert.Equal(t, SimulationStart, lease.LeasedAt)
+	assert.Equal(t, SimulationStart.Add(time.Second), lease.ExpiresAt)
+
+	waiting := enqueue(s, "b")
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, waiting.Response, 0)
+
+	// the lease of a expires only once the virtual clock passes it
+	clock.Advance(time.Second)
+	lease = awaitLease(t, waiting, time.Second)
+	assert.Equal(t, SimulationStart.Add(time.Second), lease.LeasedAt)
+}
+
+func TestSimulateSharesDeviceBetweenNamespaces(t *testing.T) {
+	busy := Distribution{Kind: DistributionConstant, Mean: 100 * time.Millisecond}
+	workloads := []Workload{{PodId: "one/a", Requests: 0.1, Limit: 1, KernelDuration: busy}}
+	for _, podId := range []string{"many/a", "many/b", "many/c", "many/d"} {
+		workloads = append(workloads, Workload{PodId: podId, Requests: 0.1, Limit: 1, KernelDuration: busy})
+	}
+
+	for _, weight := range []float64{1, 3} {
+		result, err := Simulate(SimulationConfig{
+			Scheduler: SchedulerConfig{
+				WindowDuration: 10 * time.Second,
+				EvictionPeriod: time.Second,
+				Policy:         PolicyFairShare,
+				GroupWeights:   map[string]float64{"many": weight},
+			},
+			Duration:  time.Minute,
+			Workloads: workloads,
+		})
+		assert.Nil(t, err)
+
+		used := usedPerPod(result.History)
+		many := used["many/a"] + used["many/b"] + used["many/c"] + used["many/d"]
+		assert.InDelta(t, float64(time.Minute)/(weight+1), float64(used["one/a"]), float64(time.Second), "weight %v", weight)
+		assert.InDelta(t, float64(time.Minute)*weight/(weight+1), float64(many), float64(time.Second), "weight %v", weight)
+		assert.InDelta(t, used["many/a"], used["many/d"], float64(time.Second))
+	}
+
+	_, err := Simulate(SimulationConfig{
+		Scheduler: SchedulerConfig{Policy: PolicyFairShare, GroupWeights: map[string]float64{"many": 0}},
+		Duration:  time.Minute,
+		Workloads: workloads,
+	})
+	assert.NotNil(t, err)
+}
+
+func TestSimulateSharesDeviceFairly(t *testing.T) {
+	busy := Distribution{Kind: DistributionConstant, Mean: 100 * time.Millisecond}
+	result, err := Simulate(SimulationConfig{
+		Scheduler: SchedulerConfig{
+			WindowDuration: 10 * time.Second,
+			EvictionPeriod: time.Second,
+			Policy:         PolicyFairShare,
+		},
+		Duration: time.Minute,
+		Workloads: []Workload{
+			{PodId: "a", Requests: 0.5, Limit: 1, KernelDuration: busy},
+			{PodId: "b", Requests: 0.5, Limit: 1, KernelDuration: busy},
+		},
+	})
+	assert.Nil(t, err)
+
+	used := usedPerPod(result.History)
+	assert.InDelta(t, 30*time.Second, used["a"], float64(time.Second))
+	assert.InDelta(t, 30*time.Second, used["b"], float64(time.Second))
+	assert.Equal(t, 0, result.Pods[0].Terminated)
+	assert.Greater(t, result.Pods[0].Completed, 250)
+}
+
+func TestSimulateRespectsLimit(t *testing.T) {
+	result, err := Simulate(SimulationConfig{
+		Scheduler: SchedulerConfig{WindowDuration: 10 * time.Second, EvictionPeriod: time.Second, Policy: PolicyFIFO},
+		Duration:  time.Minute,
+		Workloads: []Workload{
+			{PodId: "a", Requests: 0.2, Limit: 0.25, KernelDuration: Distribution{Mean: 100 * time.Millisecond}},
+		},
+	})
+	assert.Nil(t, err)
+
+	// the pod is alone, but cannot use more than its limit
+	used := usedPerPod(result.History)
+	assert.InDelta(t, 15*time.Second, used["a"], float64(time.Second))
+}
+
+func TestSimulateTerminatesOverrunningKernels(t *testing.T) {
+	result, err := Simulate(SimulationConfig{
+		Scheduler: SchedulerConfig{
+			WindowDuration: time.Minute,
+			EvictionPeriod: time.Second,
+			Policy:         PolicyFIFO,
+			Enforcement:    EnforcementConfig{Action: ActionRevoke, PenaltyDuration: 5 * time.Second},
+		},
+		Duration: 20 * time.Second,
+		Workloads: []Workload{
+			{PodId: "long", Requests: 0.5, Limit: 1, KernelDuration: Distribution{Mean: 3 * time.Second}, KernelCount: 1},
+			{PodId: "short", Requests: 0.5, Limit: 1, Start: 100 * time.Millisecond, KernelDuration: Distribution{Mean: 100 * time.Millisecond}, KernelCount: 1},
+		},
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, PodResult{PodId: "long", Terminated: 1}, result.Pods[0])
+	assert.Equal(t, PodResult{PodId: "short", Completed: 1}, result.Pods[1])
+	assert.Len(t, result.History, 2)
+	assert.Equal(t, "revoked", result.History[0].EndReason)
+	assert.Equal(t, SimulationStart.Add(time.Second), result.History[0].ReturnedAt)
+}
+
+func TestSimulateIsDeterministic(t *testing.T) {
+	config := SimulationConfig{
+		Scheduler: SchedulerConfig{WindowDuration: 10 * time.Second, EvictionPeriod: time.Second, Policy: PolicyLottery},
+		Duration:  time.Minute,
+		Seed:      42,
+		Workloads: []Workload{
+			{PodId: "a", Requests: 0.3, Limit: 1, ArrivalRate: 5, KernelDuration: Distribution{Kind: DistributionExponential, Mean: 50 * time.Millisecond}},
+			{PodId: "b", Requests: 0.7, Limit: 1, Gap: Distribution{Kind: DistributionUniform, Max: 100 * time.Millisecond},
+				KernelDuration: Distribution{Kind: DistributionNormal, Mean: 80 * time.Millisecond, StdDev: 20 * time.Millisecond}},
+		},
+	}
+
+	first, err := Simulate(config)
+	assert.Nil(t, err)
+	second, err := Simulate(config)
+	assert.Nil(t, err)
+	assert.Equal(t, first, second)
+
+	config.Seed = 43
+	third, err := Simulate(config)
+	assert.Nil(t, err)
+	assert.NotEqual(t, first.History, third.History)
+}
+
+func TestSimulateReplaysHistory(t *testing.T) {
+	recorded := []LeaseHistoryEntry{
+		{PodId: "a", EnqueuedAt: SimulationStart, LeasedAt: SimulationStart, ReturnedAt: SimulationStart.Add(time.Second)},
+		{PodId: "b", EnqueuedAt: SimulationStart.Add(500 * time.Millisecond), LeasedAt: SimulationStart.Add(time.Second), ReturnedAt: SimulationStart.Add(2 * time.Second)},
+	}
+
+	workloads := WorkloadsFromHistory(recorded)
+	assert.Equal(t, []Workload{
+		{PodId: "a", Requests: 0.5, Limit: 1, Kernels: []Kernel{{Arrival: 0, Duration: time.Second}}},
+		{PodId: "b", Requests: 0.5, Limit: 1, Start: 500 * time.Millisecond, Kernels: []Kernel{{Arrival: 500 * time.Millisecond, Duration: time.Second}}},
+	}, workloads)
+
+	result, err := Simulate(SimulationConfig{
+		Scheduler: SchedulerConfig{WindowDuration: time.Minute, EvictionPeriod: time.Minute, Policy: PolicyFIFO},
+		Duration:  time.Minute,
+		Workloads: workloads,
+	})
+	assert.Nil(t, err)
+
+	assert.Len(t, result.History, 2)
+	for i := range recorded {
+		assert.Equal(t, recorded[i].EnqueuedAt, result.History[i].EnqueuedAt)
+		assert.Equal(t, recorded[i].LeasedAt, result.History[i].LeasedAt)
+		assert.Equal(t, recorded[i].ReturnedAt, result.History[i].ReturnedAt)
+	}
+}
+
+func TestSimulateValidatesWorkloads(t *testing.T) {
+	_, err := Simulate(SimulationConfig{Scheduler: SchedulerConfig{Policy: "unknown"}})
+	assert.NotNil(t, err)
+
+	for _, workload := range []Workload{
+		{},
+		{PodId: "a"},
+		{PodId: "a", Limit: 1, ArrivalRate: -1},
+		{PodId: "a", Limit: 1, KernelDuration: Distribution{Kind: "pareto"}},
+		{PodId: "a", Limit: 1, Gap: Distribution{Kind: DistributionUniform, Min: time.Second}},
+	} {
+		_, err := Simulate(SimulationConfig{Scheduler: SchedulerConfig{Policy: PolicyFIFO}, Workloads: []Workload{workload}})
+		assert.NotNil(t, err)
+	}
+
+	_, err = Simulate(SimulationConfig{
+		Scheduler: SchedulerConfig{Policy: PolicyFIFO},
+		Workloads: []Workload{{PodId: "a", Limit: 1}, {PodId: "a", Limit: 1}},
+	})
+	assert.NotNil(t, err)
+}
@@ -1,25 +1,119 @@
 package scheduler
 
-import "time"
+import (
+	"time"
+
+	"github.com/zbsss/device-manager/internal/leasehistory"
+	"github.com/zbsss/device-manager/internal/podref"
+	"github.com/zbsss/device-manager/pkg/quota"
+)
 
 type TokenLease struct {
-	PodId     string
-	ExpiresAt time.Time
+	// Id grows with every lease the scheduler grants, a lease is returned by
+	// its id so that a late return cannot end a newer lease of the same pod.
+	Id         uint64
+	PodId      string
+	EnqueuedAt time.Time
+	LeasedAt   time.Time
+	ExpiresAt  time.Time
+	// YieldBy is set once the holder was asked to return the token.
+	YieldBy time.Time
+	// Enforced is set once an action which leaves the lease to its holder was
+	// enforced, so that it is not repeated.
+	Enforced bool
+}
+
+type NoticeType string
+
+const (
+	// NoticeRevoke asks the pod holding the token to return it by YieldBy.
+	NoticeRevoke NoticeType = "revoke"
+	// NoticeMemoryPressure asks the pod to free memory down to MemoryBLimit,
+	// its memory quota was reduced below what it allocated.
+	NoticeMemoryPressure NoticeType = "memory-pressure"
+)
+
+// LeaseNotice is sent to the pods watching their lease.
+type LeaseNotice struct {
+	PodId string
+	// LeaseId is the lease a NoticeRevoke is about.
+	LeaseId uint64
+	Type    NoticeType
+	YieldBy time.Time
+	Reason  string
+
+	MemoryBLimit uint64
+	MemoryBUsed  uint64
 }
 
 type TokenLeaseRequest struct {
-	PodId    string
+	PodId string
+	// Response receives the lease, it is closed if the pod is unreserved. It
+	// needs a buffer of one, the scheduler does not wait for the receiver.
 	Response chan *TokenLease
+	// EnqueuedAt is set by the scheduler when the request is enqueued.
+	EnqueuedAt time.Time
+}
+
+// QueueEstimate is where a request for the token would wait in the queue.
+type QueueEstimate struct {
+	// Position is 1 for the next request to be granted the token.
+	Position int
+	Wait     time.Duration
 }
 
-type LeaseHistoryEntry struct {
-	PodId      string    `json:"podId"`
-	LeasedAt   time.Time `json:"leasedAt"`
-	ReturnedAt time.Time `json:"returnedAt"`
+type LeaseHistoryEntry = leasehistory.Entry
+
+// LeaseHistoryRecorder persists the leases of all devices.
+type LeaseHistoryRecorder interface {
+	Record(entry LeaseHistoryEntry)
 }
 
 type PodQuota struct {
-	PodId    string
-	Requests float64
-	Limit    float64
+	// PodId is the namespace/name key of the pod.
+	PodId string
+	// UID of the pod, empty if the client did not send it.
+	UID string
+	// Requests and Limit are shares of the device time.
+	Requests quota.MilliShares
+	Limit    quota.MilliShares
+	// Priority orders the queue, pods with higher priority are served first.
+	Priority int32
+	// Group is the tenant group the device time is split between before it is
+	// split between pods, the namespace of the pod if empty.
+	Group string
+}
+
+// GroupName is the group of the pod, its namespace unless Group is set.
+func (q *PodQuota) GroupName() string {
+	if q.Group != "" {
+		return q.Group
+	}
+	return podref.ParseKey(q.PodId).Namespace
+}
+
+// PodUsage is the state of a pod as seen by the scheduler.
+type PodUsage struct {
+	PodQuota
+	// Used is the share of the window the pod held the token for.
+	Used           float64
+	WindowDuration time.Duration
+	HoldsToken     bool
+	QueuedRequests int
+	QueueWait      QueueWaitStats
+}
+
+// QueueWaitStats summarizes how long the requests of a pod granted within the
+// window waited in the queue.
+type QueueWaitStats struct {
+	Count int
+	Mean  time.Duration
+	P50   time.Duration
+	P95   time.Duration
+	Max   time.Duration
+}
+
+type queueWait struct {
+	grantedAt time.Time
+	wait      time.Duration
 }
@@ -0,0 +1,228 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/zbsss/device-manager/pkg/quota"
+)
+
+type OpType string
+
+const (
+	OpRegisterDevice   OpType = "registerDevice"
+	OpDeregisterDevice OpType = "deregisterDevice"
+	OpReservePod       OpType = "reservePod"
+	OpUnreservePod     OpType = "unreservePod"
+	OpUpdatePod        OpType = "updatePod"
+	OpAllocateMemory   OpType = "allocateMemory"
+	OpFreeMemory       OpType = "freeMemory"
+)
+
+// Op is a single change of the device manager state, only the fields of its
+// type are set.
+type Op struct {
+	// Seq is assigned by the store when the op is appended.
+	Seq      uint64 `json:"seq"`
+	Type     OpType `json:"type"`
+	DeviceId string `json:"deviceId"`
+	// PodId and AllocatorPodId are namespace/name keys.
+	PodId  string `json:"podId,omitempty"`
+	PodUID string `json:"podUid,omitempty"`
+
+	AllocatorPodId  string `json:"allocatorPodId,omitempty"`
+	AllocatorPodUID string `json:"allocatorPodUid,omitempty"`
+	Vendor          string `json:"vendor,omitempty"`
+	Model           string `json:"model,omitempty"`
+
+	// Quotas are milli-shares of the device. Ops logged before quotas were
+	// milli-shares only have the Requests, Limit and Memory fractions.
+	RequestsMilli quota.MilliShares `json:"requestsMilli,omitempty"`
+	LimitMilli    quota.MilliShares `json:"limitMilli,omitempty"`
+	Requests      float64           `json:"requests,omitempty"`
+	Limit         float64           `json:"limit,omitempty"`
+	Priority      int32             `json:"priority,omitempty"`
+	Memory        float64           `json:"memory,omitempty"`
+	Group         string            `json:"group,omitempty"`
+
+	// MemoryB is the memory of a registered device, or the bytes reserved,
+	// allocated or freed by a pod. Reservations logged before they were in
+	// bytes only have the Memory fraction.
+	MemoryB uint64 `json:"memoryB,omitempty"`
+	// AllocationId is the id of the allocation allocated or freed by a pod,
+	// zero for allocations logged before they had ids.
+	AllocationId uint64 `json:"allocationId,omitempty"`
+}
+
+// State is everything the device manager needs to serve its clients after a restart.
+type State struct {
+	// Seq is the sequence number of the last applied op.
+	Seq     uint64             `json:"seq"`
+	Devices map[string]*Device `json:"devices"`
+}
+
+type Device struct {
+	Id              string          `json:"id"`
+	AllocatorPodId  string          `json:"allocatorPodId"`
+	AllocatorPodUID string          `json:"allocatorPodUid,omitempty"`
+	Vendor          string          `json:"vendor"`
+	Model           string          `json:"model"`
+	MemoryB         uint64          `json:"memoryB"`
+	Pods            map[string]*Pod `json:"pods"`
+	// LastAllocationId is the highest allocation id ever handed out on the
+	// device, ids are not reused.
+	LastAllocationId uint64 `json:"lastAllocationId,omitempty"`
+}
+
+type Pod struct {
+	Id            string            `json:"id"`
+	UID           string            `json:"uid,omitempty"`
+	RequestsMilli quota.MilliShares `json:"requestsMilli"`
+	LimitMilli    quota.MilliShares `json:"limitMilli"`
+	// Requests and Limit are the fractions of snapshots written before quotas
+	// were milli-shares, they are converted when the snapshot is read.
+	Requests     float64 `json:"requests,omitempty"`
+	Limit        float64 `json:"limit,omitempty"`
+	Priority     int32   `json:"priority"`
+	Memory       float64 `json:"memory"`
+	MemoryBLimit uint64  `json:"memoryBLimit,omitempty"`
+	MemoryBUsed  uint64  `json:"memoryBUsed"`
+	Group        string  `json:"group,omitempty"`
+	// Allocations maps the ids of outstanding allocations to their bytes, they
+	// add up to MemoryBUsed unless some were logged before they had ids.
+	Allocations map[uint64]uint64 `json:"allocations,omitempty"`
+}
+
+// matches checks if the pod is the one with the uid, an empty uid matches any.
+func (p *Pod) matches(uid string) bool {
+	return p.UID == "" || uid == "" || p.UID == uid
+}
+
+func NewState() *State {
+	return &State{Devices: map[string]*Device{}}
+}
+
+// quota returns the requests and limit of the op in milli-shares, the
+// fractions of older ops are rounded to the nearest milli-share.
+func (op Op) quota() (quota.MilliShares, quota.MilliShares) {
+	if op.RequestsMilli != 0 || op.LimitMilli != 0 {
+		return op.RequestsMilli, op.LimitMilli
+	}
+	return quota.FromFraction(op.Requests), quota.FromFraction(op.Limit)
+}
+
+// migrate converts the fractions of a snapshot written before quotas were
+// milli-shares.
+func (s *State) migrate() {
+	for _, device := range s.Devices {
+		for _, pod := range device.Pods {
+			if pod.RequestsMilli == 0 && pod.LimitMilli == 0 {
+				pod.RequestsMilli, pod.LimitMilli = quota.FromFraction(pod.Requests), quota.FromFraction(pod.Limit)
+			}
+			pod.Requests, pod.Limit = 0, 0
+		}
+	}
+}
+
+// Apply changes the state by the op. Ops are only logged after the device
+// manager accepted them, so an op which does not fit the state means the log
+// is corrupted.
+func (s *State) Apply(op Op) error {
+	if err := s.check(op); err != nil {
+		return err
+	}
+
+	device := s.Devices[op.DeviceId]
+	switch op.Type {
+	case OpRegisterDevice:
+		s.Devices[op.DeviceId] = &Device{
+			Id:              op.DeviceId,
+			AllocatorPodId:  op.AllocatorPodId,
+			AllocatorPodUID: op.AllocatorPodUID,
+			Vendor:          op.Vendor,
+			Model:           op.Model,
+			MemoryB:         op.MemoryB,
+			Pods:            map[string]*Pod{},
+		}
+	case OpDeregisterDevice:
+		delete(s.Devices, op.DeviceId)
+	case OpReservePod:
+		requests, limit := op.quota()
+		pod := &Pod{
+			Id:            op.PodId,
+			UID:           op.PodUID,
+			RequestsMilli: requests,
+			LimitMilli:    limit,
+			Priority:      op.Priority,
+			Memory:        op.Memory,
+			MemoryBLimit:  op.MemoryB,
+			Group:         op.Group,
+		}
+		// reserving a pod again keeps its allocations
+		if reserved := device.Pods[op.PodId]; reserved != nil && reserved.matches(op.PodUID) {
+			pod.MemoryBUsed, pod.Allocations = reserved.MemoryBUsed, reserved.Allocations
+			if pod.UID == "" {
+				pod.UID = reserved.UID
+			}
+		}
+		device.Pods[op.PodId] = pod
+	case OpUnreservePod:
+		delete(device.Pods, op.PodId)
+	case OpUpdatePod:
+		// the allocations of the pod are kept
+		pod := device.Pods[op.PodId]
+		pod.RequestsMilli, pod.LimitMilli = op.quota()
+		pod.Memory = op.Memory
+		pod.MemoryBLimit = op.MemoryB
+	case OpAllocateMemory:
+		pod := device.Pods[op.PodId]
+		pod.MemoryBUsed += op.MemoryB
+		if op.AllocationId != 0 {
+			if pod.Allocations == nil {
+				pod.Allocations = map[uint64]uint64{}
+			}
+			pod.Allocations[op.AllocationId] = op.MemoryB
+			if op.AllocationId > device.LastAllocationId {
+				device.LastAllocationId = op.AllocationId
+			}
+		}
+	case OpFreeMemory:
+		pod := device.Pods[op.PodId]
+		pod.MemoryBUsed -= op.MemoryB
+		delete(pod.Allocations, op.AllocationId)
+	}
+
+	s.Seq = op.Seq
+	return nil
+}
+
+// check reports whether the op can be applied to the state.
+func (s *State) check(op Op) error {
+	device := s.Devices[op.DeviceId]
+
+	switch op.Type {
+	case OpRegisterDevice:
+		return nil
+	case OpDeregisterDevice, OpReservePod, OpUnreservePod:
+		if device == nil {
+			return fmt.Errorf("op %d: device %s not registered", op.Seq, op.DeviceId)
+		}
+		if reserved := device.Pods[op.PodId]; op.Type == OpReservePod && op.MemoryB > 0 && reserved != nil && reserved.matches(op.PodUID) && op.MemoryB < reserved.MemoryBUsed {
+			return fmt.Errorf("op %d: pod %s reserves %d B, it allocated %d B", op.Seq, op.PodId, op.MemoryB, reserved.MemoryBUsed)
+		}
+		return nil
+	case OpUpdatePod, OpAllocateMemory, OpFreeMemory:
+		if device == nil || device.Pods[op.PodId] == nil {
+			return fmt.Errorf("op %d: pod %s not reserved on device %s", op.Seq, op.PodId, op.DeviceId)
+		}
+		pod := device.Pods[op.PodId]
+		if op.Type == OpFreeMemory && op.MemoryB > pod.MemoryBUsed {
+			return fmt.Errorf("op %d: pod %s frees %d B, it allocated %d B", op.Seq, op.PodId, op.MemoryB, pod.MemoryBUsed)
+		}
+		if op.Type == OpFreeMemory && op.AllocationId != 0 && pod.Allocations[op.AllocationId] != op.MemoryB {
+			return fmt.Errorf("op %d: pod %s has no allocation %d of %d B", op.Seq, op.PodId, op.AllocationId, op.MemoryB)
+		}
+		return nil
+	default:
+		return fmt.Errorf("op %d: unknown type %q", op.Seq, op.Type)
+	}
+}
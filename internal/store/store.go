@@ -0,0 +1,319 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	snapshotFile = "state.json"
+	walFile      = "wal.log"
+)
+
+type Config struct {
+	Dir string
+	// SnapshotEvery is the number of ops after which the state is written to
+	// a snapshot and the log is truncated, zero never does it.
+	SnapshotEvery int
+	// NoSync skips fsync, the log survives a crash of the process but not of
+	// the node.
+	NoSync bool
+	// SyncAllocations fsyncs memory allocations and frees as well. They are
+	// not synced by default, the clients holding them do not survive a crash
+	// of the node either, and they are synced with the next op that is.
+	SyncAllocations bool
+}
+
+// Store persists the device manager state as a snapshot and a write-ahead log
+// of the ops applied since. Every log record is a line with the CRC32 of the
+// op followed by the op as JSON.
+type Store struct {
+	lock          sync.Mutex
+	config        Config
+	state         *State
+	wal           *os.File
+	walSize       int64
+	sinceSnapshot int
+}
+
+// Open loads the snapshot and replays the log on top of it. A torn record at
+// the end of the log, left by a crash in the middle of an append, is dropped.
+func Open(config Config) (*Store, error) {
+	err := os.MkdirAll(config.Dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := readSnapshot(filepath.Join(config.Dir, snapshotFile))
+	if err != nil {
+		return nil, err
+	}
+
+	wal, err := os.OpenFile(filepath.Join(config.Dir, walFile), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	replayed, validBytes, err := replay(wal, state)
+	if err != nil {
+		wal.Close()
+		return nil, err
+	}
+
+	// drop the torn record, so that new records are appended after the last valid one
+	err = wal.Truncate(validBytes)
+	if err == nil {
+		_, err = wal.Seek(validBytes, io.SeekStart)
+	}
+	if err != nil {
+		wal.Close()
+		return nil, fmt.Errorf("failed to truncate %s: %w", wal.Name(), err)
+	}
+
+	return &Store{config: config, state: state, wal: wal, walSize: validBytes, sinceSnapshot: replayed}, nil
+}
+
+func readSnapshot(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewState(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state := NewState()
+	err = json.Unmarshal(data, state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	state.migrate()
+	return state, nil
+}
+
+// replay applies the records of the log which are newer than the state. It
+// returns the number of applied records and the length of the valid part of the log.
+func replay(wal *os.File, state *State) (int, int64, error) {
+	reader := bufio.NewReader(wal)
+	replayed := 0
+	var offset int64
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// a record without a newline was not fully written
+			return replayed, offset, nil
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+
+		op, recordErr := decodeRecord(line)
+		if recordErr != nil {
+			if _, peekErr := reader.Peek(1); peekErr == io.EOF {
+				return replayed, offset, nil
+			}
+			return 0, 0, fmt.Errorf("%s corrupted at offset %d: %w", wal.Name(), offset, recordErr)
+		}
+		offset += int64(len(line))
+
+		// records written before the snapshot are in it already
+		if op.Seq <= state.Seq {
+			continue
+		}
+		if err := state.Apply(op); err != nil {
+			return 0, 0, err
+		}
+		replayed++
+	}
+}
+
+func encodeRecord(op Op) ([]byte, error) {
+	data, err := json.Marshal(op)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf("%08x %s\n", crc32.ChecksumIEEE(data), data)), nil
+}
+
+func decodeRecord(line []byte) (Op, error) {
+	var op Op
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	if len(line) < 10 || line[8] != ' ' {
+		return op, fmt.Errorf("malformed record")
+	}
+
+	var checksum uint32
+	_, err := fmt.Sscanf(string(line[:8]), "%08x", &checksum)
+	if err != nil {
+		return op, fmt.Errorf("malformed checksum: %w", err)
+	}
+	data := line[9:]
+	if crc32.ChecksumIEEE(data) != checksum {
+		return op, fmt.Errorf("checksum mismatch")
+	}
+
+	err = json.Unmarshal(data, &op)
+	return op, err
+}
+
+// State returns a copy of the current state.
+func (s *Store) State() *State {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// the state only holds plain data, a JSON round trip is a deep copy
+	data, _ := json.Marshal(s.state)
+	state := NewState()
+	json.Unmarshal(data, state)
+	return state
+}
+
+// Append logs and applies the op, it is durable once Append returns nil and
+// neither logged nor applied otherwise.
+func (s *Store) Append(op Op) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	op.Seq = s.state.Seq + 1
+	record, err := encodeRecord(op)
+	if err != nil {
+		return err
+	}
+
+	// an op which cannot be replayed must not reach the log
+	if err := s.state.check(op); err != nil {
+		return err
+	}
+
+	_, err = s.wal.Write(record)
+	if err == nil && s.syncs(op) {
+		err = s.wal.Sync()
+	}
+	if err != nil {
+		// a partly written record would hide the records appended after it
+		s.truncateNoLock(s.walSize)
+		return fmt.Errorf("failed to write %s: %w", s.wal.Name(), err)
+	}
+
+	s.walSize += int64(len(record))
+	s.state.Apply(op)
+	s.sinceSnapshot++
+
+	if s.config.SnapshotEvery > 0 && s.sinceSnapshot >= s.config.SnapshotEvery {
+		// the op is durable in the log already, the snapshot is retried
+		// after the next op
+		if err := s.snapshotNoLock(); err != nil {
+			log.Printf("Failed to snapshot state: %v", err)
+		}
+	}
+	return nil
+}
+
+// syncs checks if the op is fsynced when it is appended.
+func (s *Store) syncs(op Op) bool {
+	if s.config.NoSync {
+		return false
+	}
+	return s.config.SyncAllocations || (op.Type != OpAllocateMemory && op.Type != OpFreeMemory)
+}
+
+// Snapshot writes the state and truncates the log.
+func (s *Store) Snapshot() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.snapshotNoLock()
+}
+
+func (s *Store) snapshotNoLock() error {
+	data, err := json.Marshal(s.state)
+	if err != nil {
+		return err
+	}
+
+	// the snapshot replaces the old one atomically, a crash before the log
+	// is truncated only replays records which are skipped by their seq
+	path := filepath.Join(s.config.Dir, snapshotFile)
+	err = writeFileAtomic(path, data, !s.config.NoSync)
+	if err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	err = s.truncateNoLock(0)
+	if err != nil {
+		return err
+	}
+
+	s.sinceSnapshot = 0
+	return nil
+}
+
+func (s *Store) truncateNoLock(size int64) error {
+	err := s.wal.Truncate(size)
+	if err == nil {
+		_, err = s.wal.Seek(size, io.SeekStart)
+	}
+	if err == nil && !s.config.NoSync {
+		err = s.wal.Sync()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to truncate %s: %w", s.wal.Name(), err)
+	}
+
+	s.walSize = size
+	return nil
+}
+
+func writeFileAtomic(path string, data []byte, sync bool) error {
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(data)
+	if err == nil && sync {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	err = os.Rename(tmp, path)
+	if err != nil || !sync {
+		return err
+	}
+
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
+
+// Close writes a snapshot, so that the next start does not replay the log.
+func (s *Store) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	err := s.snapshotNoLock()
+	if closeErr := s.wal.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,251 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var ops = []Op{
+	{Type: OpRegisterDevice, DeviceId: "device", AllocatorPodId: "allocator", Vendor: "vendor", Model: "model", MemoryB: 1024},
+	{Type: OpReservePod, DeviceId: "device", PodId: "a", RequestsMilli: 500, LimitMilli: 1000, Priority: 2, Memory: 0.5},
+	// logged before quotas were milli-shares
+	{Type: OpReservePod, DeviceId: "device", PodId: "b", Requests: 0.2, Limit: 0.4, Memory: 0.25},
+	{Type: OpAllocateMemory, DeviceId: "device", PodId: "a", MemoryB: 300},
+	{Type: OpFreeMemory, DeviceId: "device", PodId: "a", MemoryB: 100},
+	{Type: OpUnreservePod, DeviceId: "device", PodId: "b"},
+}
+
+func expectedState() *State {
+	return &State{
+		Seq: uint64(len(ops)),
+		Devices: map[string]*Device{
+			"device": {
+				Id: "device", AllocatorPodId: "allocator", Vendor: "vendor", Model: "model", MemoryB: 1024,
+				Pods: map[string]*Pod{
+					"a": {Id: "a", RequestsMilli: 500, LimitMilli: 1000, Priority: 2, Memory: 0.5, MemoryBUsed: 200},
+				},
+			},
+		},
+	}
+}
+
+func openStore(t *testing.T, config Config) *Store {
+	t.Helper()
+
+	s, err := Open(config)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	return s
+}
+
+func appendOps(t *testing.T, s *Store, ops []Op) {
+	t.Helper()
+
+	for _, op := range ops {
+		if err := s.Append(op); err != nil {
+			t.Fatalf("failed to append %v: %v", op, err)
+		}
+	}
+}
+
+func TestStoreReplaysLogAfterCrash(t *testing.T) {
+	config := Config{Dir: t.TempDir()}
+	s := openStore(t, config)
+	appendOps(t, s, ops)
+
+	// no Close, as if the process was killed
+	restarted := openStore(t, config)
+	defer restarted.Close()
+	assert.Equal(t, expectedState(), restarted.State())
+
+	_, err := os.Stat(filepath.Join(config.Dir, snapshotFile))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestStoreSnapshotTruncatesLog(t *testing.T) {
+	config := Config{Dir: t.TempDir(), SnapshotEvery: 4}
+	s := openStore(t, config)
+	appendOps(t, s, ops)
+
+	// 4 ops are in the snapshot, the remaining 2 in the log
+	wal, err := os.ReadFile(filepath.Join(config.Dir, walFile))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(splitLines(wal)))
+
+	restarted := openStore(t, config)
+	assert.Equal(t, expectedState(), restarted.State())
+	assert.Nil(t, restarted.Close())
+
+	// Close leaves only the snapshot
+	wal, err = os.ReadFile(filepath.Join(config.Dir, walFile))
+	assert.Nil(t, err)
+	assert.Empty(t, wal)
+	assert.Equal(t, expectedState(), openStore(t, config).State())
+}
+
+func TestStoreKeepsOpWhenSnapshotFails(t *testing.T) {
+	config := Config{Dir: t.TempDir(), SnapshotEvery: 1}
+	s := openStore(t, config)
+
+	// a directory in the way of the snapshot
+	snapshot := filepath.Join(config.Dir, snapshotFile)
+	assert.Nil(t, os.MkdirAll(filepath.Join(snapshot, "blocked"), 0o755))
+	appendOps(t, s, ops[:2])
+
+	wal, err := os.ReadFile(filepath.Join(config.Dir, walFile))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(splitLines(wal)))
+
+	// the snapshot is retried after the next op
+	assert.Nil(t, os.RemoveAll(snapshot))
+	appendOps(t, s, ops[2:])
+	wal, err = os.ReadFile(filepath.Join(config.Dir, walFile))
+	assert.Nil(t, err)
+	assert.Empty(t, wal)
+	assert.Equal(t, expectedState(), openStore(t, config).State())
+}
+
+func TestStoreConvertsFractionsOfOldSnapshot(t *testing.T) {
+	config := Config{Dir: t.TempDir()}
+	snapshot := `{"seq": 2, "devices": {"device": {"id": "device", "memoryB": 1024, "pods": {
+		"a": {"id": "a", "requests": 0.3, "limit": 0.7, "memory": 0.5, "memoryBUsed": 0}}}}}`
+	assert.Nil(t, os.WriteFile(filepath.Join(config.Dir, snapshotFile), []byte(snapshot), 0o644))
+
+	s := openStore(t, config)
+	appendOps(t, s, []Op{{Type: OpReservePod, DeviceId: "device", PodId: "b", Requests: 0.1, Limit: 0.2}})
+
+	pods := s.State().Devices["device"].Pods
+	assert.Equal(t, &Pod{Id: "a", RequestsMilli: 300, LimitMilli: 700, Memory: 0.5}, pods["a"])
+	assert.Equal(t, &Pod{Id: "b", RequestsMilli: 100, LimitMilli: 200}, pods["b"])
+}
+
+func TestStoreKeepsAllocationsOfReservedPod(t *testing.T) {
+	config := Config{Dir: t.TempDir()}
+	s := openStore(t, config)
+	appendOps(t, s, []Op{
+		ops[0],
+		{Type: OpReservePod, DeviceId: "device", PodId: "a", PodUID: "uid", RequestsMilli: 500, LimitMilli: 500, MemoryB: 512},
+		{Type: OpAllocateMemory, DeviceId: "device", PodId: "a", MemoryB: 300, AllocationId: 1},
+		{Type: OpReservePod, DeviceId: "device", PodId: "a", RequestsMilli: 600, LimitMilli: 600, MemoryB: 400},
+	})
+	assert.NotNil(t, s.Append(Op{Type: OpReservePod, DeviceId: "device", PodId: "a", RequestsMilli: 600, LimitMilli: 600, MemoryB: 200}))
+
+	expected := &Pod{Id: "a", UID: "uid", RequestsMilli: 600, LimitMilli: 600, MemoryBLimit: 400, MemoryBUsed: 300, Allocations: map[uint64]uint64{1: 300}}
+	assert.Equal(t, expected, s.State().Devices["device"].Pods["a"])
+	assert.Equal(t, expected, openStore(t, config).State().Devices["device"].Pods["a"])
+
+	// a recreated pod does not inherit them
+	appendOps(t, s, []Op{{Type: OpReservePod, DeviceId: "device", PodId: "a", PodUID: "new", RequestsMilli: 600, LimitMilli: 600, MemoryB: 200}})
+	assert.Zero(t, s.State().Devices["device"].Pods["a"].MemoryBUsed)
+}
+
+func TestStoreSkipsLogRecordsInSnapshot(t *testing.T) {
+	config := Config{Dir: t.TempDir()}
+	s := openStore(t, config)
+	appendOps(t, s, ops)
+
+	// crash after the snapshot was written, but before the log was truncated
+	wal, err := os.ReadFile(filepath.Join(config.Dir, walFile))
+	assert.Nil(t, err)
+	assert.Nil(t, s.Snapshot())
+	assert.Nil(t, os.WriteFile(filepath.Join(config.Dir, walFile), wal, 0o644))
+
+	assert.Equal(t, expectedState(), openStore(t, config).State())
+}
+
+func TestStoreDropsTornRecord(t *testing.T) {
+	config := Config{Dir: t.TempDir()}
+	s := openStore(t, config)
+	appendOps(t, s, ops)
+
+	path := filepath.Join(config.Dir, walFile)
+	record, err := encodeRecord(Op{Seq: uint64(len(ops) + 1), Type: OpAllocateMemory, DeviceId: "device", PodId: "a", MemoryB: 1})
+	assert.Nil(t, err)
+
+	for _, torn := range [][]byte{record[:len(record)/2], append(record[:len(record)-3:len(record)-3], '\n')} {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+		assert.Nil(t, err)
+		_, err = file.Write(torn)
+		assert.Nil(t, err)
+		file.Close()
+
+		restarted := openStore(t, config)
+		assert.Equal(t, expectedState(), restarted.State())
+
+		// new records go after the last valid one
+		appendOps(t, restarted, []Op{{Type: OpAllocateMemory, DeviceId: "device", PodId: "a", MemoryB: 1}})
+		appendOps(t, restarted, []Op{{Type: OpFreeMemory, DeviceId: "device", PodId: "a", MemoryB: 1}})
+		state := openStore(t, config).State()
+		assert.Equal(t, uint64(len(ops)+2), state.Seq)
+		assert.Equal(t, uint64(200), state.Devices["device"].Pods["a"].MemoryBUsed)
+
+		// start the next round from the expected state
+		assert.Nil(t, restarted.Close())
+		assert.Nil(t, os.Remove(filepath.Join(config.Dir, snapshotFile)))
+		assert.Nil(t, os.Truncate(path, 0))
+		appendOps(t, openStore(t, config), ops)
+	}
+}
+
+func TestStoreFailsOnCorruptedLog(t *testing.T) {
+	config := Config{Dir: t.TempDir()}
+	appendOps(t, openStore(t, config), ops)
+
+	path := filepath.Join(config.Dir, walFile)
+	wal, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	wal[20] ^= 0xff
+	assert.Nil(t, os.WriteFile(path, wal, 0o644))
+
+	_, err = Open(config)
+	assert.NotNil(t, err)
+}
+
+func TestStoreRejectsOpsWhichCannotBeReplayed(t *testing.T) {
+	config := Config{Dir: t.TempDir()}
+	s := openStore(t, config)
+
+	assert.NotNil(t, s.Append(Op{Type: OpReservePod, DeviceId: "device", PodId: "a"}))
+	appendOps(t, s, ops[:1])
+	assert.NotNil(t, s.Append(Op{Type: OpAllocateMemory, DeviceId: "device", PodId: "a", MemoryB: 1}))
+	assert.NotNil(t, s.Append(Op{Type: "unknown", DeviceId: "device"}))
+
+	state := openStore(t, config).State()
+	assert.Equal(t, uint64(1), state.Seq)
+	assert.Len(t, state.Devices, 1)
+}
+
+func TestStoreTracksAllocations(t *testing.T) {
+	config := Config{Dir: t.TempDir()}
+	s := openStore(t, config)
+
+	appendOps(t, s, ops[:2])
+	appendOps(t, s, []Op{
+		{Type: OpAllocateMemory, DeviceId: "device", PodId: "a", MemoryB: 100, AllocationId: 1},
+		{Type: OpAllocateMemory, DeviceId: "device", PodId: "a", MemoryB: 50, AllocationId: 2},
+		{Type: OpFreeMemory, DeviceId: "device", PodId: "a", MemoryB: 100, AllocationId: 1},
+	})
+	assert.NotNil(t, s.Append(Op{Type: OpFreeMemory, DeviceId: "device", PodId: "a", MemoryB: 100, AllocationId: 1}))
+	assert.NotNil(t, s.Append(Op{Type: OpFreeMemory, DeviceId: "device", PodId: "a", MemoryB: 51}))
+
+	device := openStore(t, config).State().Devices["device"]
+	assert.Equal(t, uint64(2), device.LastAllocationId)
+	assert.Equal(t, map[uint64]uint64{2: 50}, device.Pods["a"].Allocations)
+	assert.Equal(t, uint64(50), device.Pods["a"].MemoryBUsed)
+}
+
+func splitLines(data []byte) []string {
+	lines := []string{}
+	start := 0
+	for i, b := range data {
+		if b == '\n' {
+			lines = append(lines, string(data[start:i]))
+			start = i + 1
+		}
+	}
+	return lines
+}
@@ -20,6 +20,108 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// How a reservation was admitted by the admission policy of the device.
+type Admission int32
+
+const (
+	// The reservation fits into the device with all others.
+	Admission_GUARANTEED Admission = 0
+	// The sum of reservations exceeds the device within the overcommit ratio.
+	Admission_OVERCOMMITTED Admission = 1
+	// The sum of reservations exceeds the device, nothing is guaranteed.
+	Admission_BEST_EFFORT Admission = 2
+)
+
+// Enum value maps for Admission.
+var (
+	Admission_name = map[int32]string{
+		0: "GUARANTEED",
+		1: "OVERCOMMITTED",
+		2: "BEST_EFFORT",
+	}
+	Admission_value = map[string]int32{
+		"GUARANTEED":    0,
+		"OVERCOMMITTED": 1,
+		"BEST_EFFORT":   2,
+	}
+)
+
+func (x Admission) Enum() *Admission {
+	p := new(Admission)
+	*p = x
+	return p
+}
+
+func (x Admission) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Admission) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_devicemanager_device_manager_proto_enumTypes[0].Descriptor()
+}
+
+func (Admission) Type() protoreflect.EnumType {
+	return &file_pkg_devicemanager_device_manager_proto_enumTypes[0]
+}
+
+func (x Admission) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Admission.Descriptor instead.
+func (Admission) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{0}
+}
+
+type LeaseNotice_Type int32
+
+const (
+	// The token has to be returned before yield_by, otherwise the pod is evicted.
+	LeaseNotice_REVOKE LeaseNotice_Type = 0
+	// The memory quota of the pod was reduced below what it allocated, it
+	// should free memory down to memory_b_limit.
+	LeaseNotice_MEMORY_PRESSURE LeaseNotice_Type = 1
+)
+
+// Enum value maps for LeaseNotice_Type.
+var (
+	LeaseNotice_Type_name = map[int32]string{
+		0: "REVOKE",
+		1: "MEMORY_PRESSURE",
+	}
+	LeaseNotice_Type_value = map[string]int32{
+		"REVOKE":          0,
+		"MEMORY_PRESSURE": 1,
+	}
+)
+
+func (x LeaseNotice_Type) Enum() *LeaseNotice_Type {
+	p := new(LeaseNotice_Type)
+	*p = x
+	return p
+}
+
+func (x LeaseNotice_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LeaseNotice_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_devicemanager_device_manager_proto_enumTypes[1].Descriptor()
+}
+
+func (LeaseNotice_Type) Type() protoreflect.EnumType {
+	return &file_pkg_devicemanager_device_manager_proto_enumTypes[1]
+}
+
+func (x LeaseNotice_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LeaseNotice_Type.Descriptor instead.
+func (LeaseNotice_Type) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{6, 0}
+}
+
 type GetTokenRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -27,6 +129,11 @@ type GetTokenRequest struct {
 
 	DeviceId string `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
 	PodId    string `protobuf:"bytes,2,opt,name=pod_id,json=podId,proto3" json:"pod_id,omitempty"`
+	// Pods are identified by namespace/name, clients which only send pod_id are
+	// in the default namespace. A pod_uid which differs from the one the quota
+	// was reserved with is rejected, the pod was recreated.
+	PodNamespace string `protobuf:"bytes,3,opt,name=pod_namespace,json=podNamespace,proto3" json:"pod_namespace,omitempty"`
+	PodUid       string `protobuf:"bytes,4,opt,name=pod_uid,json=podUid,proto3" json:"pod_uid,omitempty"`
 }
 
 func (x *GetTokenRequest) Reset() {
@@ -75,12 +182,28 @@ func (x *GetTokenRequest) GetPodId() string {
 	return ""
 }
 
+func (x *GetTokenRequest) GetPodNamespace() string {
+	if x != nil {
+		return x.PodNamespace
+	}
+	return ""
+}
+
+func (x *GetTokenRequest) GetPodUid() string {
+	if x != nil {
+		return x.PodUid
+	}
+	return ""
+}
+
 type GetTokenReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	ExpiresAt int64 `protobuf:"varint,1,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// lease_id identifies this grant, the token is returned with it.
+	LeaseId uint64 `protobuf:"varint,2,opt,name=lease_id,json=leaseId,proto3" json:"lease_id,omitempty"`
 }
 
 func (x *GetTokenReply) Reset() {
@@ -122,17 +245,30 @@ func (x *GetTokenReply) GetExpiresAt() int64 {
 	return 0
 }
 
-type ReturnTokenRequest struct {
+func (x *GetTokenReply) GetLeaseId() uint64 {
+	if x != nil {
+		return x.LeaseId
+	}
+	return 0
+}
+
+type TryGetTokenReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	DeviceId string `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
-	PodId    string `protobuf:"bytes,2,opt,name=pod_id,json=podId,proto3" json:"pod_id,omitempty"`
+	// granted is false if the device is busy, the pod then has no lease and
+	// queue_position and estimated_wait_ms tell how long a GetToken would wait.
+	Granted   bool  `protobuf:"varint,1,opt,name=granted,proto3" json:"granted,omitempty"`
+	ExpiresAt int64 `protobuf:"varint,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// 1 for the next request to be granted the token.
+	QueuePosition   int32  `protobuf:"varint,3,opt,name=queue_position,json=queuePosition,proto3" json:"queue_position,omitempty"`
+	EstimatedWaitMs int64  `protobuf:"varint,4,opt,name=estimated_wait_ms,json=estimatedWaitMs,proto3" json:"estimated_wait_ms,omitempty"`
+	LeaseId         uint64 `protobuf:"varint,5,opt,name=lease_id,json=leaseId,proto3" json:"lease_id,omitempty"`
 }
 
-func (x *ReturnTokenRequest) Reset() {
-	*x = ReturnTokenRequest{}
+func (x *TryGetTokenReply) Reset() {
+	*x = TryGetTokenReply{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -140,13 +276,13 @@ func (x *ReturnTokenRequest) Reset() {
 	}
 }
 
-func (x *ReturnTokenRequest) String() string {
+func (x *TryGetTokenReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ReturnTokenRequest) ProtoMessage() {}
+func (*TryGetTokenReply) ProtoMessage() {}
 
-func (x *ReturnTokenRequest) ProtoReflect() protoreflect.Message {
+func (x *TryGetTokenReply) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -158,90 +294,78 @@ func (x *ReturnTokenRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ReturnTokenRequest.ProtoReflect.Descriptor instead.
-func (*ReturnTokenRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use TryGetTokenReply.ProtoReflect.Descriptor instead.
+func (*TryGetTokenReply) Descriptor() ([]byte, []int) {
 	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *ReturnTokenRequest) GetDeviceId() string {
+func (x *TryGetTokenReply) GetGranted() bool {
 	if x != nil {
-		return x.DeviceId
+		return x.Granted
 	}
-	return ""
+	return false
 }
 
-func (x *ReturnTokenRequest) GetPodId() string {
+func (x *TryGetTokenReply) GetExpiresAt() int64 {
 	if x != nil {
-		return x.PodId
+		return x.ExpiresAt
 	}
-	return ""
-}
-
-type ReturnTokenReply struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+	return 0
 }
 
-func (x *ReturnTokenReply) Reset() {
-	*x = ReturnTokenReply{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[3]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+func (x *TryGetTokenReply) GetQueuePosition() int32 {
+	if x != nil {
+		return x.QueuePosition
 	}
+	return 0
 }
 
-func (x *ReturnTokenReply) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*ReturnTokenReply) ProtoMessage() {}
-
-func (x *ReturnTokenReply) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[3]
-	if protoimpl.UnsafeEnabled && x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+func (x *TryGetTokenReply) GetEstimatedWaitMs() int64 {
+	if x != nil {
+		return x.EstimatedWaitMs
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use ReturnTokenReply.ProtoReflect.Descriptor instead.
-func (*ReturnTokenReply) Descriptor() ([]byte, []int) {
-	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{3}
+func (x *TryGetTokenReply) GetLeaseId() uint64 {
+	if x != nil {
+		return x.LeaseId
+	}
+	return 0
 }
 
-type AllocateMemoryRequest struct {
+type ReturnTokenRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	DeviceId string `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
-	PodId    string `protobuf:"bytes,2,opt,name=pod_id,json=podId,proto3" json:"pod_id,omitempty"`
-	MemoryB  uint64 `protobuf:"varint,3,opt,name=memory_b,json=memoryB,proto3" json:"memory_b,omitempty"`
+	DeviceId     string `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	PodId        string `protobuf:"bytes,2,opt,name=pod_id,json=podId,proto3" json:"pod_id,omitempty"`
+	PodNamespace string `protobuf:"bytes,3,opt,name=pod_namespace,json=podNamespace,proto3" json:"pod_namespace,omitempty"`
+	PodUid       string `protobuf:"bytes,4,opt,name=pod_uid,json=podUid,proto3" json:"pod_uid,omitempty"`
+	// lease_id of the GetTokenReply, a return of a lease which already ended
+	// fails and leaves a newer lease of the pod alone. It is required, also on
+	// the session and the fast path.
+	LeaseId uint64 `protobuf:"varint,5,opt,name=lease_id,json=leaseId,proto3" json:"lease_id,omitempty"`
 }
 
-func (x *AllocateMemoryRequest) Reset() {
-	*x = AllocateMemoryRequest{}
+func (x *ReturnTokenRequest) Reset() {
+	*x = ReturnTokenRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[4]
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *AllocateMemoryRequest) String() string {
+func (x *ReturnTokenRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AllocateMemoryRequest) ProtoMessage() {}
+func (*ReturnTokenRequest) ProtoMessage() {}
 
-func (x *AllocateMemoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[4]
+func (x *ReturnTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -252,55 +376,69 @@ func (x *AllocateMemoryRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AllocateMemoryRequest.ProtoReflect.Descriptor instead.
-func (*AllocateMemoryRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use ReturnTokenRequest.ProtoReflect.Descriptor instead.
+func (*ReturnTokenRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *AllocateMemoryRequest) GetDeviceId() string {
+func (x *ReturnTokenRequest) GetDeviceId() string {
 	if x != nil {
 		return x.DeviceId
 	}
 	return ""
 }
 
-func (x *AllocateMemoryRequest) GetPodId() string {
+func (x *ReturnTokenRequest) GetPodId() string {
 	if x != nil {
 		return x.PodId
 	}
 	return ""
 }
 
-func (x *AllocateMemoryRequest) GetMemoryB() uint64 {
+func (x *ReturnTokenRequest) GetPodNamespace() string {
 	if x != nil {
-		return x.MemoryB
+		return x.PodNamespace
+	}
+	return ""
+}
+
+func (x *ReturnTokenRequest) GetPodUid() string {
+	if x != nil {
+		return x.PodUid
+	}
+	return ""
+}
+
+func (x *ReturnTokenRequest) GetLeaseId() uint64 {
+	if x != nil {
+		return x.LeaseId
 	}
 	return 0
 }
 
-type AllocateMemoryReply struct {
+type ReturnTokenReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 }
 
-func (x *AllocateMemoryReply) Reset() {
-	*x = AllocateMemoryReply{}
+func (x *ReturnTokenReply) Reset() {
+	*x = ReturnTokenReply{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[5]
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *AllocateMemoryReply) String() string {
+func (x *ReturnTokenReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AllocateMemoryReply) ProtoMessage() {}
+func (*ReturnTokenReply) ProtoMessage() {}
 
-func (x *AllocateMemoryReply) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[5]
+func (x *ReturnTokenReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -311,38 +449,39 @@ func (x *AllocateMemoryReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AllocateMemoryReply.ProtoReflect.Descriptor instead.
-func (*AllocateMemoryReply) Descriptor() ([]byte, []int) {
-	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use ReturnTokenReply.ProtoReflect.Descriptor instead.
+func (*ReturnTokenReply) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{4}
 }
 
-type FreeMemoryRequest struct {
+type WatchLeaseRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	DeviceId string `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
-	PodId    string `protobuf:"bytes,2,opt,name=pod_id,json=podId,proto3" json:"pod_id,omitempty"`
-	MemoryB  uint64 `protobuf:"varint,3,opt,name=memory_b,json=memoryB,proto3" json:"memory_b,omitempty"`
+	DeviceId     string `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	PodId        string `protobuf:"bytes,2,opt,name=pod_id,json=podId,proto3" json:"pod_id,omitempty"`
+	PodNamespace string `protobuf:"bytes,3,opt,name=pod_namespace,json=podNamespace,proto3" json:"pod_namespace,omitempty"`
+	PodUid       string `protobuf:"bytes,4,opt,name=pod_uid,json=podUid,proto3" json:"pod_uid,omitempty"`
 }
 
-func (x *FreeMemoryRequest) Reset() {
-	*x = FreeMemoryRequest{}
+func (x *WatchLeaseRequest) Reset() {
+	*x = WatchLeaseRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[6]
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *FreeMemoryRequest) String() string {
+func (x *WatchLeaseRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FreeMemoryRequest) ProtoMessage() {}
+func (*WatchLeaseRequest) ProtoMessage() {}
 
-func (x *FreeMemoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[6]
+func (x *WatchLeaseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -353,55 +492,70 @@ func (x *FreeMemoryRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FreeMemoryRequest.ProtoReflect.Descriptor instead.
-func (*FreeMemoryRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use WatchLeaseRequest.ProtoReflect.Descriptor instead.
+func (*WatchLeaseRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *FreeMemoryRequest) GetDeviceId() string {
+func (x *WatchLeaseRequest) GetDeviceId() string {
 	if x != nil {
 		return x.DeviceId
 	}
 	return ""
 }
 
-func (x *FreeMemoryRequest) GetPodId() string {
+func (x *WatchLeaseRequest) GetPodId() string {
 	if x != nil {
 		return x.PodId
 	}
 	return ""
 }
 
-func (x *FreeMemoryRequest) GetMemoryB() uint64 {
+func (x *WatchLeaseRequest) GetPodNamespace() string {
 	if x != nil {
-		return x.MemoryB
+		return x.PodNamespace
 	}
-	return 0
+	return ""
 }
 
-type FreeMemoryReply struct {
+func (x *WatchLeaseRequest) GetPodUid() string {
+	if x != nil {
+		return x.PodUid
+	}
+	return ""
+}
+
+type LeaseNotice struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Type         LeaseNotice_Type `protobuf:"varint,1,opt,name=type,proto3,enum=device_manager.LeaseNotice_Type" json:"type,omitempty"`
+	YieldBy      int64            `protobuf:"varint,2,opt,name=yield_by,json=yieldBy,proto3" json:"yield_by,omitempty"`
+	Reason       string           `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	MemoryBLimit uint64           `protobuf:"varint,4,opt,name=memory_b_limit,json=memoryBLimit,proto3" json:"memory_b_limit,omitempty"`
+	MemoryBUsed  uint64           `protobuf:"varint,5,opt,name=memory_b_used,json=memoryBUsed,proto3" json:"memory_b_used,omitempty"`
+	// lease_id is the lease a REVOKE is about.
+	LeaseId uint64 `protobuf:"varint,6,opt,name=lease_id,json=leaseId,proto3" json:"lease_id,omitempty"`
 }
 
-func (x *FreeMemoryReply) Reset() {
-	*x = FreeMemoryReply{}
+func (x *LeaseNotice) Reset() {
+	*x = LeaseNotice{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[7]
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *FreeMemoryReply) String() string {
+func (x *LeaseNotice) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FreeMemoryReply) ProtoMessage() {}
+func (*LeaseNotice) ProtoMessage() {}
 
-func (x *FreeMemoryReply) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[7]
+func (x *LeaseNotice) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -412,40 +566,87 @@ func (x *FreeMemoryReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FreeMemoryReply.ProtoReflect.Descriptor instead.
-func (*FreeMemoryReply) Descriptor() ([]byte, []int) {
-	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use LeaseNotice.ProtoReflect.Descriptor instead.
+func (*LeaseNotice) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{6}
 }
 
-type RegisterDeviceRequest struct {
+func (x *LeaseNotice) GetType() LeaseNotice_Type {
+	if x != nil {
+		return x.Type
+	}
+	return LeaseNotice_REVOKE
+}
+
+func (x *LeaseNotice) GetYieldBy() int64 {
+	if x != nil {
+		return x.YieldBy
+	}
+	return 0
+}
+
+func (x *LeaseNotice) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *LeaseNotice) GetMemoryBLimit() uint64 {
+	if x != nil {
+		return x.MemoryBLimit
+	}
+	return 0
+}
+
+func (x *LeaseNotice) GetMemoryBUsed() uint64 {
+	if x != nil {
+		return x.MemoryBUsed
+	}
+	return 0
+}
+
+func (x *LeaseNotice) GetLeaseId() uint64 {
+	if x != nil {
+		return x.LeaseId
+	}
+	return 0
+}
+
+// SessionRequest is sent by the client of a session, the first one opens it.
+type SessionRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Vendor         string `protobuf:"bytes,1,opt,name=vendor,proto3" json:"vendor,omitempty"`
-	Model          string `protobuf:"bytes,2,opt,name=model,proto3" json:"model,omitempty"`
-	DeviceId       string `protobuf:"bytes,3,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
-	MemoryB        uint64 `protobuf:"varint,4,opt,name=memory_b,json=memoryB,proto3" json:"memory_b,omitempty"`
-	AllocatorPodId string `protobuf:"bytes,5,opt,name=allocator_pod_id,json=allocatorPodId,proto3" json:"allocator_pod_id,omitempty"`
+	// seq is echoed by the reply to the request.
+	Seq uint64 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	// Types that are assignable to Request:
+	//	*SessionRequest_Open
+	//	*SessionRequest_Acquire
+	//	*SessionRequest_Release
+	//	*SessionRequest_Allocate
+	//	*SessionRequest_Free
+	Request isSessionRequest_Request `protobuf_oneof:"request"`
 }
 
-func (x *RegisterDeviceRequest) Reset() {
-	*x = RegisterDeviceRequest{}
+func (x *SessionRequest) Reset() {
+	*x = SessionRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[8]
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *RegisterDeviceRequest) String() string {
+func (x *SessionRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RegisterDeviceRequest) ProtoMessage() {}
+func (*SessionRequest) ProtoMessage() {}
 
-func (x *RegisterDeviceRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[8]
+func (x *SessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -456,54 +657,174 @@ func (x *RegisterDeviceRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RegisterDeviceRequest.ProtoReflect.Descriptor instead.
-func (*RegisterDeviceRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use SessionRequest.ProtoReflect.Descriptor instead.
+func (*SessionRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *RegisterDeviceRequest) GetVendor() string {
+func (x *SessionRequest) GetSeq() uint64 {
 	if x != nil {
-		return x.Vendor
+		return x.Seq
 	}
-	return ""
+	return 0
 }
 
-func (x *RegisterDeviceRequest) GetModel() string {
+func (m *SessionRequest) GetRequest() isSessionRequest_Request {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+func (x *SessionRequest) GetOpen() *SessionOpen {
+	if x, ok := x.GetRequest().(*SessionRequest_Open); ok {
+		return x.Open
+	}
+	return nil
+}
+
+func (x *SessionRequest) GetAcquire() *SessionAcquire {
+	if x, ok := x.GetRequest().(*SessionRequest_Acquire); ok {
+		return x.Acquire
+	}
+	return nil
+}
+
+func (x *SessionRequest) GetRelease() *SessionRelease {
+	if x, ok := x.GetRequest().(*SessionRequest_Release); ok {
+		return x.Release
+	}
+	return nil
+}
+
+func (x *SessionRequest) GetAllocate() *SessionAllocate {
+	if x, ok := x.GetRequest().(*SessionRequest_Allocate); ok {
+		return x.Allocate
+	}
+	return nil
+}
+
+func (x *SessionRequest) GetFree() *SessionFree {
+	if x, ok := x.GetRequest().(*SessionRequest_Free); ok {
+		return x.Free
+	}
+	return nil
+}
+
+type isSessionRequest_Request interface {
+	isSessionRequest_Request()
+}
+
+type SessionRequest_Open struct {
+	Open *SessionOpen `protobuf:"bytes,2,opt,name=open,proto3,oneof"`
+}
+
+type SessionRequest_Acquire struct {
+	Acquire *SessionAcquire `protobuf:"bytes,3,opt,name=acquire,proto3,oneof"`
+}
+
+type SessionRequest_Release struct {
+	Release *SessionRelease `protobuf:"bytes,4,opt,name=release,proto3,oneof"`
+}
+
+type SessionRequest_Allocate struct {
+	Allocate *SessionAllocate `protobuf:"bytes,5,opt,name=allocate,proto3,oneof"`
+}
+
+type SessionRequest_Free struct {
+	Free *SessionFree `protobuf:"bytes,6,opt,name=free,proto3,oneof"`
+}
+
+func (*SessionRequest_Open) isSessionRequest_Request() {}
+
+func (*SessionRequest_Acquire) isSessionRequest_Request() {}
+
+func (*SessionRequest_Release) isSessionRequest_Request() {}
+
+func (*SessionRequest_Allocate) isSessionRequest_Request() {}
+
+func (*SessionRequest_Free) isSessionRequest_Request() {}
+
+type SessionOpen struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceId     string `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	PodId        string `protobuf:"bytes,2,opt,name=pod_id,json=podId,proto3" json:"pod_id,omitempty"`
+	PodNamespace string `protobuf:"bytes,3,opt,name=pod_namespace,json=podNamespace,proto3" json:"pod_namespace,omitempty"`
+	PodUid       string `protobuf:"bytes,4,opt,name=pod_uid,json=podUid,proto3" json:"pod_uid,omitempty"`
+}
+
+func (x *SessionOpen) Reset() {
+	*x = SessionOpen{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SessionOpen) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionOpen) ProtoMessage() {}
+
+func (x *SessionOpen) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionOpen.ProtoReflect.Descriptor instead.
+func (*SessionOpen) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SessionOpen) GetDeviceId() string {
 	if x != nil {
-		return x.Model
+		return x.DeviceId
 	}
 	return ""
 }
 
-func (x *RegisterDeviceRequest) GetDeviceId() string {
+func (x *SessionOpen) GetPodId() string {
 	if x != nil {
-		return x.DeviceId
+		return x.PodId
 	}
 	return ""
 }
 
-func (x *RegisterDeviceRequest) GetMemoryB() uint64 {
+func (x *SessionOpen) GetPodNamespace() string {
 	if x != nil {
-		return x.MemoryB
+		return x.PodNamespace
 	}
-	return 0
+	return ""
 }
 
-func (x *RegisterDeviceRequest) GetAllocatorPodId() string {
+func (x *SessionOpen) GetPodUid() string {
 	if x != nil {
-		return x.AllocatorPodId
+		return x.PodUid
 	}
 	return ""
 }
 
-type RegisterDeviceReply struct {
+// SessionAcquire waits for the token like GetToken.
+type SessionAcquire struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 }
 
-func (x *RegisterDeviceReply) Reset() {
-	*x = RegisterDeviceReply{}
+func (x *SessionAcquire) Reset() {
+	*x = SessionAcquire{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -511,13 +832,13 @@ func (x *RegisterDeviceReply) Reset() {
 	}
 }
 
-func (x *RegisterDeviceReply) String() string {
+func (x *SessionAcquire) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RegisterDeviceReply) ProtoMessage() {}
+func (*SessionAcquire) ProtoMessage() {}
 
-func (x *RegisterDeviceReply) ProtoReflect() protoreflect.Message {
+func (x *SessionAcquire) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -529,25 +850,21 @@ func (x *RegisterDeviceReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RegisterDeviceReply.ProtoReflect.Descriptor instead.
-func (*RegisterDeviceReply) Descriptor() ([]byte, []int) {
+// Deprecated: Use SessionAcquire.ProtoReflect.Descriptor instead.
+func (*SessionAcquire) Descriptor() ([]byte, []int) {
 	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{9}
 }
 
-type ReservePodQuotaRequest struct {
+type SessionRelease struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	DeviceId string  `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
-	PodId    string  `protobuf:"bytes,2,opt,name=pod_id,json=podId,proto3" json:"pod_id,omitempty"`
-	Requests float64 `protobuf:"fixed64,3,opt,name=requests,proto3" json:"requests,omitempty"`
-	Limit    float64 `protobuf:"fixed64,4,opt,name=limit,proto3" json:"limit,omitempty"`
-	Memory   float64 `protobuf:"fixed64,5,opt,name=memory,proto3" json:"memory,omitempty"`
+	LeaseId uint64 `protobuf:"varint,1,opt,name=lease_id,json=leaseId,proto3" json:"lease_id,omitempty"`
 }
 
-func (x *ReservePodQuotaRequest) Reset() {
-	*x = ReservePodQuotaRequest{}
+func (x *SessionRelease) Reset() {
+	*x = SessionRelease{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -555,13 +872,13 @@ func (x *ReservePodQuotaRequest) Reset() {
 	}
 }
 
-func (x *ReservePodQuotaRequest) String() string {
+func (x *SessionRelease) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ReservePodQuotaRequest) ProtoMessage() {}
+func (*SessionRelease) ProtoMessage() {}
 
-func (x *ReservePodQuotaRequest) ProtoReflect() protoreflect.Message {
+func (x *SessionRelease) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -573,54 +890,28 @@ func (x *ReservePodQuotaRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ReservePodQuotaRequest.ProtoReflect.Descriptor instead.
-func (*ReservePodQuotaRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use SessionRelease.ProtoReflect.Descriptor instead.
+func (*SessionRelease) Descriptor() ([]byte, []int) {
 	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *ReservePodQuotaRequest) GetDeviceId() string {
-	if x != nil {
-		return x.DeviceId
-	}
-	return ""
-}
-
-func (x *ReservePodQuotaRequest) GetPodId() string {
-	if x != nil {
-		return x.PodId
-	}
-	return ""
-}
-
-func (x *ReservePodQuotaRequest) GetRequests() float64 {
-	if x != nil {
-		return x.Requests
-	}
-	return 0
-}
-
-func (x *ReservePodQuotaRequest) GetLimit() float64 {
-	if x != nil {
-		return x.Limit
-	}
-	return 0
-}
-
-func (x *ReservePodQuotaRequest) GetMemory() float64 {
+func (x *SessionRelease) GetLeaseId() uint64 {
 	if x != nil {
-		return x.Memory
+		return x.LeaseId
 	}
 	return 0
 }
 
-type ReservePodQuotaReply struct {
+type SessionAllocate struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	MemoryB uint64 `protobuf:"varint,1,opt,name=memory_b,json=memoryB,proto3" json:"memory_b,omitempty"`
 }
 
-func (x *ReservePodQuotaReply) Reset() {
-	*x = ReservePodQuotaReply{}
+func (x *SessionAllocate) Reset() {
+	*x = SessionAllocate{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -628,13 +919,13 @@ func (x *ReservePodQuotaReply) Reset() {
 	}
 }
 
-func (x *ReservePodQuotaReply) String() string {
+func (x *SessionAllocate) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ReservePodQuotaReply) ProtoMessage() {}
+func (*SessionAllocate) ProtoMessage() {}
 
-func (x *ReservePodQuotaReply) ProtoReflect() protoreflect.Message {
+func (x *SessionAllocate) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -646,22 +937,28 @@ func (x *ReservePodQuotaReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ReservePodQuotaReply.ProtoReflect.Descriptor instead.
-func (*ReservePodQuotaReply) Descriptor() ([]byte, []int) {
+// Deprecated: Use SessionAllocate.ProtoReflect.Descriptor instead.
+func (*SessionAllocate) Descriptor() ([]byte, []int) {
 	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{11}
 }
 
-type GetAvailableDevicesRequest struct {
+func (x *SessionAllocate) GetMemoryB() uint64 {
+	if x != nil {
+		return x.MemoryB
+	}
+	return 0
+}
+
+type SessionFree struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Vendor string `protobuf:"bytes,1,opt,name=vendor,proto3" json:"vendor,omitempty"`
-	Model  string `protobuf:"bytes,2,opt,name=model,proto3" json:"model,omitempty"`
+	AllocationId uint64 `protobuf:"varint,1,opt,name=allocation_id,json=allocationId,proto3" json:"allocation_id,omitempty"`
 }
 
-func (x *GetAvailableDevicesRequest) Reset() {
-	*x = GetAvailableDevicesRequest{}
+func (x *SessionFree) Reset() {
+	*x = SessionFree{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -669,13 +966,13 @@ func (x *GetAvailableDevicesRequest) Reset() {
 	}
 }
 
-func (x *GetAvailableDevicesRequest) String() string {
+func (x *SessionFree) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetAvailableDevicesRequest) ProtoMessage() {}
+func (*SessionFree) ProtoMessage() {}
 
-func (x *GetAvailableDevicesRequest) ProtoReflect() protoreflect.Message {
+func (x *SessionFree) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -687,37 +984,40 @@ func (x *GetAvailableDevicesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetAvailableDevicesRequest.ProtoReflect.Descriptor instead.
-func (*GetAvailableDevicesRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use SessionFree.ProtoReflect.Descriptor instead.
+func (*SessionFree) Descriptor() ([]byte, []int) {
 	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *GetAvailableDevicesRequest) GetVendor() string {
-	if x != nil {
-		return x.Vendor
-	}
-	return ""
-}
-
-func (x *GetAvailableDevicesRequest) GetModel() string {
+func (x *SessionFree) GetAllocationId() uint64 {
 	if x != nil {
-		return x.Model
+		return x.AllocationId
 	}
-	return ""
+	return 0
 }
 
-type FreeDeviceResources struct {
+// SessionEvent is a reply to a request of the client or a notice about its
+// lease, which has seq 0.
+type SessionEvent struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	DeviceId string  `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
-	Memory   float64 `protobuf:"fixed64,2,opt,name=memory,proto3" json:"memory,omitempty"`
-	Requests float64 `protobuf:"fixed64,3,opt,name=requests,proto3" json:"requests,omitempty"`
+	Seq uint64 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	// error is set if the request failed, the session stays open.
+	Error string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// Types that are assignable to Event:
+	//	*SessionEvent_Opened
+	//	*SessionEvent_Acquired
+	//	*SessionEvent_Released
+	//	*SessionEvent_Allocated
+	//	*SessionEvent_Freed
+	//	*SessionEvent_Notice
+	Event isSessionEvent_Event `protobuf_oneof:"event"`
 }
 
-func (x *FreeDeviceResources) Reset() {
-	*x = FreeDeviceResources{}
+func (x *SessionEvent) Reset() {
+	*x = SessionEvent{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -725,13 +1025,13 @@ func (x *FreeDeviceResources) Reset() {
 	}
 }
 
-func (x *FreeDeviceResources) String() string {
+func (x *SessionEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FreeDeviceResources) ProtoMessage() {}
+func (*SessionEvent) ProtoMessage() {}
 
-func (x *FreeDeviceResources) ProtoReflect() protoreflect.Message {
+func (x *SessionEvent) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -743,42 +1043,122 @@ func (x *FreeDeviceResources) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FreeDeviceResources.ProtoReflect.Descriptor instead.
-func (*FreeDeviceResources) Descriptor() ([]byte, []int) {
+// Deprecated: Use SessionEvent.ProtoReflect.Descriptor instead.
+func (*SessionEvent) Descriptor() ([]byte, []int) {
 	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *FreeDeviceResources) GetDeviceId() string {
+func (x *SessionEvent) GetSeq() uint64 {
 	if x != nil {
-		return x.DeviceId
+		return x.Seq
 	}
-	return ""
+	return 0
 }
 
-func (x *FreeDeviceResources) GetMemory() float64 {
+func (x *SessionEvent) GetError() string {
 	if x != nil {
-		return x.Memory
+		return x.Error
 	}
-	return 0
+	return ""
 }
 
-func (x *FreeDeviceResources) GetRequests() float64 {
-	if x != nil {
-		return x.Requests
+func (m *SessionEvent) GetEvent() isSessionEvent_Event {
+	if m != nil {
+		return m.Event
 	}
-	return 0
+	return nil
 }
 
-type GetAvailableDevicesReply struct {
+func (x *SessionEvent) GetOpened() *SessionOpened {
+	if x, ok := x.GetEvent().(*SessionEvent_Opened); ok {
+		return x.Opened
+	}
+	return nil
+}
+
+func (x *SessionEvent) GetAcquired() *GetTokenReply {
+	if x, ok := x.GetEvent().(*SessionEvent_Acquired); ok {
+		return x.Acquired
+	}
+	return nil
+}
+
+func (x *SessionEvent) GetReleased() *ReturnTokenReply {
+	if x, ok := x.GetEvent().(*SessionEvent_Released); ok {
+		return x.Released
+	}
+	return nil
+}
+
+func (x *SessionEvent) GetAllocated() *AllocateMemoryReply {
+	if x, ok := x.GetEvent().(*SessionEvent_Allocated); ok {
+		return x.Allocated
+	}
+	return nil
+}
+
+func (x *SessionEvent) GetFreed() *FreeMemoryReply {
+	if x, ok := x.GetEvent().(*SessionEvent_Freed); ok {
+		return x.Freed
+	}
+	return nil
+}
+
+func (x *SessionEvent) GetNotice() *LeaseNotice {
+	if x, ok := x.GetEvent().(*SessionEvent_Notice); ok {
+		return x.Notice
+	}
+	return nil
+}
+
+type isSessionEvent_Event interface {
+	isSessionEvent_Event()
+}
+
+type SessionEvent_Opened struct {
+	Opened *SessionOpened `protobuf:"bytes,3,opt,name=opened,proto3,oneof"`
+}
+
+type SessionEvent_Acquired struct {
+	Acquired *GetTokenReply `protobuf:"bytes,4,opt,name=acquired,proto3,oneof"`
+}
+
+type SessionEvent_Released struct {
+	Released *ReturnTokenReply `protobuf:"bytes,5,opt,name=released,proto3,oneof"`
+}
+
+type SessionEvent_Allocated struct {
+	Allocated *AllocateMemoryReply `protobuf:"bytes,6,opt,name=allocated,proto3,oneof"`
+}
+
+type SessionEvent_Freed struct {
+	Freed *FreeMemoryReply `protobuf:"bytes,7,opt,name=freed,proto3,oneof"`
+}
+
+type SessionEvent_Notice struct {
+	Notice *LeaseNotice `protobuf:"bytes,8,opt,name=notice,proto3,oneof"`
+}
+
+func (*SessionEvent_Opened) isSessionEvent_Event() {}
+
+func (*SessionEvent_Acquired) isSessionEvent_Event() {}
+
+func (*SessionEvent_Released) isSessionEvent_Event() {}
+
+func (*SessionEvent_Allocated) isSessionEvent_Event() {}
+
+func (*SessionEvent_Freed) isSessionEvent_Event() {}
+
+func (*SessionEvent_Notice) isSessionEvent_Event() {}
+
+type SessionOpened struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
-
-	Free []*FreeDeviceResources `protobuf:"bytes,1,rep,name=free,proto3" json:"free,omitempty"`
 }
 
-func (x *GetAvailableDevicesReply) Reset() {
-	*x = GetAvailableDevicesReply{}
+func (x *SessionOpened) Reset() {
+	*x = SessionOpened{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -786,13 +1166,13 @@ func (x *GetAvailableDevicesReply) Reset() {
 	}
 }
 
-func (x *GetAvailableDevicesReply) String() string {
+func (x *SessionOpened) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetAvailableDevicesReply) ProtoMessage() {}
+func (*SessionOpened) ProtoMessage() {}
 
-func (x *GetAvailableDevicesReply) ProtoReflect() protoreflect.Message {
+func (x *SessionOpened) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -804,14 +1184,1886 @@ func (x *GetAvailableDevicesReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetAvailableDevicesReply.ProtoReflect.Descriptor instead.
-func (*GetAvailableDevicesReply) Descriptor() ([]byte, []int) {
+// Deprecated: Use SessionOpened.ProtoReflect.Descriptor instead.
+func (*SessionOpened) Descriptor() ([]byte, []int) {
 	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *GetAvailableDevicesReply) GetFree() []*FreeDeviceResources {
+type AllocateMemoryRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceId     string `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	PodId        string `protobuf:"bytes,2,opt,name=pod_id,json=podId,proto3" json:"pod_id,omitempty"`
+	MemoryB      uint64 `protobuf:"varint,3,opt,name=memory_b,json=memoryB,proto3" json:"memory_b,omitempty"`
+	PodNamespace string `protobuf:"bytes,4,opt,name=pod_namespace,json=podNamespace,proto3" json:"pod_namespace,omitempty"`
+	PodUid       string `protobuf:"bytes,5,opt,name=pod_uid,json=podUid,proto3" json:"pod_uid,omitempty"`
+}
+
+func (x *AllocateMemoryRequest) Reset() {
+	*x = AllocateMemoryRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AllocateMemoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllocateMemoryRequest) ProtoMessage() {}
+
+func (x *AllocateMemoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllocateMemoryRequest.ProtoReflect.Descriptor instead.
+func (*AllocateMemoryRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *AllocateMemoryRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *AllocateMemoryRequest) GetPodId() string {
+	if x != nil {
+		return x.PodId
+	}
+	return ""
+}
+
+func (x *AllocateMemoryRequest) GetMemoryB() uint64 {
+	if x != nil {
+		return x.MemoryB
+	}
+	return 0
+}
+
+func (x *AllocateMemoryRequest) GetPodNamespace() string {
+	if x != nil {
+		return x.PodNamespace
+	}
+	return ""
+}
+
+func (x *AllocateMemoryRequest) GetPodUid() string {
+	if x != nil {
+		return x.PodUid
+	}
+	return ""
+}
+
+type AllocateMemoryReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Id of the allocation on the device, it is freed by this id.
+	AllocationId uint64 `protobuf:"varint,1,opt,name=allocation_id,json=allocationId,proto3" json:"allocation_id,omitempty"`
+}
+
+func (x *AllocateMemoryReply) Reset() {
+	*x = AllocateMemoryReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AllocateMemoryReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllocateMemoryReply) ProtoMessage() {}
+
+func (x *AllocateMemoryReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllocateMemoryReply.ProtoReflect.Descriptor instead.
+func (*AllocateMemoryReply) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *AllocateMemoryReply) GetAllocationId() uint64 {
+	if x != nil {
+		return x.AllocationId
+	}
+	return 0
+}
+
+type FreeMemoryRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceId string `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	PodId    string `protobuf:"bytes,2,opt,name=pod_id,json=podId,proto3" json:"pod_id,omitempty"`
+	// Deprecated: ignored, the size of the allocation is known.
+	MemoryB      uint64 `protobuf:"varint,3,opt,name=memory_b,json=memoryB,proto3" json:"memory_b,omitempty"`
+	PodNamespace string `protobuf:"bytes,4,opt,name=pod_namespace,json=podNamespace,proto3" json:"pod_namespace,omitempty"`
+	PodUid       string `protobuf:"bytes,5,opt,name=pod_uid,json=podUid,proto3" json:"pod_uid,omitempty"`
+	// Freeing an allocation twice or one which was never made fails.
+	AllocationId uint64 `protobuf:"varint,6,opt,name=allocation_id,json=allocationId,proto3" json:"allocation_id,omitempty"`
+}
+
+func (x *FreeMemoryRequest) Reset() {
+	*x = FreeMemoryRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FreeMemoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FreeMemoryRequest) ProtoMessage() {}
+
+func (x *FreeMemoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FreeMemoryRequest.ProtoReflect.Descriptor instead.
+func (*FreeMemoryRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *FreeMemoryRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *FreeMemoryRequest) GetPodId() string {
+	if x != nil {
+		return x.PodId
+	}
+	return ""
+}
+
+func (x *FreeMemoryRequest) GetMemoryB() uint64 {
+	if x != nil {
+		return x.MemoryB
+	}
+	return 0
+}
+
+func (x *FreeMemoryRequest) GetPodNamespace() string {
+	if x != nil {
+		return x.PodNamespace
+	}
+	return ""
+}
+
+func (x *FreeMemoryRequest) GetPodUid() string {
+	if x != nil {
+		return x.PodUid
+	}
+	return ""
+}
+
+func (x *FreeMemoryRequest) GetAllocationId() uint64 {
+	if x != nil {
+		return x.AllocationId
+	}
+	return 0
+}
+
+type FreeMemoryReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Size of the freed allocation.
+	MemoryB uint64 `protobuf:"varint,1,opt,name=memory_b,json=memoryB,proto3" json:"memory_b,omitempty"`
+}
+
+func (x *FreeMemoryReply) Reset() {
+	*x = FreeMemoryReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FreeMemoryReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FreeMemoryReply) ProtoMessage() {}
+
+func (x *FreeMemoryReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FreeMemoryReply.ProtoReflect.Descriptor instead.
+func (*FreeMemoryReply) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *FreeMemoryReply) GetMemoryB() uint64 {
+	if x != nil {
+		return x.MemoryB
+	}
+	return 0
+}
+
+type RegisterDeviceRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Vendor                string `protobuf:"bytes,1,opt,name=vendor,proto3" json:"vendor,omitempty"`
+	Model                 string `protobuf:"bytes,2,opt,name=model,proto3" json:"model,omitempty"`
+	DeviceId              string `protobuf:"bytes,3,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	MemoryB               uint64 `protobuf:"varint,4,opt,name=memory_b,json=memoryB,proto3" json:"memory_b,omitempty"`
+	AllocatorPodId        string `protobuf:"bytes,5,opt,name=allocator_pod_id,json=allocatorPodId,proto3" json:"allocator_pod_id,omitempty"`
+	AllocatorPodNamespace string `protobuf:"bytes,6,opt,name=allocator_pod_namespace,json=allocatorPodNamespace,proto3" json:"allocator_pod_namespace,omitempty"`
+	AllocatorPodUid       string `protobuf:"bytes,7,opt,name=allocator_pod_uid,json=allocatorPodUid,proto3" json:"allocator_pod_uid,omitempty"`
+}
+
+func (x *RegisterDeviceRequest) Reset() {
+	*x = RegisterDeviceRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RegisterDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterDeviceRequest) ProtoMessage() {}
+
+func (x *RegisterDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterDeviceRequest.ProtoReflect.Descriptor instead.
+func (*RegisterDeviceRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RegisterDeviceRequest) GetVendor() string {
+	if x != nil {
+		return x.Vendor
+	}
+	return ""
+}
+
+func (x *RegisterDeviceRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *RegisterDeviceRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *RegisterDeviceRequest) GetMemoryB() uint64 {
+	if x != nil {
+		return x.MemoryB
+	}
+	return 0
+}
+
+func (x *RegisterDeviceRequest) GetAllocatorPodId() string {
+	if x != nil {
+		return x.AllocatorPodId
+	}
+	return ""
+}
+
+func (x *RegisterDeviceRequest) GetAllocatorPodNamespace() string {
+	if x != nil {
+		return x.AllocatorPodNamespace
+	}
+	return ""
+}
+
+func (x *RegisterDeviceRequest) GetAllocatorPodUid() string {
+	if x != nil {
+		return x.AllocatorPodUid
+	}
+	return ""
+}
+
+type RegisterDeviceReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *RegisterDeviceReply) Reset() {
+	*x = RegisterDeviceReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RegisterDeviceReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterDeviceReply) ProtoMessage() {}
+
+func (x *RegisterDeviceReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterDeviceReply.ProtoReflect.Descriptor instead.
+func (*RegisterDeviceReply) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{20}
+}
+
+// A request without any quota reads it from the sharedev.requests,
+// sharedev.limits and sharedev.memory labels of the pod.
+type ReservePodQuotaRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceId string `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	PodId    string `protobuf:"bytes,2,opt,name=pod_id,json=podId,proto3" json:"pod_id,omitempty"`
+	// Deprecated: fractions of the device, rounded to the nearest milli-share.
+	// They are only read if none of the *_milli fields is set.
+	Requests float64 `protobuf:"fixed64,3,opt,name=requests,proto3" json:"requests,omitempty"`
+	Limit    float64 `protobuf:"fixed64,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	Memory   float64 `protobuf:"fixed64,5,opt,name=memory,proto3" json:"memory,omitempty"`
+	// Pods with higher priority are served first and may preempt the lease
+	// of a lower priority pod.
+	Priority     int32  `protobuf:"varint,6,opt,name=priority,proto3" json:"priority,omitempty"`
+	PodNamespace string `protobuf:"bytes,7,opt,name=pod_namespace,json=podNamespace,proto3" json:"pod_namespace,omitempty"`
+	PodUid       string `protobuf:"bytes,8,opt,name=pod_uid,json=podUid,proto3" json:"pod_uid,omitempty"`
+	// Quotas in milli-shares of the device, 1000 is the whole device.
+	RequestsMilli int64 `protobuf:"varint,9,opt,name=requests_milli,json=requestsMilli,proto3" json:"requests_milli,omitempty"`
+	LimitMilli    int64 `protobuf:"varint,10,opt,name=limit_milli,json=limitMilli,proto3" json:"limit_milli,omitempty"`
+	MemoryMilli   int64 `protobuf:"varint,11,opt,name=memory_milli,json=memoryMilli,proto3" json:"memory_milli,omitempty"`
+	// Memory in bytes, it cannot be combined with memory or memory_milli and
+	// must not exceed the memory of the device.
+	MemoryB uint64 `protobuf:"varint,12,opt,name=memory_b,json=memoryB,proto3" json:"memory_b,omitempty"`
+}
+
+func (x *ReservePodQuotaRequest) Reset() {
+	*x = ReservePodQuotaRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReservePodQuotaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReservePodQuotaRequest) ProtoMessage() {}
+
+func (x *ReservePodQuotaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReservePodQuotaRequest.ProtoReflect.Descriptor instead.
+func (*ReservePodQuotaRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ReservePodQuotaRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *ReservePodQuotaRequest) GetPodId() string {
+	if x != nil {
+		return x.PodId
+	}
+	return ""
+}
+
+func (x *ReservePodQuotaRequest) GetRequests() float64 {
+	if x != nil {
+		return x.Requests
+	}
+	return 0
+}
+
+func (x *ReservePodQuotaRequest) GetLimit() float64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ReservePodQuotaRequest) GetMemory() float64 {
+	if x != nil {
+		return x.Memory
+	}
+	return 0
+}
+
+func (x *ReservePodQuotaRequest) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *ReservePodQuotaRequest) GetPodNamespace() string {
+	if x != nil {
+		return x.PodNamespace
+	}
+	return ""
+}
+
+func (x *ReservePodQuotaRequest) GetPodUid() string {
+	if x != nil {
+		return x.PodUid
+	}
+	return ""
+}
+
+func (x *ReservePodQuotaRequest) GetRequestsMilli() int64 {
+	if x != nil {
+		return x.RequestsMilli
+	}
+	return 0
+}
+
+func (x *ReservePodQuotaRequest) GetLimitMilli() int64 {
+	if x != nil {
+		return x.LimitMilli
+	}
+	return 0
+}
+
+func (x *ReservePodQuotaRequest) GetMemoryMilli() int64 {
+	if x != nil {
+		return x.MemoryMilli
+	}
+	return 0
+}
+
+func (x *ReservePodQuotaRequest) GetMemoryB() uint64 {
+	if x != nil {
+		return x.MemoryB
+	}
+	return 0
+}
+
+type ReservePodQuotaReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RequestsAdmission Admission `protobuf:"varint,1,opt,name=requests_admission,json=requestsAdmission,proto3,enum=device_manager.Admission" json:"requests_admission,omitempty"`
+	MemoryAdmission   Admission `protobuf:"varint,2,opt,name=memory_admission,json=memoryAdmission,proto3,enum=device_manager.Admission" json:"memory_admission,omitempty"`
+}
+
+func (x *ReservePodQuotaReply) Reset() {
+	*x = ReservePodQuotaReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReservePodQuotaReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReservePodQuotaReply) ProtoMessage() {}
+
+func (x *ReservePodQuotaReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReservePodQuotaReply.ProtoReflect.Descriptor instead.
+func (*ReservePodQuotaReply) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ReservePodQuotaReply) GetRequestsAdmission() Admission {
+	if x != nil {
+		return x.RequestsAdmission
+	}
+	return Admission_GUARANTEED
+}
+
+func (x *ReservePodQuotaReply) GetMemoryAdmission() Admission {
+	if x != nil {
+		return x.MemoryAdmission
+	}
+	return Admission_GUARANTEED
+}
+
+// Replaces the quota of a pod reserved on the device, its allocations, priority
+// and lease are kept.
+type UpdatePodQuotaRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceId     string `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	PodId        string `protobuf:"bytes,2,opt,name=pod_id,json=podId,proto3" json:"pod_id,omitempty"`
+	PodNamespace string `protobuf:"bytes,3,opt,name=pod_namespace,json=podNamespace,proto3" json:"pod_namespace,omitempty"`
+	PodUid       string `protobuf:"bytes,4,opt,name=pod_uid,json=podUid,proto3" json:"pod_uid,omitempty"`
+	// Milli-shares of the device, a limit of 0 is the requests.
+	RequestsMilli int64 `protobuf:"varint,5,opt,name=requests_milli,json=requestsMilli,proto3" json:"requests_milli,omitempty"`
+	LimitMilli    int64 `protobuf:"varint,6,opt,name=limit_milli,json=limitMilli,proto3" json:"limit_milli,omitempty"`
+	// Memory as milli-shares of the device memory or in bytes, at most one of
+	// them is set. If neither is set the memory reservation is kept.
+	MemoryMilli int64  `protobuf:"varint,7,opt,name=memory_milli,json=memoryMilli,proto3" json:"memory_milli,omitempty"`
+	MemoryB     uint64 `protobuf:"varint,8,opt,name=memory_b,json=memoryB,proto3" json:"memory_b,omitempty"`
+	// Memory below what the pod allocated is refused, unless memory pressure is
+	// allowed. The pod is then sent a MEMORY_PRESSURE notice and cannot allocate
+	// until it freed memory below the new reservation.
+	AllowMemoryPressure bool `protobuf:"varint,9,opt,name=allow_memory_pressure,json=allowMemoryPressure,proto3" json:"allow_memory_pressure,omitempty"`
+}
+
+func (x *UpdatePodQuotaRequest) Reset() {
+	*x = UpdatePodQuotaRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdatePodQuotaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePodQuotaRequest) ProtoMessage() {}
+
+func (x *UpdatePodQuotaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePodQuotaRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePodQuotaRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *UpdatePodQuotaRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *UpdatePodQuotaRequest) GetPodId() string {
+	if x != nil {
+		return x.PodId
+	}
+	return ""
+}
+
+func (x *UpdatePodQuotaRequest) GetPodNamespace() string {
+	if x != nil {
+		return x.PodNamespace
+	}
+	return ""
+}
+
+func (x *UpdatePodQuotaRequest) GetPodUid() string {
+	if x != nil {
+		return x.PodUid
+	}
+	return ""
+}
+
+func (x *UpdatePodQuotaRequest) GetRequestsMilli() int64 {
+	if x != nil {
+		return x.RequestsMilli
+	}
+	return 0
+}
+
+func (x *UpdatePodQuotaRequest) GetLimitMilli() int64 {
+	if x != nil {
+		return x.LimitMilli
+	}
+	return 0
+}
+
+func (x *UpdatePodQuotaRequest) GetMemoryMilli() int64 {
+	if x != nil {
+		return x.MemoryMilli
+	}
+	return 0
+}
+
+func (x *UpdatePodQuotaRequest) GetMemoryB() uint64 {
+	if x != nil {
+		return x.MemoryB
+	}
+	return 0
+}
+
+func (x *UpdatePodQuotaRequest) GetAllowMemoryPressure() bool {
+	if x != nil {
+		return x.AllowMemoryPressure
+	}
+	return false
+}
+
+type UpdatePodQuotaReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RequestsAdmission Admission `protobuf:"varint,1,opt,name=requests_admission,json=requestsAdmission,proto3,enum=device_manager.Admission" json:"requests_admission,omitempty"`
+	MemoryAdmission   Admission `protobuf:"varint,2,opt,name=memory_admission,json=memoryAdmission,proto3,enum=device_manager.Admission" json:"memory_admission,omitempty"`
+	// Memory the pod allocated beyond the new reservation.
+	MemoryBOverLimit uint64 `protobuf:"varint,3,opt,name=memory_b_over_limit,json=memoryBOverLimit,proto3" json:"memory_b_over_limit,omitempty"`
+}
+
+func (x *UpdatePodQuotaReply) Reset() {
+	*x = UpdatePodQuotaReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdatePodQuotaReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePodQuotaReply) ProtoMessage() {}
+
+func (x *UpdatePodQuotaReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePodQuotaReply.ProtoReflect.Descriptor instead.
+func (*UpdatePodQuotaReply) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *UpdatePodQuotaReply) GetRequestsAdmission() Admission {
+	if x != nil {
+		return x.RequestsAdmission
+	}
+	return Admission_GUARANTEED
+}
+
+func (x *UpdatePodQuotaReply) GetMemoryAdmission() Admission {
+	if x != nil {
+		return x.MemoryAdmission
+	}
+	return Admission_GUARANTEED
+}
+
+func (x *UpdatePodQuotaReply) GetMemoryBOverLimit() uint64 {
+	if x != nil {
+		return x.MemoryBOverLimit
+	}
+	return 0
+}
+
+type GetAvailableDevicesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Vendor string `protobuf:"bytes,1,opt,name=vendor,proto3" json:"vendor,omitempty"`
+	Model  string `protobuf:"bytes,2,opt,name=model,proto3" json:"model,omitempty"`
+}
+
+func (x *GetAvailableDevicesRequest) Reset() {
+	*x = GetAvailableDevicesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetAvailableDevicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAvailableDevicesRequest) ProtoMessage() {}
+
+func (x *GetAvailableDevicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAvailableDevicesRequest.ProtoReflect.Descriptor instead.
+func (*GetAvailableDevicesRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *GetAvailableDevicesRequest) GetVendor() string {
+	if x != nil {
+		return x.Vendor
+	}
+	return ""
+}
+
+func (x *GetAvailableDevicesRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+type FreeDeviceResources struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceId string `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	// Deprecated: memory_milli and requests_milli as fractions of the device.
+	Memory   float64 `protobuf:"fixed64,2,opt,name=memory,proto3" json:"memory,omitempty"`
+	Requests float64 `protobuf:"fixed64,3,opt,name=requests,proto3" json:"requests,omitempty"`
+	// Unreserved milli-shares of the device, negative if it is overcommitted.
+	MemoryMilli   int64 `protobuf:"varint,4,opt,name=memory_milli,json=memoryMilli,proto3" json:"memory_milli,omitempty"`
+	RequestsMilli int64 `protobuf:"varint,5,opt,name=requests_milli,json=requestsMilli,proto3" json:"requests_milli,omitempty"`
+	// Unreserved and total memory of the device.
+	MemoryBFree  uint64 `protobuf:"varint,6,opt,name=memory_b_free,json=memoryBFree,proto3" json:"memory_b_free,omitempty"`
+	MemoryBTotal uint64 `protobuf:"varint,7,opt,name=memory_b_total,json=memoryBTotal,proto3" json:"memory_b_total,omitempty"`
+}
+
+func (x *FreeDeviceResources) Reset() {
+	*x = FreeDeviceResources{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FreeDeviceResources) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FreeDeviceResources) ProtoMessage() {}
+
+func (x *FreeDeviceResources) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FreeDeviceResources.ProtoReflect.Descriptor instead.
+func (*FreeDeviceResources) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *FreeDeviceResources) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *FreeDeviceResources) GetMemory() float64 {
+	if x != nil {
+		return x.Memory
+	}
+	return 0
+}
+
+func (x *FreeDeviceResources) GetRequests() float64 {
+	if x != nil {
+		return x.Requests
+	}
+	return 0
+}
+
+func (x *FreeDeviceResources) GetMemoryMilli() int64 {
+	if x != nil {
+		return x.MemoryMilli
+	}
+	return 0
+}
+
+func (x *FreeDeviceResources) GetRequestsMilli() int64 {
+	if x != nil {
+		return x.RequestsMilli
+	}
+	return 0
+}
+
+func (x *FreeDeviceResources) GetMemoryBFree() uint64 {
+	if x != nil {
+		return x.MemoryBFree
+	}
+	return 0
+}
+
+func (x *FreeDeviceResources) GetMemoryBTotal() uint64 {
+	if x != nil {
+		return x.MemoryBTotal
+	}
+	return 0
+}
+
+type GetAvailableDevicesReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Free []*FreeDeviceResources `protobuf:"bytes,1,rep,name=free,proto3" json:"free,omitempty"`
+}
+
+func (x *GetAvailableDevicesReply) Reset() {
+	*x = GetAvailableDevicesReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetAvailableDevicesReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAvailableDevicesReply) ProtoMessage() {}
+
+func (x *GetAvailableDevicesReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAvailableDevicesReply.ProtoReflect.Descriptor instead.
+func (*GetAvailableDevicesReply) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GetAvailableDevicesReply) GetFree() []*FreeDeviceResources {
+	if x != nil {
+		return x.Free
+	}
+	return nil
+}
+
+type GetLeaseHistoryRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Empty device_id and pod_id match all devices and pods.
+	DeviceId string `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	PodId    string `protobuf:"bytes,2,opt,name=pod_id,json=podId,proto3" json:"pod_id,omitempty"`
+	// Unix time range of returned leases, 0 leaves it open.
+	Since int64 `protobuf:"varint,3,opt,name=since,proto3" json:"since,omitempty"`
+	Until int64 `protobuf:"varint,4,opt,name=until,proto3" json:"until,omitempty"`
+	// Empty pod_namespace matches all namespaces.
+	PodNamespace string `protobuf:"bytes,5,opt,name=pod_namespace,json=podNamespace,proto3" json:"pod_namespace,omitempty"`
+}
+
+func (x *GetLeaseHistoryRequest) Reset() {
+	*x = GetLeaseHistoryRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetLeaseHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLeaseHistoryRequest) ProtoMessage() {}
+
+func (x *GetLeaseHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLeaseHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetLeaseHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GetLeaseHistoryRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *GetLeaseHistoryRequest) GetPodId() string {
+	if x != nil {
+		return x.PodId
+	}
+	return ""
+}
+
+func (x *GetLeaseHistoryRequest) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+func (x *GetLeaseHistoryRequest) GetUntil() int64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+func (x *GetLeaseHistoryRequest) GetPodNamespace() string {
+	if x != nil {
+		return x.PodNamespace
+	}
+	return ""
+}
+
+type LeaseHistoryEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceId     string `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	PodId        string `protobuf:"bytes,2,opt,name=pod_id,json=podId,proto3" json:"pod_id,omitempty"`
+	LeasedAtMs   int64  `protobuf:"varint,3,opt,name=leased_at_ms,json=leasedAtMs,proto3" json:"leased_at_ms,omitempty"`
+	ReturnedAtMs int64  `protobuf:"varint,4,opt,name=returned_at_ms,json=returnedAtMs,proto3" json:"returned_at_ms,omitempty"`
+	// returned, yielded, evicted, deleted or revoked
+	EndReason    string `protobuf:"bytes,5,opt,name=end_reason,json=endReason,proto3" json:"end_reason,omitempty"`
+	PodNamespace string `protobuf:"bytes,6,opt,name=pod_namespace,json=podNamespace,proto3" json:"pod_namespace,omitempty"`
+}
+
+func (x *LeaseHistoryEntry) Reset() {
+	*x = LeaseHistoryEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LeaseHistoryEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaseHistoryEntry) ProtoMessage() {}
+
+func (x *LeaseHistoryEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaseHistoryEntry.ProtoReflect.Descriptor instead.
+func (*LeaseHistoryEntry) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *LeaseHistoryEntry) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *LeaseHistoryEntry) GetPodId() string {
+	if x != nil {
+		return x.PodId
+	}
+	return ""
+}
+
+func (x *LeaseHistoryEntry) GetLeasedAtMs() int64 {
+	if x != nil {
+		return x.LeasedAtMs
+	}
+	return 0
+}
+
+func (x *LeaseHistoryEntry) GetReturnedAtMs() int64 {
+	if x != nil {
+		return x.ReturnedAtMs
+	}
+	return 0
+}
+
+func (x *LeaseHistoryEntry) GetEndReason() string {
+	if x != nil {
+		return x.EndReason
+	}
+	return ""
+}
+
+func (x *LeaseHistoryEntry) GetPodNamespace() string {
+	if x != nil {
+		return x.PodNamespace
+	}
+	return ""
+}
+
+type GetLeaseHistoryReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Entries []*LeaseHistoryEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+}
+
+func (x *GetLeaseHistoryReply) Reset() {
+	*x = GetLeaseHistoryReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetLeaseHistoryReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLeaseHistoryReply) ProtoMessage() {}
+
+func (x *GetLeaseHistoryReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLeaseHistoryReply.ProtoReflect.Descriptor instead.
+func (*GetLeaseHistoryReply) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *GetLeaseHistoryReply) GetEntries() []*LeaseHistoryEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type GetPodUsageRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceId     string `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	PodId        string `protobuf:"bytes,2,opt,name=pod_id,json=podId,proto3" json:"pod_id,omitempty"`
+	PodNamespace string `protobuf:"bytes,3,opt,name=pod_namespace,json=podNamespace,proto3" json:"pod_namespace,omitempty"`
+	PodUid       string `protobuf:"bytes,4,opt,name=pod_uid,json=podUid,proto3" json:"pod_uid,omitempty"`
+}
+
+func (x *GetPodUsageRequest) Reset() {
+	*x = GetPodUsageRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetPodUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPodUsageRequest) ProtoMessage() {}
+
+func (x *GetPodUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPodUsageRequest.ProtoReflect.Descriptor instead.
+func (*GetPodUsageRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *GetPodUsageRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *GetPodUsageRequest) GetPodId() string {
+	if x != nil {
+		return x.PodId
+	}
+	return ""
+}
+
+func (x *GetPodUsageRequest) GetPodNamespace() string {
+	if x != nil {
+		return x.PodNamespace
+	}
+	return ""
+}
+
+func (x *GetPodUsageRequest) GetPodUid() string {
+	if x != nil {
+		return x.PodUid
+	}
+	return ""
+}
+
+type QueueWaitStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Number of leases granted within the window.
+	Count  uint64  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	MeanMs float64 `protobuf:"fixed64,2,opt,name=mean_ms,json=meanMs,proto3" json:"mean_ms,omitempty"`
+	P50Ms  float64 `protobuf:"fixed64,3,opt,name=p50_ms,json=p50Ms,proto3" json:"p50_ms,omitempty"`
+	P95Ms  float64 `protobuf:"fixed64,4,opt,name=p95_ms,json=p95Ms,proto3" json:"p95_ms,omitempty"`
+	MaxMs  float64 `protobuf:"fixed64,5,opt,name=max_ms,json=maxMs,proto3" json:"max_ms,omitempty"`
+}
+
+func (x *QueueWaitStats) Reset() {
+	*x = QueueWaitStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueueWaitStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueWaitStats) ProtoMessage() {}
+
+func (x *QueueWaitStats) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueWaitStats.ProtoReflect.Descriptor instead.
+func (*QueueWaitStats) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *QueueWaitStats) GetCount() uint64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *QueueWaitStats) GetMeanMs() float64 {
+	if x != nil {
+		return x.MeanMs
+	}
+	return 0
+}
+
+func (x *QueueWaitStats) GetP50Ms() float64 {
+	if x != nil {
+		return x.P50Ms
+	}
+	return 0
+}
+
+func (x *QueueWaitStats) GetP95Ms() float64 {
+	if x != nil {
+		return x.P95Ms
+	}
+	return 0
+}
+
+func (x *QueueWaitStats) GetMaxMs() float64 {
+	if x != nil {
+		return x.MaxMs
+	}
+	return 0
+}
+
+type GetPodUsageReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Share of the window the pod held the token for.
+	Used           float64         `protobuf:"fixed64,1,opt,name=used,proto3" json:"used,omitempty"`
+	Requests       float64         `protobuf:"fixed64,2,opt,name=requests,proto3" json:"requests,omitempty"`
+	Limit          float64         `protobuf:"fixed64,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Priority       int32           `protobuf:"varint,4,opt,name=priority,proto3" json:"priority,omitempty"`
+	WindowSeconds  int64           `protobuf:"varint,5,opt,name=window_seconds,json=windowSeconds,proto3" json:"window_seconds,omitempty"`
+	HoldsToken     bool            `protobuf:"varint,6,opt,name=holds_token,json=holdsToken,proto3" json:"holds_token,omitempty"`
+	QueuedRequests uint32          `protobuf:"varint,7,opt,name=queued_requests,json=queuedRequests,proto3" json:"queued_requests,omitempty"`
+	QueueWait      *QueueWaitStats `protobuf:"bytes,8,opt,name=queue_wait,json=queueWait,proto3" json:"queue_wait,omitempty"`
+	Memory         float64         `protobuf:"fixed64,9,opt,name=memory,proto3" json:"memory,omitempty"`
+	MemoryBLimit   uint64          `protobuf:"varint,10,opt,name=memory_b_limit,json=memoryBLimit,proto3" json:"memory_b_limit,omitempty"`
+	MemoryBUsed    uint64          `protobuf:"varint,11,opt,name=memory_b_used,json=memoryBUsed,proto3" json:"memory_b_used,omitempty"`
+	// Tenant group the device time is split between before it is split between
+	// pods, the namespace unless the pod has a sharedev.group label.
+	Group string `protobuf:"bytes,12,opt,name=group,proto3" json:"group,omitempty"`
+	// Allocations of the pod which were not freed yet.
+	Allocations []*MemoryAllocation `protobuf:"bytes,13,rep,name=allocations,proto3" json:"allocations,omitempty"`
+}
+
+func (x *GetPodUsageReply) Reset() {
+	*x = GetPodUsageReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetPodUsageReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPodUsageReply) ProtoMessage() {}
+
+func (x *GetPodUsageReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPodUsageReply.ProtoReflect.Descriptor instead.
+func (*GetPodUsageReply) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *GetPodUsageReply) GetUsed() float64 {
+	if x != nil {
+		return x.Used
+	}
+	return 0
+}
+
+func (x *GetPodUsageReply) GetRequests() float64 {
+	if x != nil {
+		return x.Requests
+	}
+	return 0
+}
+
+func (x *GetPodUsageReply) GetLimit() float64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetPodUsageReply) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *GetPodUsageReply) GetWindowSeconds() int64 {
+	if x != nil {
+		return x.WindowSeconds
+	}
+	return 0
+}
+
+func (x *GetPodUsageReply) GetHoldsToken() bool {
+	if x != nil {
+		return x.HoldsToken
+	}
+	return false
+}
+
+func (x *GetPodUsageReply) GetQueuedRequests() uint32 {
+	if x != nil {
+		return x.QueuedRequests
+	}
+	return 0
+}
+
+func (x *GetPodUsageReply) GetQueueWait() *QueueWaitStats {
+	if x != nil {
+		return x.QueueWait
+	}
+	return nil
+}
+
+func (x *GetPodUsageReply) GetMemory() float64 {
+	if x != nil {
+		return x.Memory
+	}
+	return 0
+}
+
+func (x *GetPodUsageReply) GetMemoryBLimit() uint64 {
+	if x != nil {
+		return x.MemoryBLimit
+	}
+	return 0
+}
+
+func (x *GetPodUsageReply) GetMemoryBUsed() uint64 {
+	if x != nil {
+		return x.MemoryBUsed
+	}
+	return 0
+}
+
+func (x *GetPodUsageReply) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *GetPodUsageReply) GetAllocations() []*MemoryAllocation {
+	if x != nil {
+		return x.Allocations
+	}
+	return nil
+}
+
+type MemoryAllocation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AllocationId uint64 `protobuf:"varint,1,opt,name=allocation_id,json=allocationId,proto3" json:"allocation_id,omitempty"`
+	MemoryB      uint64 `protobuf:"varint,2,opt,name=memory_b,json=memoryB,proto3" json:"memory_b,omitempty"`
+}
+
+func (x *MemoryAllocation) Reset() {
+	*x = MemoryAllocation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MemoryAllocation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MemoryAllocation) ProtoMessage() {}
+
+func (x *MemoryAllocation) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MemoryAllocation.ProtoReflect.Descriptor instead.
+func (*MemoryAllocation) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *MemoryAllocation) GetAllocationId() uint64 {
+	if x != nil {
+		return x.AllocationId
+	}
+	return 0
+}
+
+func (x *MemoryAllocation) GetMemoryB() uint64 {
+	if x != nil {
+		return x.MemoryB
+	}
+	return 0
+}
+
+type ExportTraceRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Same filter as in GetLeaseHistoryRequest.
+	DeviceId     string `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	PodId        string `protobuf:"bytes,2,opt,name=pod_id,json=podId,proto3" json:"pod_id,omitempty"`
+	Since        int64  `protobuf:"varint,3,opt,name=since,proto3" json:"since,omitempty"`
+	Until        int64  `protobuf:"varint,4,opt,name=until,proto3" json:"until,omitempty"`
+	PodNamespace string `protobuf:"bytes,5,opt,name=pod_namespace,json=podNamespace,proto3" json:"pod_namespace,omitempty"`
+}
+
+func (x *ExportTraceRequest) Reset() {
+	*x = ExportTraceRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportTraceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportTraceRequest) ProtoMessage() {}
+
+func (x *ExportTraceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportTraceRequest.ProtoReflect.Descriptor instead.
+func (*ExportTraceRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ExportTraceRequest) GetDeviceId() string {
 	if x != nil {
-		return x.Free
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *ExportTraceRequest) GetPodId() string {
+	if x != nil {
+		return x.PodId
+	}
+	return ""
+}
+
+func (x *ExportTraceRequest) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+func (x *ExportTraceRequest) GetUntil() int64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+func (x *ExportTraceRequest) GetPodNamespace() string {
+	if x != nil {
+		return x.PodNamespace
+	}
+	return ""
+}
+
+type ExportTraceReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Chrome Trace Event format JSON, open with chrome://tracing or ui.perfetto.dev.
+	Trace []byte `protobuf:"bytes,1,opt,name=trace,proto3" json:"trace,omitempty"`
+}
+
+func (x *ExportTraceReply) Reset() {
+	*x = ExportTraceReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportTraceReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportTraceReply) ProtoMessage() {}
+
+func (x *ExportTraceReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportTraceReply.ProtoReflect.Descriptor instead.
+func (*ExportTraceReply) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *ExportTraceReply) GetTrace() []byte {
+	if x != nil {
+		return x.Trace
+	}
+	return nil
+}
+
+type GetNamespaceBudgetsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Empty returns the budgets of all namespaces.
+	Namespace string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+}
+
+func (x *GetNamespaceBudgetsRequest) Reset() {
+	*x = GetNamespaceBudgetsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetNamespaceBudgetsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNamespaceBudgetsRequest) ProtoMessage() {}
+
+func (x *GetNamespaceBudgetsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNamespaceBudgetsRequest.ProtoReflect.Descriptor instead.
+func (*GetNamespaceBudgetsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *GetNamespaceBudgetsRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+type NamespaceBudget struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Namespace string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// vendor/model of the devices, e.g. example.com/mydev.
+	Resource string `protobuf:"bytes,2,opt,name=resource,proto3" json:"resource,omitempty"`
+	// Sum of requests the namespace may reserve in device shares, -1 if not capped.
+	RequestsLimit float64 `protobuf:"fixed64,3,opt,name=requests_limit,json=requestsLimit,proto3" json:"requests_limit,omitempty"`
+	RequestsUsed  float64 `protobuf:"fixed64,4,opt,name=requests_used,json=requestsUsed,proto3" json:"requests_used,omitempty"`
+	// Share of the memory of all devices of the resource the namespace may
+	// reserve, -1 if not capped.
+	MemoryLimit  float64 `protobuf:"fixed64,5,opt,name=memory_limit,json=memoryLimit,proto3" json:"memory_limit,omitempty"`
+	MemoryUsed   float64 `protobuf:"fixed64,6,opt,name=memory_used,json=memoryUsed,proto3" json:"memory_used,omitempty"`
+	MemoryBUsed  uint64  `protobuf:"varint,7,opt,name=memory_b_used,json=memoryBUsed,proto3" json:"memory_b_used,omitempty"`
+	MemoryBTotal uint64  `protobuf:"varint,8,opt,name=memory_b_total,json=memoryBTotal,proto3" json:"memory_b_total,omitempty"`
+}
+
+func (x *NamespaceBudget) Reset() {
+	*x = NamespaceBudget{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[38]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NamespaceBudget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NamespaceBudget) ProtoMessage() {}
+
+func (x *NamespaceBudget) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[38]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NamespaceBudget.ProtoReflect.Descriptor instead.
+func (*NamespaceBudget) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *NamespaceBudget) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *NamespaceBudget) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *NamespaceBudget) GetRequestsLimit() float64 {
+	if x != nil {
+		return x.RequestsLimit
+	}
+	return 0
+}
+
+func (x *NamespaceBudget) GetRequestsUsed() float64 {
+	if x != nil {
+		return x.RequestsUsed
+	}
+	return 0
+}
+
+func (x *NamespaceBudget) GetMemoryLimit() float64 {
+	if x != nil {
+		return x.MemoryLimit
+	}
+	return 0
+}
+
+func (x *NamespaceBudget) GetMemoryUsed() float64 {
+	if x != nil {
+		return x.MemoryUsed
+	}
+	return 0
+}
+
+func (x *NamespaceBudget) GetMemoryBUsed() uint64 {
+	if x != nil {
+		return x.MemoryBUsed
+	}
+	return 0
+}
+
+func (x *NamespaceBudget) GetMemoryBTotal() uint64 {
+	if x != nil {
+		return x.MemoryBTotal
+	}
+	return 0
+}
+
+type GetNamespaceBudgetsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Budgets []*NamespaceBudget `protobuf:"bytes,1,rep,name=budgets,proto3" json:"budgets,omitempty"`
+}
+
+func (x *GetNamespaceBudgetsReply) Reset() {
+	*x = GetNamespaceBudgetsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[39]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetNamespaceBudgetsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNamespaceBudgetsReply) ProtoMessage() {}
+
+func (x *GetNamespaceBudgetsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_devicemanager_device_manager_proto_msgTypes[39]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNamespaceBudgetsReply.ProtoReflect.Descriptor instead.
+func (*GetNamespaceBudgetsReply) Descriptor() ([]byte, []int) {
+	return file_pkg_devicemanager_device_manager_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *GetNamespaceBudgetsReply) GetBudgets() []*NamespaceBudget {
+	if x != nil {
+		return x.Budgets
 	}
 	return nil
 }
@@ -822,121 +3074,493 @@ var file_pkg_devicemanager_device_manager_proto_rawDesc = []byte{
 	0x0a, 0x26, 0x70, 0x6b, 0x67, 0x2f, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x6d, 0x61, 0x6e, 0x61,
 	0x67, 0x65, 0x72, 0x2f, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x2d, 0x6d, 0x61, 0x6e, 0x61, 0x67,
 	0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65,
-	0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x22, 0x45, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x54,
-	0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x64,
-	0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
-	0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x12, 0x15, 0x0a, 0x06, 0x70, 0x6f, 0x64, 0x5f,
-	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x22,
-	0x2e, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79,
-	0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x22,
-	0x48, 0x0a, 0x12, 0x52, 0x65, 0x74, 0x75, 0x72, 0x6e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f,
-	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65,
-	0x49, 0x64, 0x12, 0x15, 0x0a, 0x06, 0x70, 0x6f, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x22, 0x12, 0x0a, 0x10, 0x52, 0x65, 0x74,
-	0x75, 0x72, 0x6e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x66, 0x0a,
-	0x15, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65,
-	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x76, 0x69, 0x63,
-	0x65, 0x49, 0x64, 0x12, 0x15, 0x0a, 0x06, 0x70, 0x6f, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x65,
-	0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x62, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x6d, 0x65,
-	0x6d, 0x6f, 0x72, 0x79, 0x42, 0x22, 0x15, 0x0a, 0x13, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74,
-	0x65, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x62, 0x0a, 0x11,
-	0x46, 0x72, 0x65, 0x65, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x12, 0x15,
-	0x0a, 0x06, 0x70, 0x6f, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
-	0x70, 0x6f, 0x64, 0x49, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f,
-	0x62, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x42,
-	0x22, 0x11, 0x0a, 0x0f, 0x46, 0x72, 0x65, 0x65, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x52, 0x65,
-	0x70, 0x6c, 0x79, 0x22, 0xa7, 0x01, 0x0a, 0x15, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72,
-	0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a,
-	0x06, 0x76, 0x65, 0x6e, 0x64, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x76,
-	0x65, 0x6e, 0x64, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x1b, 0x0a, 0x09, 0x64,
-	0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
-	0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x65, 0x6d, 0x6f,
-	0x72, 0x79, 0x5f, 0x62, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x6d, 0x65, 0x6d, 0x6f,
-	0x72, 0x79, 0x42, 0x12, 0x28, 0x0a, 0x10, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72,
-	0x5f, 0x70, 0x6f, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x61,
-	0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x50, 0x6f, 0x64, 0x49, 0x64, 0x22, 0x15, 0x0a,
-	0x13, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52,
-	0x65, 0x70, 0x6c, 0x79, 0x22, 0x96, 0x01, 0x0a, 0x16, 0x52, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65,
-	0x50, 0x6f, 0x64, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x22, 0x83, 0x01, 0x0a, 0x0f, 0x47, 0x65, 0x74,
+	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09,
+	0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x12, 0x15, 0x0a, 0x06, 0x70, 0x6f, 0x64,
+	0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64,
+	0x12, 0x23, 0x0a, 0x0d, 0x70, 0x6f, 0x64, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x6f, 0x64, 0x4e, 0x61, 0x6d, 0x65,
+	0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x70, 0x6f, 0x64, 0x5f, 0x75, 0x69, 0x64,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x6f, 0x64, 0x55, 0x69, 0x64, 0x22, 0x49,
+	0x0a, 0x0d, 0x47, 0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12,
+	0x1d, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x19,
+	0x0a, 0x08, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x07, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x49, 0x64, 0x22, 0xb9, 0x01, 0x0a, 0x10, 0x54, 0x72,
+	0x79, 0x47, 0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x18,
+	0x0a, 0x07, 0x67, 0x72, 0x61, 0x6e, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x07, 0x67, 0x72, 0x61, 0x6e, 0x74, 0x65, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69,
+	0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x65, 0x78,
+	0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x25, 0x0a, 0x0e, 0x71, 0x75, 0x65, 0x75, 0x65,
+	0x5f, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0d, 0x71, 0x75, 0x65, 0x75, 0x65, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2a,
+	0x0a, 0x11, 0x65, 0x73, 0x74, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x77, 0x61, 0x69, 0x74,
+	0x5f, 0x6d, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x65, 0x73, 0x74, 0x69, 0x6d,
+	0x61, 0x74, 0x65, 0x64, 0x57, 0x61, 0x69, 0x74, 0x4d, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x6c, 0x65,
+	0x61, 0x73, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x6c, 0x65,
+	0x61, 0x73, 0x65, 0x49, 0x64, 0x22, 0xa1, 0x01, 0x0a, 0x12, 0x52, 0x65, 0x74, 0x75, 0x72, 0x6e,
+	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09,
+	0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x12, 0x15, 0x0a, 0x06, 0x70, 0x6f, 0x64,
+	0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64,
+	0x12, 0x23, 0x0a, 0x0d, 0x70, 0x6f, 0x64, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x6f, 0x64, 0x4e, 0x61, 0x6d, 0x65,
+	0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x70, 0x6f, 0x64, 0x5f, 0x75, 0x69, 0x64,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x6f, 0x64, 0x55, 0x69, 0x64, 0x12, 0x19,
+	0x0a, 0x08, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x07, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x49, 0x64, 0x22, 0x12, 0x0a, 0x10, 0x52, 0x65, 0x74,
+	0x75, 0x72, 0x6e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x85, 0x01,
+	0x0a, 0x11, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64,
+	0x12, 0x15, 0x0a, 0x06, 0x70, 0x6f, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x6f, 0x64, 0x5f, 0x6e,
+	0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c,
+	0x70, 0x6f, 0x64, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x17, 0x0a, 0x07,
+	0x70, 0x6f, 0x64, 0x5f, 0x75, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70,
+	0x6f, 0x64, 0x55, 0x69, 0x64, 0x22, 0x84, 0x02, 0x0a, 0x0b, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x4e,
+	0x6f, 0x74, 0x69, 0x63, 0x65, 0x12, 0x34, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x20, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e,
+	0x61, 0x67, 0x65, 0x72, 0x2e, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x4e, 0x6f, 0x74, 0x69, 0x63, 0x65,
+	0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x79,
+	0x69, 0x65, 0x6c, 0x64, 0x5f, 0x62, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x79,
+	0x69, 0x65, 0x6c, 0x64, 0x42, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x24,
+	0x0a, 0x0e, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x62, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x42, 0x4c,
+	0x69, 0x6d, 0x69, 0x74, 0x12, 0x22, 0x0a, 0x0d, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x62,
+	0x5f, 0x75, 0x73, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x6d, 0x65, 0x6d,
+	0x6f, 0x72, 0x79, 0x42, 0x55, 0x73, 0x65, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x6c, 0x65, 0x61, 0x73,
+	0x65, 0x5f, 0x69, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x6c, 0x65, 0x61, 0x73,
+	0x65, 0x49, 0x64, 0x22, 0x27, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0a, 0x0a, 0x06, 0x52,
+	0x45, 0x56, 0x4f, 0x4b, 0x45, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x4d, 0x45, 0x4d, 0x4f, 0x52,
+	0x59, 0x5f, 0x50, 0x52, 0x45, 0x53, 0x53, 0x55, 0x52, 0x45, 0x10, 0x01, 0x22, 0xca, 0x02, 0x0a,
+	0x0e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x10, 0x0a, 0x03, 0x73, 0x65, 0x71, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x73, 0x65,
+	0x71, 0x12, 0x31, 0x0a, 0x04, 0x6f, 0x70, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1b, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72,
+	0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x4f, 0x70, 0x65, 0x6e, 0x48, 0x00, 0x52, 0x04,
+	0x6f, 0x70, 0x65, 0x6e, 0x12, 0x3a, 0x0a, 0x07, 0x61, 0x63, 0x71, 0x75, 0x69, 0x72, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d,
+	0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x41, 0x63,
+	0x71, 0x75, 0x69, 0x72, 0x65, 0x48, 0x00, 0x52, 0x07, 0x61, 0x63, 0x71, 0x75, 0x69, 0x72, 0x65,
+	0x12, 0x3a, 0x0a, 0x07, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1e, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67,
+	0x65, 0x72, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73,
+	0x65, 0x48, 0x00, 0x52, 0x07, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x12, 0x3d, 0x0a, 0x08,
+	0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f,
+	0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e,
+	0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x48,
+	0x00, 0x52, 0x08, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x12, 0x31, 0x0a, 0x04, 0x66,
+	0x72, 0x65, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x64, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x46, 0x72, 0x65, 0x65, 0x48, 0x00, 0x52, 0x04, 0x66, 0x72, 0x65, 0x65, 0x42, 0x09,
+	0x0a, 0x07, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x7f, 0x0a, 0x0b, 0x53, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x4f, 0x70, 0x65, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x49, 0x64, 0x12, 0x15, 0x0a, 0x06, 0x70, 0x6f, 0x64, 0x5f, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x0d,
+	0x70, 0x6f, 0x64, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x6f, 0x64, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63,
+	0x65, 0x12, 0x17, 0x0a, 0x07, 0x70, 0x6f, 0x64, 0x5f, 0x75, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x70, 0x6f, 0x64, 0x55, 0x69, 0x64, 0x22, 0x10, 0x0a, 0x0e, 0x53, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x41, 0x63, 0x71, 0x75, 0x69, 0x72, 0x65, 0x22, 0x2b, 0x0a, 0x0e,
+	0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x12, 0x19,
+	0x0a, 0x08, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x07, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x49, 0x64, 0x22, 0x2c, 0x0a, 0x0f, 0x53, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x12, 0x19, 0x0a, 0x08,
+	0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x62, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07,
+	0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x42, 0x22, 0x32, 0x0a, 0x0b, 0x53, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x46, 0x72, 0x65, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x61,
+	0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x22, 0xaa, 0x03, 0x0a, 0x0c,
+	0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x10, 0x0a, 0x03,
+	0x73, 0x65, 0x71, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x73, 0x65, 0x71, 0x12, 0x14,
+	0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x12, 0x37, 0x0a, 0x06, 0x6f, 0x70, 0x65, 0x6e, 0x65, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61,
+	0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x4f, 0x70, 0x65,
+	0x6e, 0x65, 0x64, 0x48, 0x00, 0x52, 0x06, 0x6f, 0x70, 0x65, 0x6e, 0x65, 0x64, 0x12, 0x3b, 0x0a,
+	0x08, 0x61, 0x63, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1d, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72,
+	0x2e, 0x47, 0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x48, 0x00,
+	0x52, 0x08, 0x61, 0x63, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x12, 0x3e, 0x0a, 0x08, 0x72, 0x65,
+	0x6c, 0x65, 0x61, 0x73, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x64,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x52, 0x65,
+	0x74, 0x75, 0x72, 0x6e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x48, 0x00,
+	0x52, 0x08, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x64, 0x12, 0x43, 0x0a, 0x09, 0x61, 0x6c,
+	0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e,
+	0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x41,
+	0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x48, 0x00, 0x52, 0x09, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x64, 0x12,
+	0x37, 0x0a, 0x05, 0x66, 0x72, 0x65, 0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f,
+	0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e,
+	0x46, 0x72, 0x65, 0x65, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x48,
+	0x00, 0x52, 0x05, 0x66, 0x72, 0x65, 0x65, 0x64, 0x12, 0x35, 0x0a, 0x06, 0x6e, 0x6f, 0x74, 0x69,
+	0x63, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x4e,
+	0x6f, 0x74, 0x69, 0x63, 0x65, 0x48, 0x00, 0x52, 0x06, 0x6e, 0x6f, 0x74, 0x69, 0x63, 0x65, 0x42,
+	0x07, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x22, 0x0f, 0x0a, 0x0d, 0x53, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x4f, 0x70, 0x65, 0x6e, 0x65, 0x64, 0x22, 0xa4, 0x01, 0x0a, 0x15, 0x41, 0x6c,
+	0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64,
+	0x12, 0x15, 0x0a, 0x06, 0x70, 0x6f, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x65, 0x6d, 0x6f, 0x72,
+	0x79, 0x5f, 0x62, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x6d, 0x65, 0x6d, 0x6f, 0x72,
+	0x79, 0x42, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x6f, 0x64, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70,
+	0x61, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x6f, 0x64, 0x4e, 0x61,
+	0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x70, 0x6f, 0x64, 0x5f, 0x75,
+	0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x6f, 0x64, 0x55, 0x69, 0x64,
+	0x22, 0x3a, 0x0a, 0x13, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x6f,
+	0x72, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x23, 0x0a, 0x0d, 0x61, 0x6c, 0x6c, 0x6f, 0x63,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c,
+	0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x22, 0xc5, 0x01, 0x0a,
+	0x11, 0x46, 0x72, 0x65, 0x65, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x12,
+	0x15, 0x0a, 0x06, 0x70, 0x6f, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79,
+	0x5f, 0x62, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79,
+	0x42, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x6f, 0x64, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61,
+	0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x6f, 0x64, 0x4e, 0x61, 0x6d,
+	0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x70, 0x6f, 0x64, 0x5f, 0x75, 0x69,
+	0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x6f, 0x64, 0x55, 0x69, 0x64, 0x12,
+	0x23, 0x0a, 0x0d, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x49, 0x64, 0x22, 0x2c, 0x0a, 0x0f, 0x46, 0x72, 0x65, 0x65, 0x4d, 0x65, 0x6d, 0x6f,
+	0x72, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x65, 0x6d, 0x6f, 0x72,
+	0x79, 0x5f, 0x62, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x6d, 0x65, 0x6d, 0x6f, 0x72,
+	0x79, 0x42, 0x22, 0x8b, 0x02, 0x0a, 0x15, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x44,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06,
+	0x76, 0x65, 0x6e, 0x64, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x76, 0x65,
+	0x6e, 0x64, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x65, 0x6d, 0x6f, 0x72,
+	0x79, 0x5f, 0x62, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x6d, 0x65, 0x6d, 0x6f, 0x72,
+	0x79, 0x42, 0x12, 0x28, 0x0a, 0x10, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x5f,
+	0x70, 0x6f, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x61, 0x6c,
+	0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x50, 0x6f, 0x64, 0x49, 0x64, 0x12, 0x36, 0x0a, 0x17,
+	0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x5f, 0x70, 0x6f, 0x64, 0x5f, 0x6e, 0x61,
+	0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x15, 0x61,
+	0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x50, 0x6f, 0x64, 0x4e, 0x61, 0x6d, 0x65, 0x73,
+	0x70, 0x61, 0x63, 0x65, 0x12, 0x2a, 0x0a, 0x11, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f,
+	0x72, 0x5f, 0x70, 0x6f, 0x64, 0x5f, 0x75, 0x69, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0f, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x50, 0x6f, 0x64, 0x55, 0x69, 0x64,
+	0x22, 0x15, 0x0a, 0x13, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x44, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0xf6, 0x02, 0x0a, 0x16, 0x52, 0x65, 0x73, 0x65,
+	0x72, 0x76, 0x65, 0x50, 0x6f, 0x64, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x12,
+	0x15, 0x0a, 0x06, 0x70, 0x6f, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x6d, 0x6f,
+	0x72, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79,
+	0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x23, 0x0a, 0x0d,
+	0x70, 0x6f, 0x64, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x6f, 0x64, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63,
+	0x65, 0x12, 0x17, 0x0a, 0x07, 0x70, 0x6f, 0x64, 0x5f, 0x75, 0x69, 0x64, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x70, 0x6f, 0x64, 0x55, 0x69, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x72, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x5f, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0d, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x4d, 0x69, 0x6c, 0x6c,
+	0x69, 0x12, 0x1f, 0x0a, 0x0b, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x5f, 0x6d, 0x69, 0x6c, 0x6c, 0x69,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x4d, 0x69, 0x6c,
+	0x6c, 0x69, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x6d, 0x69, 0x6c,
+	0x6c, 0x69, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79,
+	0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f,
+	0x62, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x42,
+	0x22, 0xa6, 0x01, 0x0a, 0x14, 0x52, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x50, 0x6f, 0x64, 0x51,
+	0x75, 0x6f, 0x74, 0x61, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x48, 0x0a, 0x12, 0x72, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x73, 0x5f, 0x61, 0x64, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d,
+	0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x41, 0x64, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x52, 0x11, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x41, 0x64, 0x6d, 0x69, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x44, 0x0a, 0x10, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x61, 0x64,
+	0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e,
+	0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x41,
+	0x64, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0f, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79,
+	0x41, 0x64, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0xc3, 0x02, 0x0a, 0x15, 0x55, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64,
+	0x12, 0x15, 0x0a, 0x06, 0x70, 0x6f, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x6f, 0x64, 0x5f, 0x6e,
+	0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c,
+	0x70, 0x6f, 0x64, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x17, 0x0a, 0x07,
+	0x70, 0x6f, 0x64, 0x5f, 0x75, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70,
+	0x6f, 0x64, 0x55, 0x69, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x73, 0x5f, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x72,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x12, 0x1f, 0x0a, 0x0b,
+	0x6c, 0x69, 0x6d, 0x69, 0x74, 0x5f, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0a, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x12, 0x21, 0x0a,
+	0x0c, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0b, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x4d, 0x69, 0x6c, 0x6c, 0x69,
+	0x12, 0x19, 0x0a, 0x08, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x62, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x07, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x42, 0x12, 0x32, 0x0a, 0x15, 0x61,
+	0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x70, 0x72, 0x65, 0x73,
+	0x73, 0x75, 0x72, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x13, 0x61, 0x6c, 0x6c, 0x6f,
+	0x77, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x50, 0x72, 0x65, 0x73, 0x73, 0x75, 0x72, 0x65, 0x22,
+	0xd4, 0x01, 0x0a, 0x13, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x51, 0x75, 0x6f,
+	0x74, 0x61, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x48, 0x0a, 0x12, 0x72, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x73, 0x5f, 0x61, 0x64, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e,
+	0x61, 0x67, 0x65, 0x72, 0x2e, 0x41, 0x64, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x11,
+	0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x41, 0x64, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x44, 0x0a, 0x10, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x61, 0x64, 0x6d, 0x69,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x64, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x41, 0x64, 0x6d,
+	0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0f, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x41, 0x64,
+	0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2d, 0x0a, 0x13, 0x6d, 0x65, 0x6d, 0x6f, 0x72,
+	0x79, 0x5f, 0x62, 0x5f, 0x6f, 0x76, 0x65, 0x72, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x10, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x42, 0x4f, 0x76, 0x65,
+	0x72, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x4a, 0x0a, 0x1a, 0x47, 0x65, 0x74, 0x41, 0x76, 0x61,
+	0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x65, 0x6e, 0x64, 0x6f, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x76, 0x65, 0x6e, 0x64, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05,
+	0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6d, 0x6f, 0x64,
+	0x65, 0x6c, 0x22, 0xfa, 0x01, 0x0a, 0x13, 0x46, 0x72, 0x65, 0x65, 0x44, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72,
+	0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x12,
+	0x1a, 0x0a, 0x08, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x08, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x6d,
+	0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0b, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x12, 0x25,
+	0x0a, 0x0e, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x5f, 0x6d, 0x69, 0x6c, 0x6c, 0x69,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73,
+	0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x12, 0x22, 0x0a, 0x0d, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f,
+	0x62, 0x5f, 0x66, 0x72, 0x65, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x6d, 0x65,
+	0x6d, 0x6f, 0x72, 0x79, 0x42, 0x46, 0x72, 0x65, 0x65, 0x12, 0x24, 0x0a, 0x0e, 0x6d, 0x65, 0x6d,
+	0x6f, 0x72, 0x79, 0x5f, 0x62, 0x5f, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x0c, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x42, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x22,
+	0x53, 0x0a, 0x18, 0x47, 0x65, 0x74, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x44,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x37, 0x0a, 0x04, 0x66,
+	0x72, 0x65, 0x65, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x64, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x46, 0x72, 0x65, 0x65, 0x44,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x52, 0x04,
+	0x66, 0x72, 0x65, 0x65, 0x22, 0x9d, 0x01, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x4c, 0x65, 0x61, 0x73,
+	0x65, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
 	0x1b, 0x0a, 0x09, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
 	0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x12, 0x15, 0x0a, 0x06,
 	0x70, 0x6f, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x6f,
-	0x64, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x12,
-	0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05,
-	0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x18,
-	0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x22, 0x16, 0x0a,
-	0x14, 0x52, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x50, 0x6f, 0x64, 0x51, 0x75, 0x6f, 0x74, 0x61,
-	0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x4a, 0x0a, 0x1a, 0x47, 0x65, 0x74, 0x41, 0x76, 0x61, 0x69,
-	0x6c, 0x61, 0x62, 0x6c, 0x65, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x65, 0x6e, 0x64, 0x6f, 0x72, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x06, 0x76, 0x65, 0x6e, 0x64, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6d,
-	0x6f, 0x64, 0x65, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6d, 0x6f, 0x64, 0x65,
-	0x6c, 0x22, 0x66, 0x0a, 0x13, 0x46, 0x72, 0x65, 0x65, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52,
-	0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x65, 0x76, 0x69,
-	0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x76,
-	0x69, 0x63, 0x65, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x12, 0x1a, 0x0a,
-	0x08, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52,
-	0x08, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x22, 0x53, 0x0a, 0x18, 0x47, 0x65, 0x74,
-	0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73,
-	0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x37, 0x0a, 0x04, 0x66, 0x72, 0x65, 0x65, 0x18, 0x01, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e,
-	0x61, 0x67, 0x65, 0x72, 0x2e, 0x46, 0x72, 0x65, 0x65, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52,
-	0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x52, 0x04, 0x66, 0x72, 0x65, 0x65, 0x32, 0x9a,
-	0x05, 0x0a, 0x0d, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72,
-	0x12, 0x5e, 0x0a, 0x0e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x44, 0x65, 0x76, 0x69,
-	0x63, 0x65, 0x12, 0x25, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61,
-	0x67, 0x65, 0x72, 0x2e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x44, 0x65, 0x76, 0x69,
-	0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x23, 0x2e, 0x64, 0x65, 0x76, 0x69,
-	0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x52, 0x65, 0x67, 0x69, 0x73,
-	0x74, 0x65, 0x72, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00,
-	0x12, 0x6d, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65,
-	0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x12, 0x2a, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65,
-	0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x76, 0x61, 0x69,
-	0x6c, 0x61, 0x62, 0x6c, 0x65, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e,
-	0x61, 0x67, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c,
-	0x65, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12,
-	0x61, 0x0a, 0x0f, 0x52, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x50, 0x6f, 0x64, 0x51, 0x75, 0x6f,
-	0x74, 0x61, 0x12, 0x26, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61,
-	0x67, 0x65, 0x72, 0x2e, 0x52, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x50, 0x6f, 0x64, 0x51, 0x75,
-	0x6f, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x64, 0x65, 0x76,
-	0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x52, 0x65, 0x73, 0x65,
-	0x72, 0x76, 0x65, 0x50, 0x6f, 0x64, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x52, 0x65, 0x70, 0x6c, 0x79,
-	0x22, 0x00, 0x12, 0x4c, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1f,
+	0x64, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x75, 0x6e, 0x74,
+	0x69, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x12,
+	0x23, 0x0a, 0x0d, 0x70, 0x6f, 0x64, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x6f, 0x64, 0x4e, 0x61, 0x6d, 0x65, 0x73,
+	0x70, 0x61, 0x63, 0x65, 0x22, 0xd3, 0x01, 0x0a, 0x11, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x48, 0x69,
+	0x73, 0x74, 0x6f, 0x72, 0x79, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x12, 0x15, 0x0a, 0x06, 0x70, 0x6f, 0x64, 0x5f, 0x69,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x12, 0x20,
+	0x0a, 0x0c, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x5f, 0x6d, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x64, 0x41, 0x74, 0x4d, 0x73,
+	0x12, 0x24, 0x0a, 0x0e, 0x72, 0x65, 0x74, 0x75, 0x72, 0x6e, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x5f,
+	0x6d, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x72, 0x65, 0x74, 0x75, 0x72, 0x6e,
+	0x65, 0x64, 0x41, 0x74, 0x4d, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x6e, 0x64, 0x5f, 0x72, 0x65,
+	0x61, 0x73, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x65, 0x6e, 0x64, 0x52,
+	0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x6f, 0x64, 0x5f, 0x6e, 0x61, 0x6d,
+	0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x6f,
+	0x64, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x22, 0x53, 0x0a, 0x14, 0x47, 0x65,
+	0x74, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x12, 0x3b, 0x0a, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e,
+	0x61, 0x67, 0x65, 0x72, 0x2e, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72,
+	0x79, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22,
+	0x86, 0x01, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x64, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x49, 0x64, 0x12, 0x15, 0x0a, 0x06, 0x70, 0x6f, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x6f,
+	0x64, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0c, 0x70, 0x6f, 0x64, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12,
+	0x17, 0x0a, 0x07, 0x70, 0x6f, 0x64, 0x5f, 0x75, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x70, 0x6f, 0x64, 0x55, 0x69, 0x64, 0x22, 0x84, 0x01, 0x0a, 0x0e, 0x51, 0x75, 0x65,
+	0x75, 0x65, 0x57, 0x61, 0x69, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x65, 0x61, 0x6e, 0x5f, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x06, 0x6d, 0x65, 0x61, 0x6e, 0x4d, 0x73, 0x12, 0x15, 0x0a, 0x06, 0x70, 0x35,
+	0x30, 0x5f, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x70, 0x35, 0x30, 0x4d,
+	0x73, 0x12, 0x15, 0x0a, 0x06, 0x70, 0x39, 0x35, 0x5f, 0x6d, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x05, 0x70, 0x39, 0x35, 0x4d, 0x73, 0x12, 0x15, 0x0a, 0x06, 0x6d, 0x61, 0x78, 0x5f,
+	0x6d, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x6d, 0x61, 0x78, 0x4d, 0x73, 0x22,
+	0xe0, 0x03, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x64, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x04, 0x75, 0x73, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x72, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72,
+	0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x72,
+	0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x25, 0x0a, 0x0e, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77,
+	0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d,
+	0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x1f, 0x0a,
+	0x0b, 0x68, 0x6f, 0x6c, 0x64, 0x73, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0a, 0x68, 0x6f, 0x6c, 0x64, 0x73, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x27,
+	0x0a, 0x0f, 0x71, 0x75, 0x65, 0x75, 0x65, 0x64, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0e, 0x71, 0x75, 0x65, 0x75, 0x65, 0x64, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x12, 0x3d, 0x0a, 0x0a, 0x71, 0x75, 0x65, 0x75, 0x65,
+	0x5f, 0x77, 0x61, 0x69, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x64, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x51, 0x75, 0x65,
+	0x75, 0x65, 0x57, 0x61, 0x69, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x09, 0x71, 0x75, 0x65,
+	0x75, 0x65, 0x57, 0x61, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x12, 0x24,
+	0x0a, 0x0e, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x62, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x42, 0x4c,
+	0x69, 0x6d, 0x69, 0x74, 0x12, 0x22, 0x0a, 0x0d, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x62,
+	0x5f, 0x75, 0x73, 0x65, 0x64, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x6d, 0x65, 0x6d,
+	0x6f, 0x72, 0x79, 0x42, 0x55, 0x73, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x67, 0x72, 0x6f, 0x75,
+	0x70, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x42,
+	0x0a, 0x0b, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x0d, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e,
+	0x61, 0x67, 0x65, 0x72, 0x2e, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x41, 0x6c, 0x6c, 0x6f, 0x63,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x22, 0x52, 0x0a, 0x10, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x41, 0x6c, 0x6c, 0x6f,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x23, 0x0a, 0x0d, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x61,
+	0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x6d,
+	0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x62, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x6d,
+	0x65, 0x6d, 0x6f, 0x72, 0x79, 0x42, 0x22, 0x99, 0x01, 0x0a, 0x12, 0x45, 0x78, 0x70, 0x6f, 0x72,
+	0x74, 0x54, 0x72, 0x61, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a,
+	0x09, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x12, 0x15, 0x0a, 0x06, 0x70, 0x6f,
+	0x64, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x6f, 0x64, 0x49,
+	0x64, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x75, 0x6e, 0x74, 0x69, 0x6c,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x12, 0x23, 0x0a,
+	0x0d, 0x70, 0x6f, 0x64, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x6f, 0x64, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61,
+	0x63, 0x65, 0x22, 0x28, 0x0a, 0x10, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x54, 0x72, 0x61, 0x63,
+	0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x72, 0x61, 0x63, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x74, 0x72, 0x61, 0x63, 0x65, 0x22, 0x3a, 0x0a, 0x1a,
+	0x47, 0x65, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x42, 0x75, 0x64, 0x67,
+	0x65, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61,
+	0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e,
+	0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x22, 0xa5, 0x02, 0x0a, 0x0f, 0x4e, 0x61, 0x6d,
+	0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x42, 0x75, 0x64, 0x67, 0x65, 0x74, 0x12, 0x1c, 0x0a, 0x09,
+	0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x72, 0x65,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x73, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d,
+	0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x23, 0x0a,
+	0x0d, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x5f, 0x75, 0x73, 0x65, 0x64, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x0c, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x55, 0x73,
+	0x65, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x6c, 0x69, 0x6d,
+	0x69, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79,
+	0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f,
+	0x75, 0x73, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x6d, 0x65, 0x6d, 0x6f,
+	0x72, 0x79, 0x55, 0x73, 0x65, 0x64, 0x12, 0x22, 0x0a, 0x0d, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79,
+	0x5f, 0x62, 0x5f, 0x75, 0x73, 0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x6d,
+	0x65, 0x6d, 0x6f, 0x72, 0x79, 0x42, 0x55, 0x73, 0x65, 0x64, 0x12, 0x24, 0x0a, 0x0e, 0x6d, 0x65,
+	0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x62, 0x5f, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x0c, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x42, 0x54, 0x6f, 0x74, 0x61, 0x6c,
+	0x22, 0x55, 0x0a, 0x18, 0x47, 0x65, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65,
+	0x42, 0x75, 0x64, 0x67, 0x65, 0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x39, 0x0a, 0x07,
+	0x62, 0x75, 0x64, 0x67, 0x65, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e,
+	0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x4e,
+	0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x42, 0x75, 0x64, 0x67, 0x65, 0x74, 0x52, 0x07,
+	0x62, 0x75, 0x64, 0x67, 0x65, 0x74, 0x73, 0x2a, 0x3f, 0x0a, 0x09, 0x41, 0x64, 0x6d, 0x69, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x0a, 0x47, 0x55, 0x41, 0x52, 0x41, 0x4e, 0x54, 0x45,
+	0x45, 0x44, 0x10, 0x00, 0x12, 0x11, 0x0a, 0x0d, 0x4f, 0x56, 0x45, 0x52, 0x43, 0x4f, 0x4d, 0x4d,
+	0x49, 0x54, 0x54, 0x45, 0x44, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x42, 0x45, 0x53, 0x54, 0x5f,
+	0x45, 0x46, 0x46, 0x4f, 0x52, 0x54, 0x10, 0x02, 0x32, 0xf3, 0x0a, 0x0a, 0x0d, 0x44, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x12, 0x5e, 0x0a, 0x0e, 0x52, 0x65,
+	0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x12, 0x25, 0x2e, 0x64,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x52, 0x65,
+	0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x23, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e,
+	0x61, 0x67, 0x65, 0x72, 0x2e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x44, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x6d, 0x0a, 0x13, 0x47, 0x65,
+	0x74, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65,
+	0x73, 0x12, 0x2a, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67,
+	0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x44,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e,
+	0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x47,
+	0x65, 0x74, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x44, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x61, 0x0a, 0x0f, 0x52, 0x65, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x50, 0x6f, 0x64, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x12, 0x26, 0x2e, 0x64,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x52, 0x65,
+	0x73, 0x65, 0x72, 0x76, 0x65, 0x50, 0x6f, 0x64, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61,
+	0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x52, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x50, 0x6f, 0x64,
+	0x51, 0x75, 0x6f, 0x74, 0x61, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x5e, 0x0a, 0x0e,
+	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x12, 0x25,
 	0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e,
-	0x47, 0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x1d, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72,
-	0x2e, 0x47, 0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00,
-	0x12, 0x55, 0x0a, 0x0b, 0x52, 0x65, 0x74, 0x75, 0x72, 0x6e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12,
+	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x23, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d,
+	0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64,
+	0x51, 0x75, 0x6f, 0x74, 0x61, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x6d, 0x0a, 0x13,
+	0x47, 0x65, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x42, 0x75, 0x64, 0x67,
+	0x65, 0x74, 0x73, 0x12, 0x2a, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e,
+	0x61, 0x67, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63,
+	0x65, 0x42, 0x75, 0x64, 0x67, 0x65, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x28, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72,
+	0x2e, 0x47, 0x65, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x42, 0x75, 0x64,
+	0x67, 0x65, 0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x4c, 0x0a, 0x08, 0x47,
+	0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1f, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65,
+	0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65,
+	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x6f, 0x6b,
+	0x65, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x52, 0x0a, 0x0b, 0x54, 0x72, 0x79,
+	0x47, 0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1f, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x6f, 0x6b,
+	0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x64, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x54, 0x72, 0x79, 0x47, 0x65,
+	0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x55, 0x0a,
+	0x0b, 0x52, 0x65, 0x74, 0x75, 0x72, 0x6e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x22, 0x2e, 0x64,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x52, 0x65,
+	0x74, 0x75, 0x72, 0x6e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x20, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65,
+	0x72, 0x2e, 0x52, 0x65, 0x74, 0x75, 0x72, 0x6e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x22, 0x00, 0x12, 0x50, 0x0a, 0x0a, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x65, 0x61,
+	0x73, 0x65, 0x12, 0x21, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61,
+	0x67, 0x65, 0x72, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d,
+	0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x4e, 0x6f, 0x74, 0x69,
+	0x63, 0x65, 0x22, 0x00, 0x30, 0x01, 0x12, 0x51, 0x0a, 0x0b, 0x4f, 0x70, 0x65, 0x6e, 0x53, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1e, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d,
+	0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d,
+	0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x45, 0x76,
+	0x65, 0x6e, 0x74, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x12, 0x5e, 0x0a, 0x0e, 0x41, 0x6c, 0x6c,
+	0x6f, 0x63, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x12, 0x25, 0x2e, 0x64, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x41, 0x6c, 0x6c,
+	0x6f, 0x63, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x23, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61,
+	0x67, 0x65, 0x72, 0x2e, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x6f,
+	0x72, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x52, 0x0a, 0x0a, 0x46, 0x72, 0x65,
+	0x65, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x12, 0x21, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65,
+	0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x46, 0x72, 0x65, 0x65, 0x4d, 0x65, 0x6d,
+	0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x64, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x46, 0x72, 0x65, 0x65,
+	0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x61, 0x0a,
+	0x0f, 0x47, 0x65, 0x74, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79,
+	0x12, 0x26, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65,
+	0x72, 0x2e, 0x47, 0x65, 0x74, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72,
+	0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x4c, 0x65, 0x61,
+	0x73, 0x65, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00,
+	0x12, 0x55, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x64, 0x55, 0x73, 0x61, 0x67, 0x65, 0x12,
 	0x22, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72,
-	0x2e, 0x52, 0x65, 0x74, 0x75, 0x72, 0x6e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75,
+	0x2e, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x64, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75,
 	0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e,
-	0x61, 0x67, 0x65, 0x72, 0x2e, 0x52, 0x65, 0x74, 0x75, 0x72, 0x6e, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
-	0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x5e, 0x0a, 0x0e, 0x41, 0x6c, 0x6c, 0x6f, 0x63,
-	0x61, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x12, 0x25, 0x2e, 0x64, 0x65, 0x76, 0x69,
-	0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x41, 0x6c, 0x6c, 0x6f, 0x63,
-	0x61, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x23, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65,
-	0x72, 0x2e, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79,
-	0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x52, 0x0a, 0x0a, 0x46, 0x72, 0x65, 0x65, 0x4d,
-	0x65, 0x6d, 0x6f, 0x72, 0x79, 0x12, 0x21, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6d,
-	0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x46, 0x72, 0x65, 0x65, 0x4d, 0x65, 0x6d, 0x6f, 0x72,
-	0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63,
-	0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x46, 0x72, 0x65, 0x65, 0x4d, 0x65,
-	0x6d, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x42, 0x33, 0x5a, 0x31, 0x67,
-	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x7a, 0x62, 0x73, 0x73, 0x73, 0x2f,
-	0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x2d, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2f, 0x70,
-	0x6b, 0x67, 0x2f, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72,
-	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x61, 0x67, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x64, 0x55, 0x73, 0x61, 0x67, 0x65,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x55, 0x0a, 0x0b, 0x45, 0x78, 0x70, 0x6f, 0x72,
+	0x74, 0x54, 0x72, 0x61, 0x63, 0x65, 0x12, 0x22, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f,
+	0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x54, 0x72,
+	0x61, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x64, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x45, 0x78, 0x70, 0x6f,
+	0x72, 0x74, 0x54, 0x72, 0x61, 0x63, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x42, 0x33,
+	0x5a, 0x31, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x7a, 0x62, 0x73,
+	0x73, 0x73, 0x2f, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x2d, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65,
+	0x72, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x6d, 0x61, 0x6e, 0x61,
+	0x67, 0x65, 0x72, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -951,45 +3575,109 @@ func file_pkg_devicemanager_device_manager_proto_rawDescGZIP() []byte {
 	return file_pkg_devicemanager_device_manager_proto_rawDescData
 }
 
-var file_pkg_devicemanager_device_manager_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_pkg_devicemanager_device_manager_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_pkg_devicemanager_device_manager_proto_msgTypes = make([]protoimpl.MessageInfo, 40)
 var file_pkg_devicemanager_device_manager_proto_goTypes = []interface{}{
-	(*GetTokenRequest)(nil),            // 0: device_manager.GetTokenRequest
-	(*GetTokenReply)(nil),              // 1: device_manager.GetTokenReply
-	(*ReturnTokenRequest)(nil),         // 2: device_manager.ReturnTokenRequest
-	(*ReturnTokenReply)(nil),           // 3: device_manager.ReturnTokenReply
-	(*AllocateMemoryRequest)(nil),      // 4: device_manager.AllocateMemoryRequest
-	(*AllocateMemoryReply)(nil),        // 5: device_manager.AllocateMemoryReply
-	(*FreeMemoryRequest)(nil),          // 6: device_manager.FreeMemoryRequest
-	(*FreeMemoryReply)(nil),            // 7: device_manager.FreeMemoryReply
-	(*RegisterDeviceRequest)(nil),      // 8: device_manager.RegisterDeviceRequest
-	(*RegisterDeviceReply)(nil),        // 9: device_manager.RegisterDeviceReply
-	(*ReservePodQuotaRequest)(nil),     // 10: device_manager.ReservePodQuotaRequest
-	(*ReservePodQuotaReply)(nil),       // 11: device_manager.ReservePodQuotaReply
-	(*GetAvailableDevicesRequest)(nil), // 12: device_manager.GetAvailableDevicesRequest
-	(*FreeDeviceResources)(nil),        // 13: device_manager.FreeDeviceResources
-	(*GetAvailableDevicesReply)(nil),   // 14: device_manager.GetAvailableDevicesReply
+	(Admission)(0),                     // 0: device_manager.Admission
+	(LeaseNotice_Type)(0),              // 1: device_manager.LeaseNotice.Type
+	(*GetTokenRequest)(nil),            // 2: device_manager.GetTokenRequest
+	(*GetTokenReply)(nil),              // 3: device_manager.GetTokenReply
+	(*TryGetTokenReply)(nil),           // 4: device_manager.TryGetTokenReply
+	(*ReturnTokenRequest)(nil),         // 5: device_manager.ReturnTokenRequest
+	(*ReturnTokenReply)(nil),           // 6: device_manager.ReturnTokenReply
+	(*WatchLeaseRequest)(nil),          // 7: device_manager.WatchLeaseRequest
+	(*LeaseNotice)(nil),                // 8: device_manager.LeaseNotice
+	(*SessionRequest)(nil),             // 9: device_manager.SessionRequest
+	(*SessionOpen)(nil),                // 10: device_manager.SessionOpen
+	(*SessionAcquire)(nil),             // 11: device_manager.SessionAcquire
+	(*SessionRelease)(nil),             // 12: device_manager.SessionRelease
+	(*SessionAllocate)(nil),            // 13: device_manager.SessionAllocate
+	(*SessionFree)(nil),                // 14: device_manager.SessionFree
+	(*SessionEvent)(nil),               // 15: device_manager.SessionEvent
+	(*SessionOpened)(nil),              // 16: device_manager.SessionOpened
+	(*AllocateMemoryRequest)(nil),      // 17: device_manager.AllocateMemoryRequest
+	(*AllocateMemoryReply)(nil),        // 18: device_manager.AllocateMemoryReply
+	(*FreeMemoryRequest)(nil),          // 19: device_manager.FreeMemoryRequest
+	(*FreeMemoryReply)(nil),            // 20: device_manager.FreeMemoryReply
+	(*RegisterDeviceRequest)(nil),      // 21: device_manager.RegisterDeviceRequest
+	(*RegisterDeviceReply)(nil),        // 22: device_manager.RegisterDeviceReply
+	(*ReservePodQuotaRequest)(nil),     // 23: device_manager.ReservePodQuotaRequest
+	(*ReservePodQuotaReply)(nil),       // 24: device_manager.ReservePodQuotaReply
+	(*UpdatePodQuotaRequest)(nil),      // 25: device_manager.UpdatePodQuotaRequest
+	(*UpdatePodQuotaReply)(nil),        // 26: device_manager.UpdatePodQuotaReply
+	(*GetAvailableDevicesRequest)(nil), // 27: device_manager.GetAvailableDevicesRequest
+	(*FreeDeviceResources)(nil),        // 28: device_manager.FreeDeviceResources
+	(*GetAvailableDevicesReply)(nil),   // 29: device_manager.GetAvailableDevicesReply
+	(*GetLeaseHistoryRequest)(nil),     // 30: device_manager.GetLeaseHistoryRequest
+	(*LeaseHistoryEntry)(nil),          // 31: device_manager.LeaseHistoryEntry
+	(*GetLeaseHistoryReply)(nil),       // 32: device_manager.GetLeaseHistoryReply
+	(*GetPodUsageRequest)(nil),         // 33: device_manager.GetPodUsageRequest
+	(*QueueWaitStats)(nil),             // 34: device_manager.QueueWaitStats
+	(*GetPodUsageReply)(nil),           // 35: device_manager.GetPodUsageReply
+	(*MemoryAllocation)(nil),           // 36: device_manager.MemoryAllocation
+	(*ExportTraceRequest)(nil),         // 37: device_manager.ExportTraceRequest
+	(*ExportTraceReply)(nil),           // 38: device_manager.ExportTraceReply
+	(*GetNamespaceBudgetsRequest)(nil), // 39: device_manager.GetNamespaceBudgetsRequest
+	(*NamespaceBudget)(nil),            // 40: device_manager.NamespaceBudget
+	(*GetNamespaceBudgetsReply)(nil),   // 41: device_manager.GetNamespaceBudgetsReply
 }
 var file_pkg_devicemanager_device_manager_proto_depIdxs = []int32{
-	13, // 0: device_manager.GetAvailableDevicesReply.free:type_name -> device_manager.FreeDeviceResources
-	8,  // 1: device_manager.DeviceManager.RegisterDevice:input_type -> device_manager.RegisterDeviceRequest
-	12, // 2: device_manager.DeviceManager.GetAvailableDevices:input_type -> device_manager.GetAvailableDevicesRequest
-	10, // 3: device_manager.DeviceManager.ReservePodQuota:input_type -> device_manager.ReservePodQuotaRequest
-	0,  // 4: device_manager.DeviceManager.GetToken:input_type -> device_manager.GetTokenRequest
-	2,  // 5: device_manager.DeviceManager.ReturnToken:input_type -> device_manager.ReturnTokenRequest
-	4,  // 6: device_manager.DeviceManager.AllocateMemory:input_type -> device_manager.AllocateMemoryRequest
-	6,  // 7: device_manager.DeviceManager.FreeMemory:input_type -> device_manager.FreeMemoryRequest
-	9,  // 8: device_manager.DeviceManager.RegisterDevice:output_type -> device_manager.RegisterDeviceReply
-	14, // 9: device_manager.DeviceManager.GetAvailableDevices:output_type -> device_manager.GetAvailableDevicesReply
-	11, // 10: device_manager.DeviceManager.ReservePodQuota:output_type -> device_manager.ReservePodQuotaReply
-	1,  // 11: device_manager.DeviceManager.GetToken:output_type -> device_manager.GetTokenReply
-	3,  // 12: device_manager.DeviceManager.ReturnToken:output_type -> device_manager.ReturnTokenReply
-	5,  // 13: device_manager.DeviceManager.AllocateMemory:output_type -> device_manager.AllocateMemoryReply
-	7,  // 14: device_manager.DeviceManager.FreeMemory:output_type -> device_manager.FreeMemoryReply
-	8,  // [8:15] is the sub-list for method output_type
-	1,  // [1:8] is the sub-list for method input_type
-	1,  // [1:1] is the sub-list for extension type_name
-	1,  // [1:1] is the sub-list for extension extendee
-	0,  // [0:1] is the sub-list for field type_name
+	1,  // 0: device_manager.LeaseNotice.type:type_name -> device_manager.LeaseNotice.Type
+	10, // 1: device_manager.SessionRequest.open:type_name -> device_manager.SessionOpen
+	11, // 2: device_manager.SessionRequest.acquire:type_name -> device_manager.SessionAcquire
+	12, // 3: device_manager.SessionRequest.release:type_name -> device_manager.SessionRelease
+	13, // 4: device_manager.SessionRequest.allocate:type_name -> device_manager.SessionAllocate
+	14, // 5: device_manager.SessionRequest.free:type_name -> device_manager.SessionFree
+	16, // 6: device_manager.SessionEvent.opened:type_name -> device_manager.SessionOpened
+	3,  // 7: device_manager.SessionEvent.acquired:type_name -> device_manager.GetTokenReply
+	6,  // 8: device_manager.SessionEvent.released:type_name -> device_manager.ReturnTokenReply
+	18, // 9: device_manager.SessionEvent.allocated:type_name -> device_manager.AllocateMemoryReply
+	20, // 10: device_manager.SessionEvent.freed:type_name -> device_manager.FreeMemoryReply
+	8,  // 11: device_manager.SessionEvent.notice:type_name -> device_manager.LeaseNotice
+	0,  // 12: device_manager.ReservePodQuotaReply.requests_admission:type_name -> device_manager.Admission
+	0,  // 13: device_manager.ReservePodQuotaReply.memory_admission:type_name -> device_manager.Admission
+	0,  // 14: device_manager.UpdatePodQuotaReply.requests_admission:type_name -> device_manager.Admission
+	0,  // 15: device_manager.UpdatePodQuotaReply.memory_admission:type_name -> device_manager.Admission
+	28, // 16: device_manager.GetAvailableDevicesReply.free:type_name -> device_manager.FreeDeviceResources
+	31, // 17: device_manager.GetLeaseHistoryReply.entries:type_name -> device_manager.LeaseHistoryEntry
+	34, // 18: device_manager.GetPodUsageReply.queue_wait:type_name -> device_manager.QueueWaitStats
+	36, // 19: device_manager.GetPodUsageReply.allocations:type_name -> device_manager.MemoryAllocation
+	40, // 20: device_manager.GetNamespaceBudgetsReply.budgets:type_name -> device_manager.NamespaceBudget
+	21, // 21: device_manager.DeviceManager.RegisterDevice:input_type -> device_manager.RegisterDeviceRequest
+	27, // 22: device_manager.DeviceManager.GetAvailableDevices:input_type -> device_manager.GetAvailableDevicesRequest
+	23, // 23: device_manager.DeviceManager.ReservePodQuota:input_type -> device_manager.ReservePodQuotaRequest
+	25, // 24: device_manager.DeviceManager.UpdatePodQuota:input_type -> device_manager.UpdatePodQuotaRequest
+	39, // 25: device_manager.DeviceManager.GetNamespaceBudgets:input_type -> device_manager.GetNamespaceBudgetsRequest
+	2,  // 26: device_manager.DeviceManager.GetToken:input_type -> device_manager.GetTokenRequest
+	2,  // 27: device_manager.DeviceManager.TryGetToken:input_type -> device_manager.GetTokenRequest
+	5,  // 28: device_manager.DeviceManager.ReturnToken:input_type -> device_manager.ReturnTokenRequest
+	7,  // 29: device_manager.DeviceManager.WatchLease:input_type -> device_manager.WatchLeaseRequest
+	9,  // 30: device_manager.DeviceManager.OpenSession:input_type -> device_manager.SessionRequest
+	17, // 31: device_manager.DeviceManager.AllocateMemory:input_type -> device_manager.AllocateMemoryRequest
+	19, // 32: device_manager.DeviceManager.FreeMemory:input_type -> device_manager.FreeMemoryRequest
+	30, // 33: device_manager.DeviceManager.GetLeaseHistory:input_type -> device_manager.GetLeaseHistoryRequest
+	33, // 34: device_manager.DeviceManager.GetPodUsage:input_type -> device_manager.GetPodUsageRequest
+	37, // 35: device_manager.DeviceManager.ExportTrace:input_type -> device_manager.ExportTraceRequest
+	22, // 36: device_manager.DeviceManager.RegisterDevice:output_type -> device_manager.RegisterDeviceReply
+	29, // 37: device_manager.DeviceManager.GetAvailableDevices:output_type -> device_manager.GetAvailableDevicesReply
+	24, // 38: device_manager.DeviceManager.ReservePodQuota:output_type -> device_manager.ReservePodQuotaReply
+	26, // 39: device_manager.DeviceManager.UpdatePodQuota:output_type -> device_manager.UpdatePodQuotaReply
+	41, // 40: device_manager.DeviceManager.GetNamespaceBudgets:output_type -> device_manager.GetNamespaceBudgetsReply
+	3,  // 41: device_manager.DeviceManager.GetToken:output_type -> device_manager.GetTokenReply
+	4,  // 42: device_manager.DeviceManager.TryGetToken:output_type -> device_manager.TryGetTokenReply
+	6,  // 43: device_manager.DeviceManager.ReturnToken:output_type -> device_manager.ReturnTokenReply
+	8,  // 44: device_manager.DeviceManager.WatchLease:output_type -> device_manager.LeaseNotice
+	15, // 45: device_manager.DeviceManager.OpenSession:output_type -> device_manager.SessionEvent
+	18, // 46: device_manager.DeviceManager.AllocateMemory:output_type -> device_manager.AllocateMemoryReply
+	20, // 47: device_manager.DeviceManager.FreeMemory:output_type -> device_manager.FreeMemoryReply
+	32, // 48: device_manager.DeviceManager.GetLeaseHistory:output_type -> device_manager.GetLeaseHistoryReply
+	35, // 49: device_manager.DeviceManager.GetPodUsage:output_type -> device_manager.GetPodUsageReply
+	38, // 50: device_manager.DeviceManager.ExportTrace:output_type -> device_manager.ExportTraceReply
+	36, // [36:51] is the sub-list for method output_type
+	21, // [21:36] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
 }
 
 func init() { file_pkg_devicemanager_device_manager_proto_init() }
@@ -1010,8 +3698,44 @@ func file_pkg_devicemanager_device_manager_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_devicemanager_device_manager_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetTokenReply); i {
+		file_pkg_devicemanager_device_manager_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetTokenReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TryGetTokenReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReturnTokenRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReturnTokenReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1022,8 +3746,8 @@ func file_pkg_devicemanager_device_manager_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_devicemanager_device_manager_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ReturnTokenRequest); i {
+		file_pkg_devicemanager_device_manager_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchLeaseRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1034,8 +3758,8 @@ func file_pkg_devicemanager_device_manager_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_devicemanager_device_manager_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ReturnTokenReply); i {
+		file_pkg_devicemanager_device_manager_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LeaseNotice); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1046,7 +3770,103 @@ func file_pkg_devicemanager_device_manager_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_devicemanager_device_manager_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_devicemanager_device_manager_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SessionRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SessionOpen); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SessionAcquire); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SessionRelease); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SessionAllocate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SessionFree); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SessionEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SessionOpened); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*AllocateMemoryRequest); i {
 			case 0:
 				return &v.state
@@ -1058,7 +3878,7 @@ func file_pkg_devicemanager_device_manager_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_devicemanager_device_manager_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_devicemanager_device_manager_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*AllocateMemoryReply); i {
 			case 0:
 				return &v.state
@@ -1070,7 +3890,7 @@ func file_pkg_devicemanager_device_manager_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_devicemanager_device_manager_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_devicemanager_device_manager_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*FreeMemoryRequest); i {
 			case 0:
 				return &v.state
@@ -1082,7 +3902,7 @@ func file_pkg_devicemanager_device_manager_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_devicemanager_device_manager_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_devicemanager_device_manager_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*FreeMemoryReply); i {
 			case 0:
 				return &v.state
@@ -1094,7 +3914,7 @@ func file_pkg_devicemanager_device_manager_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_devicemanager_device_manager_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_devicemanager_device_manager_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*RegisterDeviceRequest); i {
 			case 0:
 				return &v.state
@@ -1106,7 +3926,7 @@ func file_pkg_devicemanager_device_manager_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_devicemanager_device_manager_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_devicemanager_device_manager_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*RegisterDeviceReply); i {
 			case 0:
 				return &v.state
@@ -1118,7 +3938,7 @@ func file_pkg_devicemanager_device_manager_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_devicemanager_device_manager_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_devicemanager_device_manager_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ReservePodQuotaRequest); i {
 			case 0:
 				return &v.state
@@ -1130,7 +3950,7 @@ func file_pkg_devicemanager_device_manager_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_devicemanager_device_manager_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_devicemanager_device_manager_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ReservePodQuotaReply); i {
 			case 0:
 				return &v.state
@@ -1142,7 +3962,31 @@ func file_pkg_devicemanager_device_manager_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_devicemanager_device_manager_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_devicemanager_device_manager_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdatePodQuotaRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdatePodQuotaReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GetAvailableDevicesRequest); i {
 			case 0:
 				return &v.state
@@ -1154,7 +3998,7 @@ func file_pkg_devicemanager_device_manager_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_devicemanager_device_manager_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_devicemanager_device_manager_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*FreeDeviceResources); i {
 			case 0:
 				return &v.state
@@ -1166,7 +4010,7 @@ func file_pkg_devicemanager_device_manager_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_devicemanager_device_manager_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_devicemanager_device_manager_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GetAvailableDevicesReply); i {
 			case 0:
 				return &v.state
@@ -1178,19 +4022,179 @@ func file_pkg_devicemanager_device_manager_proto_init() {
 				return nil
 			}
 		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetLeaseHistoryRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LeaseHistoryEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetLeaseHistoryReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetPodUsageRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueueWaitStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetPodUsageReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MemoryAllocation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExportTraceRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExportTraceReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetNamespaceBudgetsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NamespaceBudget); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_devicemanager_device_manager_proto_msgTypes[39].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetNamespaceBudgetsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_pkg_devicemanager_device_manager_proto_msgTypes[7].OneofWrappers = []interface{}{
+		(*SessionRequest_Open)(nil),
+		(*SessionRequest_Acquire)(nil),
+		(*SessionRequest_Release)(nil),
+		(*SessionRequest_Allocate)(nil),
+		(*SessionRequest_Free)(nil),
+	}
+	file_pkg_devicemanager_device_manager_proto_msgTypes[13].OneofWrappers = []interface{}{
+		(*SessionEvent_Opened)(nil),
+		(*SessionEvent_Acquired)(nil),
+		(*SessionEvent_Released)(nil),
+		(*SessionEvent_Allocated)(nil),
+		(*SessionEvent_Freed)(nil),
+		(*SessionEvent_Notice)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pkg_devicemanager_device_manager_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   15,
+			NumEnums:      2,
+			NumMessages:   40,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_pkg_devicemanager_device_manager_proto_goTypes,
 		DependencyIndexes: file_pkg_devicemanager_device_manager_proto_depIdxs,
+		EnumInfos:         file_pkg_devicemanager_device_manager_proto_enumTypes,
 		MessageInfos:      file_pkg_devicemanager_device_manager_proto_msgTypes,
 	}.Build()
 	File_pkg_devicemanager_device_manager_proto = out.File
@@ -25,10 +25,20 @@ type DeviceManagerClient interface {
 	RegisterDevice(ctx context.Context, in *RegisterDeviceRequest, opts ...grpc.CallOption) (*RegisterDeviceReply, error)
 	GetAvailableDevices(ctx context.Context, in *GetAvailableDevicesRequest, opts ...grpc.CallOption) (*GetAvailableDevicesReply, error)
 	ReservePodQuota(ctx context.Context, in *ReservePodQuotaRequest, opts ...grpc.CallOption) (*ReservePodQuotaReply, error)
+	UpdatePodQuota(ctx context.Context, in *UpdatePodQuotaRequest, opts ...grpc.CallOption) (*UpdatePodQuotaReply, error)
+	GetNamespaceBudgets(ctx context.Context, in *GetNamespaceBudgetsRequest, opts ...grpc.CallOption) (*GetNamespaceBudgetsReply, error)
 	GetToken(ctx context.Context, in *GetTokenRequest, opts ...grpc.CallOption) (*GetTokenReply, error)
+	TryGetToken(ctx context.Context, in *GetTokenRequest, opts ...grpc.CallOption) (*TryGetTokenReply, error)
 	ReturnToken(ctx context.Context, in *ReturnTokenRequest, opts ...grpc.CallOption) (*ReturnTokenReply, error)
+	WatchLease(ctx context.Context, in *WatchLeaseRequest, opts ...grpc.CallOption) (DeviceManager_WatchLeaseClient, error)
+	// OpenSession serves the token and the memory of a pod over one stream, the
+	// lease it holds ends when the stream breaks.
+	OpenSession(ctx context.Context, opts ...grpc.CallOption) (DeviceManager_OpenSessionClient, error)
 	AllocateMemory(ctx context.Context, in *AllocateMemoryRequest, opts ...grpc.CallOption) (*AllocateMemoryReply, error)
 	FreeMemory(ctx context.Context, in *FreeMemoryRequest, opts ...grpc.CallOption) (*FreeMemoryReply, error)
+	GetLeaseHistory(ctx context.Context, in *GetLeaseHistoryRequest, opts ...grpc.CallOption) (*GetLeaseHistoryReply, error)
+	GetPodUsage(ctx context.Context, in *GetPodUsageRequest, opts ...grpc.CallOption) (*GetPodUsageReply, error)
+	ExportTrace(ctx context.Context, in *ExportTraceRequest, opts ...grpc.CallOption) (*ExportTraceReply, error)
 }
 
 type deviceManagerClient struct {
@@ -66,6 +76,24 @@ func (c *deviceManagerClient) ReservePodQuota(ctx context.Context, in *ReservePo
 	return out, nil
 }
 
+func (c *deviceManagerClient) UpdatePodQuota(ctx context.Context, in *UpdatePodQuotaRequest, opts ...grpc.CallOption) (*UpdatePodQuotaReply, error) {
+	out := new(UpdatePodQuotaReply)
+	err := c.cc.Invoke(ctx, "/device_manager.DeviceManager/UpdatePodQuota", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceManagerClient) GetNamespaceBudgets(ctx context.Context, in *GetNamespaceBudgetsRequest, opts ...grpc.CallOption) (*GetNamespaceBudgetsReply, error) {
+	out := new(GetNamespaceBudgetsReply)
+	err := c.cc.Invoke(ctx, "/device_manager.DeviceManager/GetNamespaceBudgets", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *deviceManagerClient) GetToken(ctx context.Context, in *GetTokenRequest, opts ...grpc.CallOption) (*GetTokenReply, error) {
 	out := new(GetTokenReply)
 	err := c.cc.Invoke(ctx, "/device_manager.DeviceManager/GetToken", in, out, opts...)
@@ -75,6 +103,15 @@ func (c *deviceManagerClient) GetToken(ctx context.Context, in *GetTokenRequest,
 	return out, nil
 }
 
+func (c *deviceManagerClient) TryGetToken(ctx context.Context, in *GetTokenRequest, opts ...grpc.CallOption) (*TryGetTokenReply, error) {
+	out := new(TryGetTokenReply)
+	err := c.cc.Invoke(ctx, "/device_manager.DeviceManager/TryGetToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *deviceManagerClient) ReturnToken(ctx context.Context, in *ReturnTokenRequest, opts ...grpc.CallOption) (*ReturnTokenReply, error) {
 	out := new(ReturnTokenReply)
 	err := c.cc.Invoke(ctx, "/device_manager.DeviceManager/ReturnToken", in, out, opts...)
@@ -84,6 +121,69 @@ func (c *deviceManagerClient) ReturnToken(ctx context.Context, in *ReturnTokenRe
 	return out, nil
 }
 
+func (c *deviceManagerClient) WatchLease(ctx context.Context, in *WatchLeaseRequest, opts ...grpc.CallOption) (DeviceManager_WatchLeaseClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DeviceManager_ServiceDesc.Streams[0], "/device_manager.DeviceManager/WatchLease", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &deviceManagerWatchLeaseClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DeviceManager_WatchLeaseClient interface {
+	Recv() (*LeaseNotice, error)
+	grpc.ClientStream
+}
+
+type deviceManagerWatchLeaseClient struct {
+	grpc.ClientStream
+}
+
+func (x *deviceManagerWatchLeaseClient) Recv() (*LeaseNotice, error) {
+	m := new(LeaseNotice)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *deviceManagerClient) OpenSession(ctx context.Context, opts ...grpc.CallOption) (DeviceManager_OpenSessionClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DeviceManager_ServiceDesc.Streams[1], "/device_manager.DeviceManager/OpenSession", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &deviceManagerOpenSessionClient{stream}
+	return x, nil
+}
+
+type DeviceManager_OpenSessionClient interface {
+	Send(*SessionRequest) error
+	Recv() (*SessionEvent, error)
+	grpc.ClientStream
+}
+
+type deviceManagerOpenSessionClient struct {
+	grpc.ClientStream
+}
+
+func (x *deviceManagerOpenSessionClient) Send(m *SessionRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *deviceManagerOpenSessionClient) Recv() (*SessionEvent, error) {
+	m := new(SessionEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *deviceManagerClient) AllocateMemory(ctx context.Context, in *AllocateMemoryRequest, opts ...grpc.CallOption) (*AllocateMemoryReply, error) {
 	out := new(AllocateMemoryReply)
 	err := c.cc.Invoke(ctx, "/device_manager.DeviceManager/AllocateMemory", in, out, opts...)
@@ -102,6 +202,33 @@ func (c *deviceManagerClient) FreeMemory(ctx context.Context, in *FreeMemoryRequ
 	return out, nil
 }
 
+func (c *deviceManagerClient) GetLeaseHistory(ctx context.Context, in *GetLeaseHistoryRequest, opts ...grpc.CallOption) (*GetLeaseHistoryReply, error) {
+	out := new(GetLeaseHistoryReply)
+	err := c.cc.Invoke(ctx, "/device_manager.DeviceManager/GetLeaseHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceManagerClient) GetPodUsage(ctx context.Context, in *GetPodUsageRequest, opts ...grpc.CallOption) (*GetPodUsageReply, error) {
+	out := new(GetPodUsageReply)
+	err := c.cc.Invoke(ctx, "/device_manager.DeviceManager/GetPodUsage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceManagerClient) ExportTrace(ctx context.Context, in *ExportTraceRequest, opts ...grpc.CallOption) (*ExportTraceReply, error) {
+	out := new(ExportTraceReply)
+	err := c.cc.Invoke(ctx, "/device_manager.DeviceManager/ExportTrace", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DeviceManagerServer is the server API for DeviceManager service.
 // All implementations must embed UnimplementedDeviceManagerServer
 // for forward compatibility
@@ -109,10 +236,20 @@ type DeviceManagerServer interface {
 	RegisterDevice(context.Context, *RegisterDeviceRequest) (*RegisterDeviceReply, error)
 	GetAvailableDevices(context.Context, *GetAvailableDevicesRequest) (*GetAvailableDevicesReply, error)
 	ReservePodQuota(context.Context, *ReservePodQuotaRequest) (*ReservePodQuotaReply, error)
+	UpdatePodQuota(context.Context, *UpdatePodQuotaRequest) (*UpdatePodQuotaReply, error)
+	GetNamespaceBudgets(context.Context, *GetNamespaceBudgetsRequest) (*GetNamespaceBudgetsReply, error)
 	GetToken(context.Context, *GetTokenRequest) (*GetTokenReply, error)
+	TryGetToken(context.Context, *GetTokenRequest) (*TryGetTokenReply, error)
 	ReturnToken(context.Context, *ReturnTokenRequest) (*ReturnTokenReply, error)
+	WatchLease(*WatchLeaseRequest, DeviceManager_WatchLeaseServer) error
+	// OpenSession serves the token and the memory of a pod over one stream, the
+	// lease it holds ends when the stream breaks.
+	OpenSession(DeviceManager_OpenSessionServer) error
 	AllocateMemory(context.Context, *AllocateMemoryRequest) (*AllocateMemoryReply, error)
 	FreeMemory(context.Context, *FreeMemoryRequest) (*FreeMemoryReply, error)
+	GetLeaseHistory(context.Context, *GetLeaseHistoryRequest) (*GetLeaseHistoryReply, error)
+	GetPodUsage(context.Context, *GetPodUsageRequest) (*GetPodUsageReply, error)
+	ExportTrace(context.Context, *ExportTraceRequest) (*ExportTraceReply, error)
 	mustEmbedUnimplementedDeviceManagerServer()
 }
 
@@ -129,18 +266,42 @@ func (UnimplementedDeviceManagerServer) GetAvailableDevices(context.Context, *Ge
 func (UnimplementedDeviceManagerServer) ReservePodQuota(context.Context, *ReservePodQuotaRequest) (*ReservePodQuotaReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ReservePodQuota not implemented")
 }
+func (UnimplementedDeviceManagerServer) UpdatePodQuota(context.Context, *UpdatePodQuotaRequest) (*UpdatePodQuotaReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdatePodQuota not implemented")
+}
+func (UnimplementedDeviceManagerServer) GetNamespaceBudgets(context.Context, *GetNamespaceBudgetsRequest) (*GetNamespaceBudgetsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNamespaceBudgets not implemented")
+}
 func (UnimplementedDeviceManagerServer) GetToken(context.Context, *GetTokenRequest) (*GetTokenReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetToken not implemented")
 }
+func (UnimplementedDeviceManagerServer) TryGetToken(context.Context, *GetTokenRequest) (*TryGetTokenReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TryGetToken not implemented")
+}
 func (UnimplementedDeviceManagerServer) ReturnToken(context.Context, *ReturnTokenRequest) (*ReturnTokenReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ReturnToken not implemented")
 }
+func (UnimplementedDeviceManagerServer) WatchLease(*WatchLeaseRequest, DeviceManager_WatchLeaseServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchLease not implemented")
+}
+func (UnimplementedDeviceManagerServer) OpenSession(DeviceManager_OpenSessionServer) error {
+	return status.Errorf(codes.Unimplemented, "method OpenSession not implemented")
+}
 func (UnimplementedDeviceManagerServer) AllocateMemory(context.Context, *AllocateMemoryRequest) (*AllocateMemoryReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AllocateMemory not implemented")
 }
 func (UnimplementedDeviceManagerServer) FreeMemory(context.Context, *FreeMemoryRequest) (*FreeMemoryReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FreeMemory not implemented")
 }
+func (UnimplementedDeviceManagerServer) GetLeaseHistory(context.Context, *GetLeaseHistoryRequest) (*GetLeaseHistoryReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLeaseHistory not implemented")
+}
+func (UnimplementedDeviceManagerServer) GetPodUsage(context.Context, *GetPodUsageRequest) (*GetPodUsageReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPodUsage not implemented")
+}
+func (UnimplementedDeviceManagerServer) ExportTrace(context.Context, *ExportTraceRequest) (*ExportTraceReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportTrace not implemented")
+}
 func (UnimplementedDeviceManagerServer) mustEmbedUnimplementedDeviceManagerServer() {}
 
 // UnsafeDeviceManagerServer may be embedded to opt out of forward compatibility for this service.
@@ -208,6 +369,42 @@ func _DeviceManager_ReservePodQuota_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DeviceManager_UpdatePodQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePodQuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceManagerServer).UpdatePodQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/device_manager.DeviceManager/UpdatePodQuota",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceManagerServer).UpdatePodQuota(ctx, req.(*UpdatePodQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceManager_GetNamespaceBudgets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNamespaceBudgetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceManagerServer).GetNamespaceBudgets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/device_manager.DeviceManager/GetNamespaceBudgets",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceManagerServer).GetNamespaceBudgets(ctx, req.(*GetNamespaceBudgetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DeviceManager_GetToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetTokenRequest)
 	if err := dec(in); err != nil {
@@ -226,6 +423,24 @@ func _DeviceManager_GetToken_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DeviceManager_TryGetToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceManagerServer).TryGetToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/device_manager.DeviceManager/TryGetToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceManagerServer).TryGetToken(ctx, req.(*GetTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DeviceManager_ReturnToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ReturnTokenRequest)
 	if err := dec(in); err != nil {
@@ -244,6 +459,53 @@ func _DeviceManager_ReturnToken_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DeviceManager_WatchLease_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchLeaseRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DeviceManagerServer).WatchLease(m, &deviceManagerWatchLeaseServer{stream})
+}
+
+type DeviceManager_WatchLeaseServer interface {
+	Send(*LeaseNotice) error
+	grpc.ServerStream
+}
+
+type deviceManagerWatchLeaseServer struct {
+	grpc.ServerStream
+}
+
+func (x *deviceManagerWatchLeaseServer) Send(m *LeaseNotice) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DeviceManager_OpenSession_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DeviceManagerServer).OpenSession(&deviceManagerOpenSessionServer{stream})
+}
+
+type DeviceManager_OpenSessionServer interface {
+	Send(*SessionEvent) error
+	Recv() (*SessionRequest, error)
+	grpc.ServerStream
+}
+
+type deviceManagerOpenSessionServer struct {
+	grpc.ServerStream
+}
+
+func (x *deviceManagerOpenSessionServer) Send(m *SessionEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *deviceManagerOpenSessionServer) Recv() (*SessionRequest, error) {
+	m := new(SessionRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _DeviceManager_AllocateMemory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(AllocateMemoryRequest)
 	if err := dec(in); err != nil {
@@ -280,6 +542,60 @@ func _DeviceManager_FreeMemory_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DeviceManager_GetLeaseHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLeaseHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceManagerServer).GetLeaseHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/device_manager.DeviceManager/GetLeaseHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceManagerServer).GetLeaseHistory(ctx, req.(*GetLeaseHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceManager_GetPodUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPodUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceManagerServer).GetPodUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/device_manager.DeviceManager/GetPodUsage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceManagerServer).GetPodUsage(ctx, req.(*GetPodUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceManager_ExportTrace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportTraceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceManagerServer).ExportTrace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/device_manager.DeviceManager/ExportTrace",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceManagerServer).ExportTrace(ctx, req.(*ExportTraceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // DeviceManager_ServiceDesc is the grpc.ServiceDesc for DeviceManager service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -299,10 +615,22 @@ var DeviceManager_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ReservePodQuota",
 			Handler:    _DeviceManager_ReservePodQuota_Handler,
 		},
+		{
+			MethodName: "UpdatePodQuota",
+			Handler:    _DeviceManager_UpdatePodQuota_Handler,
+		},
+		{
+			MethodName: "GetNamespaceBudgets",
+			Handler:    _DeviceManager_GetNamespaceBudgets_Handler,
+		},
 		{
 			MethodName: "GetToken",
 			Handler:    _DeviceManager_GetToken_Handler,
 		},
+		{
+			MethodName: "TryGetToken",
+			Handler:    _DeviceManager_TryGetToken_Handler,
+		},
 		{
 			MethodName: "ReturnToken",
 			Handler:    _DeviceManager_ReturnToken_Handler,
@@ -315,7 +643,31 @@ var DeviceManager_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "FreeMemory",
 			Handler:    _DeviceManager_FreeMemory_Handler,
 		},
+		{
+			MethodName: "GetLeaseHistory",
+			Handler:    _DeviceManager_GetLeaseHistory_Handler,
+		},
+		{
+			MethodName: "GetPodUsage",
+			Handler:    _DeviceManager_GetPodUsage_Handler,
+		},
+		{
+			MethodName: "ExportTrace",
+			Handler:    _DeviceManager_ExportTrace_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchLease",
+			Handler:       _DeviceManager_WatchLease_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "OpenSession",
+			Handler:       _DeviceManager_OpenSession_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/devicemanager/device-manager.proto",
 }
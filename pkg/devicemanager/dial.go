@@ -0,0 +1,40 @@
+package devicemanager
+
+import (
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	// DefaultSocket is where the device manager serves the pods on its node.
+	DefaultSocket = "/var/run/device-manager/device-manager.sock"
+	// DefaultPort is the TCP port of the device manager.
+	DefaultPort = "50051"
+)
+
+// Dial connects to the device manager of this node over the Unix socket in
+// DEVICE_MANAGER_SOCKET (DefaultSocket when empty) if it exists, otherwise over
+// TCP to HOST_IP. The socket only serves pods acting as themselves, so it
+// fails if `podUid` is empty.
+func Dial(podUid string) (*grpc.ClientConn, error) {
+	socket := os.Getenv("DEVICE_MANAGER_SOCKET")
+	if socket == "" {
+		socket = DefaultSocket
+	}
+
+	target := "unix://" + socket
+	if _, err := os.Stat(socket); err != nil {
+		addr := os.Getenv("HOST_IP")
+		if addr == "" {
+			addr = "127.0.0.1"
+		}
+		target = fmt.Sprintf("%s:%s", addr, DefaultPort)
+	} else if podUid == "" {
+		return nil, fmt.Errorf("pod uid not set, the device manager socket %s only serves pods by their uid, set it from metadata.uid with the downward API", socket)
+	}
+
+	return grpc.Dial(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+}
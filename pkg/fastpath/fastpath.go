@@ -0,0 +1,231 @@
+// Package fastpath gets and returns the token of a device over a Unix socket
+// of the node the device manager runs on. Clients on the node skip the gRPC
+// round trips around short kernels, the gRPC API stays the way to reserve
+// quota and memory.
+//
+// A client opens a connection with a hello naming its device and pod, then
+// sends one acquire or release at a time, each frame is a single write:
+//
+//	hello:   'H' device pod_namespace pod_name pod_uid (uint16 length-prefixed strings)
+//	acquire: 'A'
+//	release: 'R' lease_id (uint64)
+//
+// The device manager replies 'K' to a hello and a release, 'G' lease_id
+// (uint64) expires_at (int64 unix seconds) to an acquire, or 'E' and a
+// length-prefixed message if the request failed. All integers are big endian.
+// The lease a connection holds ends when it is closed.
+package fastpath
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+const (
+	OpHello   byte = 'H'
+	OpAcquire byte = 'A'
+	OpRelease byte = 'R'
+
+	opOK      byte = 'K'
+	opGranted byte = 'G'
+	opError   byte = 'E'
+)
+
+// Hello opens a connection for a pod on a device.
+type Hello struct {
+	DeviceId     string
+	PodNamespace string
+	PodName      string
+	PodUid       string
+}
+
+// Request is an acquire or a release, LeaseId is only set for a release.
+type Request struct {
+	Op      byte
+	LeaseId uint64
+}
+
+// ReadHello reads the hello a connection starts with.
+func ReadHello(r io.Reader) (Hello, error) {
+	var op [1]byte
+	if _, err := io.ReadFull(r, op[:]); err != nil {
+		return Hello{}, err
+	}
+	if op[0] != OpHello {
+		return Hello{}, fmt.Errorf("expected hello, got %q", op[0])
+	}
+
+	var hello Hello
+	for _, field := range []*string{&hello.DeviceId, &hello.PodNamespace, &hello.PodName, &hello.PodUid} {
+		s, err := readString(r)
+		if err != nil {
+			return Hello{}, err
+		}
+		*field = s
+	}
+	return hello, nil
+}
+
+// ReadRequest reads the next acquire or release of the client.
+func ReadRequest(r io.Reader) (Request, error) {
+	var op [1]byte
+	if _, err := io.ReadFull(r, op[:]); err != nil {
+		return Request{}, err
+	}
+
+	switch op[0] {
+	case OpAcquire:
+		return Request{Op: OpAcquire}, nil
+	case OpRelease:
+		var leaseId [8]byte
+		if _, err := io.ReadFull(r, leaseId[:]); err != nil {
+			return Request{}, err
+		}
+		return Request{Op: OpRelease, LeaseId: binary.BigEndian.Uint64(leaseId[:])}, nil
+	default:
+		return Request{}, fmt.Errorf("unknown request %q", op[0])
+	}
+}
+
+// WriteOK replies to a hello or a release.
+func WriteOK(w io.Writer) error {
+	_, err := w.Write([]byte{opOK})
+	return err
+}
+
+// WriteGranted replies to an acquire with the lease.
+func WriteGranted(w io.Writer, leaseId uint64, expiresAt int64) error {
+	frame := make([]byte, 17)
+	frame[0] = opGranted
+	binary.BigEndian.PutUint64(frame[1:], leaseId)
+	binary.BigEndian.PutUint64(frame[9:], uint64(expiresAt))
+	_, err := w.Write(frame)
+	return err
+}
+
+// WriteError replies to a request which failed.
+func WriteError(w io.Writer, err error) error {
+	_, werr := w.Write(appendString([]byte{opError}, err.Error()))
+	return werr
+}
+
+func appendString(frame []byte, s string) []byte {
+	if len(s) > 1<<16-1 {
+		s = s[:1<<16-1]
+	}
+	frame = append(frame, byte(len(s)>>8), byte(len(s)))
+	return append(frame, s...)
+}
+
+func readString(r io.Reader) (string, error) {
+	var length [2]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return "", err
+	}
+
+	s := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(r, s); err != nil {
+		return "", err
+	}
+	return string(s), nil
+}
+
+// Client is a connection of a pod to the fast path, its calls are serialized.
+type Client struct {
+	lock *sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// Dial connects to the fast path at the socket `path`.
+func Dial(path string, hello Hello) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	frame := []byte{OpHello}
+	for _, s := range []string{hello.DeviceId, hello.PodNamespace, hello.PodName, hello.PodUid} {
+		frame = appendString(frame, s)
+	}
+
+	c := &Client{lock: &sync.Mutex{}, conn: conn, r: bufio.NewReader(conn)}
+	if _, err := conn.Write(frame); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := c.readOK(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Acquire waits for the token and returns its lease.
+func (c *Client) Acquire() (leaseId uint64, expiresAt int64, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, err := c.conn.Write([]byte{OpAcquire}); err != nil {
+		return 0, 0, err
+	}
+	if err := c.readReply(opGranted); err != nil {
+		return 0, 0, err
+	}
+
+	var lease [16]byte
+	if _, err := io.ReadFull(c.r, lease[:]); err != nil {
+		return 0, 0, err
+	}
+	return binary.BigEndian.Uint64(lease[:8]), int64(binary.BigEndian.Uint64(lease[8:])), nil
+}
+
+// Release returns the token of the lease.
+func (c *Client) Release(leaseId uint64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	frame := make([]byte, 9)
+	frame[0] = OpRelease
+	binary.BigEndian.PutUint64(frame[1:], leaseId)
+	if _, err := c.conn.Write(frame); err != nil {
+		return err
+	}
+	return c.readOK()
+}
+
+// Close closes the connection, the lease it holds ends.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) readOK() error {
+	return c.readReply(opOK)
+}
+
+// readReply reads the op of a reply, the error of the device manager if the
+// request failed.
+func (c *Client) readReply(expected byte) error {
+	op, err := c.r.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	switch op {
+	case expected:
+		return nil
+	case opError:
+		msg, err := readString(c.r)
+		if err != nil {
+			return err
+		}
+		return errors.New(msg)
+	default:
+		return fmt.Errorf("unexpected reply %q", op)
+	}
+}
@@ -0,0 +1,120 @@
+// Package quota models the share of a device reserved by a pod in integer
+// milli-device units, so that quotas add up exactly.
+//
+// Quotas are written like Kubernetes quantities: "250m" and "0.25" are both
+// 250 milli-shares, "1" and "1000m" are a whole device.
+// Values finer than a milli-share, like "0.0005" or "1u", are rejected
+// instead of being rounded.
+package quota
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// MilliShares is a share of a device, 1000 is the whole device.
+type MilliShares int64
+
+// Device is the whole device.
+const Device MilliShares = 1000
+
+// Labels of client pods with their quota on the device.
+const (
+	RequestsLabel = "sharedev.requests"
+	LimitsLabel   = "sharedev.limits"
+	MemoryLabel   = "sharedev.memory"
+)
+
+// Parse parses a quantity like "250m" or "0.25".
+func Parse(value string) (MilliShares, error) {
+	q, err := resource.ParseQuantity(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quota %q: %w", value, err)
+	}
+	if q.Sign() < 0 {
+		return 0, fmt.Errorf("invalid quota %q: must not be negative", value)
+	}
+
+	milli := q.MilliValue()
+	// MilliValue rounds up, an exact value survives the round trip
+	if q.Cmp(*resource.NewMilliQuantity(milli, resource.DecimalSI)) != 0 {
+		return 0, fmt.Errorf("invalid quota %q: finer than a milli-share", value)
+	}
+	return MilliShares(milli), nil
+}
+
+// FromFraction converts a fraction of the device, as sent by older clients,
+// rounding it to the nearest milli-share.
+func FromFraction(fraction float64) MilliShares {
+	return MilliShares(math.Round(fraction * float64(Device)))
+}
+
+// Fraction is the share as a fraction of the device, 250m is 0.25.
+func (m MilliShares) Fraction() float64 {
+	return float64(m) / float64(Device)
+}
+
+// Of returns the share of an amount, like the bytes of the memory of a
+// device, rounded down.
+func (m MilliShares) Of(amount uint64) uint64 {
+	if m <= 0 {
+		return 0
+	}
+	return amount/uint64(Device)*uint64(m) + amount%uint64(Device)*uint64(m)/uint64(Device)
+}
+
+// ShareOf is the share an amount is of the total, like reserved bytes of the
+// memory of a device, rounded up so that it is never under-reported.
+func ShareOf(amount, total uint64) MilliShares {
+	if total == 0 {
+		return 0
+	}
+	hi, lo := bits.Mul64(amount%total, uint64(Device))
+	share, rem := bits.Div64(hi, lo, total)
+	if rem > 0 {
+		share++
+	}
+	return MilliShares(amount/total)*Device + MilliShares(share)
+}
+
+// String formats the share like a quantity, "250m" or "1".
+func (m MilliShares) String() string {
+	if m%Device == 0 {
+		return strconv.FormatInt(int64(m/Device), 10)
+	}
+	return strconv.FormatInt(int64(m), 10) + "m"
+}
+
+// Quota is what a pod reserves on a device.
+type Quota struct {
+	Requests MilliShares
+	Limit    MilliShares
+	Memory   MilliShares
+}
+
+// FromLabels reads the quota from the labels of a pod, missing labels are
+// zero and a missing limit is the requests.
+func FromLabels(labels map[string]string) (Quota, error) {
+	q := Quota{}
+	for label, value := range map[string]*MilliShares{RequestsLabel: &q.Requests, LimitsLabel: &q.Limit, MemoryLabel: &q.Memory} {
+		if text, ok := labels[label]; ok {
+			parsed, err := Parse(text)
+			if err != nil {
+				return Quota{}, fmt.Errorf("label %s: %w", label, err)
+			}
+			*value = parsed
+		}
+	}
+
+	if q.Limit == 0 {
+		q.Limit = q.Requests
+	}
+	if q.Requests > q.Limit {
+		return Quota{}, fmt.Errorf("requests %s exceed limit %s", q.Requests, q.Limit)
+	}
+	return q, nil
+}
@@ -0,0 +1,74 @@
+package quota
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	for value, expected := range map[string]MilliShares{
+		"250m":  250,
+		"0.25":  250,
+		"1":     Device,
+		"1.0":   Device,
+		"1000m": Device,
+		"1.5":   1500,
+		"0":     0,
+	} {
+		parsed, err := Parse(value)
+		assert.Nil(t, err, value)
+		assert.Equal(t, expected, parsed, value)
+	}
+
+	for _, value := range []string{"0.0005", "1u", "-1", "-250m", "quarter", ""} {
+		_, err := Parse(value)
+		assert.NotNil(t, err, value)
+	}
+}
+
+func TestFromFraction(t *testing.T) {
+	assert.Equal(t, MilliShares(250), FromFraction(0.25))
+	assert.Equal(t, MilliShares(300), FromFraction(0.1+0.2))
+	assert.Equal(t, MilliShares(1), FromFraction(0.0012))
+	assert.Equal(t, 0.25, MilliShares(250).Fraction())
+}
+
+func TestOf(t *testing.T) {
+	assert.Equal(t, uint64(256), MilliShares(250).Of(1024))
+	assert.Equal(t, uint64(1022), MilliShares(999).Of(1024))
+	// no overflow on the largest amounts
+	assert.Equal(t, uint64(math.MaxUint64/2), MilliShares(500).Of(math.MaxUint64))
+	assert.Equal(t, uint64(0), MilliShares(-1).Of(1024))
+}
+
+func TestShareOf(t *testing.T) {
+	assert.Equal(t, MilliShares(250), ShareOf(256, 1024))
+	assert.Equal(t, MilliShares(1), ShareOf(1, 1024))
+	assert.Equal(t, MilliShares(1500), ShareOf(1536, 1024))
+	assert.Equal(t, Device, ShareOf(math.MaxUint64, math.MaxUint64))
+	assert.Equal(t, MilliShares(0), ShareOf(1, 0))
+}
+
+func TestString(t *testing.T) {
+	assert.Equal(t, "250m", MilliShares(250).String())
+	assert.Equal(t, "2", MilliShares(2000).String())
+	assert.Equal(t, "0", MilliShares(0).String())
+}
+
+func TestFromLabels(t *testing.T) {
+	q, err := FromLabels(map[string]string{RequestsLabel: "0.25", LimitsLabel: "1.0", MemoryLabel: "250m"})
+	assert.Nil(t, err)
+	assert.Equal(t, Quota{Requests: 250, Limit: Device, Memory: 250}, q)
+
+	// a missing limit is the requests
+	q, err = FromLabels(map[string]string{RequestsLabel: "500m"})
+	assert.Nil(t, err)
+	assert.Equal(t, Quota{Requests: 500, Limit: 500}, q)
+
+	_, err = FromLabels(map[string]string{RequestsLabel: "1", LimitsLabel: "500m"})
+	assert.NotNil(t, err)
+	_, err = FromLabels(map[string]string{MemoryLabel: "0.0001"})
+	assert.NotNil(t, err)
+}
@@ -2,12 +2,7 @@ package opencl
 
 // #include "opencl.h"
 import "C"
-import (
-	"context"
-	"unsafe"
-
-	pb "github.com/zbsss/device-manager/pkg/devicemanager"
-)
+import "log"
 
 type MemFlags uint64
 
@@ -19,7 +14,10 @@ const (
 )
 
 type Buffer struct {
+	// buffer is the first field, kernels take a *Buffer as a *cl_mem
 	buffer C.cl_mem
+	// allocationId is the memory allocation of the buffer in the device manager.
+	allocationId uint64
 }
 
 func createBuffer(context Context, flags []MemFlags, size uint64) (Buffer, error) {
@@ -41,7 +39,7 @@ func createBuffer(context Context, flags []MemFlags, size uint64) (Buffer, error
 		return Buffer{}, clErrorToError(errInt)
 	}
 
-	return Buffer{buffer}, nil
+	return Buffer{buffer: buffer}, nil
 }
 
 func (b Buffer) Size() uint64 {
@@ -49,11 +47,12 @@ func (b Buffer) Size() uint64 {
 }
 
 func (b Buffer) Release() {
-	var size C.size_t
-	C.clGetMemObjectInfo(b.buffer, C.CL_MEM_SIZE, C.size_t(unsafe.Sizeof(size)), unsafe.Pointer(&size), nil)
-
 	C.clReleaseMemObject(b.buffer)
+	freeMemory(b.allocationId)
+}
 
-	ctx := context.Background()
-	Scheduler.FreeMemory(ctx, &pb.FreeMemoryRequest{DeviceId: DeviceId, PodId: ClientId, MemoryB: uint64(size)})
+func freeMemory(allocationId uint64) {
+	if err := Session.free(allocationId); err != nil {
+		log.Printf("Failed to free memory allocation %d: %v", allocationId, err)
+	}
 }
@@ -3,11 +3,9 @@ package opencl
 // #include "opencl.h"
 import "C"
 import (
-	"context"
 	"errors"
+	"log"
 	"unsafe"
-
-	pb "github.com/zbsss/device-manager/pkg/devicemanager"
 )
 
 type CommandQueue struct {
@@ -31,14 +29,20 @@ func createCommandQueue(context Context, device Device) (CommandQueue, error) {
 }
 
 func (c CommandQueue) EnqueueNDRangeKernel(kernel Kernel, workDim uint32, globalWorkSize []uint64) error {
-	ctx := context.Background()
-	_, err := Scheduler.GetToken(ctx, &pb.GetTokenRequest{
-		PodId:    ClientId,
-		DeviceId: DeviceId,
-	})
+	leaseId, err := acquireToken()
 	if err != nil {
 		return err
 	}
+	// the safe point before the kernel, a revoked lease is given back unused
+	for revokedLease.CompareAndSwap(leaseId, 0) {
+		log.Printf("returning lease %d revoked before its kernel started", leaseId)
+		if err := releaseToken(leaseId); err != nil {
+			return err
+		}
+		if leaseId, err = acquireToken(); err != nil {
+			return err
+		}
+	}
 
 	errInt := clError(C.clEnqueueNDRangeKernel(c.commandQueue,
 		kernel.kernel,
@@ -49,13 +53,14 @@ func (c CommandQueue) EnqueueNDRangeKernel(kernel Kernel, workDim uint32, global
 
 	clErr := clErrorToError(errInt)
 
-	_, err = Scheduler.ReturnToken(ctx, &pb.ReturnTokenRequest{
-		PodId:    ClientId,
-		DeviceId: DeviceId,
-	})
+	// the kernel boundary, the token is returned whether or not it was revoked
+	err = releaseToken(leaseId)
 	if err != nil {
 		return err
 	}
+	if revokedLease.CompareAndSwap(leaseId, 0) {
+		log.Printf("returned revoked lease %d", leaseId)
+	}
 
 	return clErr
 }
@@ -2,11 +2,6 @@ package opencl
 
 // #include "opencl.h"
 import "C"
-import (
-	"context"
-
-	pb "github.com/zbsss/device-manager/pkg/devicemanager"
-)
 
 type Context struct {
 	context C.cl_context
@@ -41,13 +36,18 @@ func (c Context) CreateProgramWithSource(programCode string) (Program, error) {
 }
 
 func (c Context) CreateBuffer(memFlags []MemFlags, size uint64) (Buffer, error) {
-	ctx := context.Background()
-	_, err := Scheduler.AllocateMemory(ctx, &pb.AllocateMemoryRequest{DeviceId: DeviceId, PodId: ClientId, MemoryB: size})
+	allocationId, err := Session.allocate(size)
 	if err != nil {
 		return Buffer{}, err
 	}
 
-	return createBuffer(c, memFlags, size)
+	buffer, err := createBuffer(c, memFlags, size)
+	if err != nil {
+		freeMemory(allocationId)
+		return Buffer{}, err
+	}
+	buffer.allocationId = allocationId
+	return buffer, nil
 }
 
 func (c Context) Release() {
@@ -0,0 +1,85 @@
+package opencl
+
+import (
+	"log"
+	"os"
+	"sync"
+
+	"github.com/zbsss/device-manager/pkg/fastpath"
+)
+
+// TokenSocket is the fast path of the device manager on this node, kernels get
+// the token over it instead of the session when it exists.
+var TokenSocket = os.Getenv("TOKEN_SOCKET")
+
+var fastPath struct {
+	lock   sync.Mutex
+	client *fastpath.Client
+}
+
+// acquireToken waits for the token and returns the id of its lease.
+func acquireToken() (uint64, error) {
+	client := fastPathClient()
+	if client == nil {
+		return Session.acquire()
+	}
+
+	leaseId, _, err := client.Acquire()
+	if err != nil {
+		dropFastPath(client, err)
+	}
+	return leaseId, err
+}
+
+func releaseToken(leaseId uint64) error {
+	client := fastPathClient()
+	if client == nil {
+		return Session.release(leaseId)
+	}
+
+	err := client.Release(leaseId)
+	if err != nil {
+		dropFastPath(client, err)
+	}
+	return err
+}
+
+// fastPathClient connects to the fast path, it returns nil if there is none.
+func fastPathClient() *fastpath.Client {
+	fastPath.lock.Lock()
+	defer fastPath.lock.Unlock()
+
+	if fastPath.client != nil || TokenSocket == "" {
+		return fastPath.client
+	}
+	if _, err := os.Stat(TokenSocket); err != nil {
+		return nil
+	}
+
+	client, err := fastpath.Dial(TokenSocket, fastpath.Hello{
+		DeviceId:     DeviceId,
+		PodNamespace: ClientNamespace,
+		PodName:      ClientId,
+		PodUid:       ClientUid,
+	})
+	if err != nil {
+		log.Printf("fast path unavailable: %v", err)
+		return nil
+	}
+
+	fastPath.client = client
+	return client
+}
+
+// dropFastPath closes the connection after a failed call, the next call
+// connects again.
+func dropFastPath(client *fastpath.Client, err error) {
+	fastPath.lock.Lock()
+	defer fastPath.lock.Unlock()
+
+	if fastPath.client == client {
+		log.Printf("fast path interrupted: %v", err)
+		client.Close()
+		fastPath.client = nil
+	}
+}
@@ -0,0 +1,155 @@
+package opencl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	pb "github.com/zbsss/device-manager/pkg/devicemanager"
+)
+
+// Session is the stream this client gets the token and memory over. It is
+// opened on first use and again after it broke, the device manager ends the
+// lease of a broken session.
+var Session = &session{pending: map[uint64]chan *pb.SessionEvent{}}
+
+type session struct {
+	lock    sync.Mutex
+	stream  pb.DeviceManager_OpenSessionClient
+	cancel  context.CancelFunc
+	seq     uint64
+	pending map[uint64]chan *pb.SessionEvent
+}
+
+// acquire waits for the token and returns the id of its lease.
+func (s *session) acquire() (uint64, error) {
+	event, err := s.call(&pb.SessionRequest{Request: &pb.SessionRequest_Acquire{Acquire: &pb.SessionAcquire{}}})
+	if err != nil {
+		return 0, err
+	}
+	return event.GetAcquired().GetLeaseId(), nil
+}
+
+func (s *session) release(leaseId uint64) error {
+	_, err := s.call(&pb.SessionRequest{Request: &pb.SessionRequest_Release{Release: &pb.SessionRelease{LeaseId: leaseId}}})
+	return err
+}
+
+// allocate allocates memory on the device and returns the id of the allocation.
+func (s *session) allocate(memoryB uint64) (uint64, error) {
+	event, err := s.call(&pb.SessionRequest{Request: &pb.SessionRequest_Allocate{Allocate: &pb.SessionAllocate{MemoryB: memoryB}}})
+	if err != nil {
+		return 0, err
+	}
+	return event.GetAllocated().GetAllocationId(), nil
+}
+
+func (s *session) free(allocationId uint64) error {
+	_, err := s.call(&pb.SessionRequest{Request: &pb.SessionRequest_Free{Free: &pb.SessionFree{AllocationId: allocationId}}})
+	return err
+}
+
+// call sends the request and waits for its reply.
+func (s *session) call(req *pb.SessionRequest) (*pb.SessionEvent, error) {
+	s.lock.Lock()
+	if s.stream == nil {
+		if err := s.openNoLock(); err != nil {
+			s.lock.Unlock()
+			return nil, err
+		}
+	}
+
+	s.seq++
+	req.Seq = s.seq
+	reply := make(chan *pb.SessionEvent, 1)
+	s.pending[req.Seq] = reply
+	stream := s.stream
+	err := stream.Send(req)
+	s.lock.Unlock()
+
+	if err != nil {
+		s.broken(stream, err)
+	}
+
+	event, ok := <-reply
+	if !ok {
+		return nil, fmt.Errorf("session with the device manager broke")
+	}
+	if event.Error != "" {
+		return nil, errors.New(event.Error)
+	}
+	return event, nil
+}
+
+func (s *session) openNoLock() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := Scheduler.OpenSession(ctx)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	s.seq++
+	err = stream.Send(&pb.SessionRequest{Seq: s.seq, Request: &pb.SessionRequest_Open{Open: &pb.SessionOpen{
+		DeviceId:     DeviceId,
+		PodId:        ClientId,
+		PodNamespace: ClientNamespace,
+		PodUid:       ClientUid,
+	}}})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if err != nil {
+		cancel()
+		return fmt.Errorf("could not open session: %w", err)
+	}
+
+	s.stream, s.cancel = stream, cancel
+	go s.receive(stream)
+	return nil
+}
+
+// receive hands the replies to the calls waiting for them and handles notices.
+func (s *session) receive(stream pb.DeviceManager_OpenSessionClient) {
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			s.broken(stream, err)
+			return
+		}
+
+		if notice := event.GetNotice(); notice != nil {
+			handleNotice(notice)
+			continue
+		}
+
+		s.lock.Lock()
+		reply := s.pending[event.Seq]
+		delete(s.pending, event.Seq)
+		s.lock.Unlock()
+
+		if reply != nil {
+			reply <- event
+		}
+	}
+}
+
+// broken fails the calls waiting on the stream, the next call opens a new session.
+func (s *session) broken(stream pb.DeviceManager_OpenSessionClient, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.stream != stream {
+		return
+	}
+
+	log.Printf("session interrupted: %v", err)
+	s.cancel()
+	s.stream = nil
+	for seq, reply := range s.pending {
+		close(reply)
+		delete(s.pending, seq)
+	}
+}
@@ -1,34 +1,30 @@
 package opencl
 
 import (
-	"fmt"
 	"log"
 	"os"
+	"sync/atomic"
+	"time"
 
 	pb "github.com/zbsss/device-manager/pkg/devicemanager"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
-const port = "50051"
-
 var ClientId = os.Getenv("CLIENT_ID")
+var ClientNamespace = os.Getenv("POD_NAMESPACE")
+var ClientUid = os.Getenv("POD_UID")
 var DeviceId = os.Getenv("DEVICE_ID")
 
 var Scheduler = initScheduler()
 
+// revokedLease is the lease the device manager asked this client to return.
+// A kernel does not start on a revoked lease, and a running kernel gives the
+// token back at its boundary.
+var revokedLease atomic.Uint64
+
 type scheduler = pb.DeviceManagerClient
 
 func initScheduler() scheduler {
-	var addr = os.Getenv("HOST_IP")
-	if addr == "" {
-		addr = "127.0.0.1"
-	}
-
-	conn, err := grpc.Dial(
-		fmt.Sprintf("%s:%s", addr, port),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	conn, err := pb.Dial(ClientUid)
 	if err != nil {
 		log.Fatalf("did not connect: %v", err)
 	}
@@ -36,3 +32,15 @@ func initScheduler() scheduler {
 
 	return pb.NewDeviceManagerClient(conn)
 }
+
+// handleNotice acts on the notices the device manager sends over the session,
+// so that it gives this client a grace period instead of evicting it right away.
+func handleNotice(notice *pb.LeaseNotice) {
+	switch notice.Type {
+	case pb.LeaseNotice_MEMORY_PRESSURE:
+		log.Printf("memory quota reduced to %d B, %d B allocated: %s", notice.MemoryBLimit, notice.MemoryBUsed, notice.Reason)
+	default:
+		log.Printf("asked to return the token of lease %d by %s: %s", notice.LeaseId, time.Unix(notice.YieldBy, 0), notice.Reason)
+		revokedLease.Store(notice.LeaseId)
+	}
+}